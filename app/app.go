@@ -3,8 +3,11 @@ package app
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/store"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
@@ -22,43 +25,124 @@ const AppName = "ClearchainApp"
 // ClearchainApp is basic application
 type ClearchainApp struct {
 	*baseapp.BaseApp
-	cdc             *wire.Codec
-	capKeyMainStore *sdk.KVStoreKey
-	capKeyIBCStore  *sdk.KVStoreKey
-	accountMapper   sdk.AccountMapper
+	cdc                *wire.Codec
+	capKeyMainStore    *sdk.KVStoreKey
+	capKeyAccountStore *sdk.KVStoreKey
+	capKeyEntityStore  *sdk.KVStoreKey
+	capKeyJournalStore *sdk.KVStoreKey
+	capKeyParamsStore  *sdk.KVStoreKey
+	accountMapper      types.IndexedAccountMapper
+	entities           types.EntityMapper
+	nettingMapper      types.NettingMapper
+	dualControl        types.DualControlMapper
+	references         types.ReferenceMapper
+	history            types.HistoryMapper
+	journal            types.JournalMapper
+	params             types.ParamsMapper
+	queryRoutes        map[string]queryHandler
+	// committed loads the last committed state of the stores,
+	// unlike the check state it holds no mempool writes
+	committed sdk.CommitMultiStore
 }
 
-// NewClearchainApp creates a new ClearchainApp type.
-func NewClearchainApp(logger log.Logger, db dbm.DB) *ClearchainApp {
+// NewClearchainApp creates a new ClearchainApp type given
+// the databases of the stores, indexed by store name.
+func NewClearchainApp(logger log.Logger, dbs map[string]dbm.DB) *ClearchainApp {
 	var app = &ClearchainApp{
-		BaseApp:         baseapp.NewBaseApp(AppName, logger, db),
-		cdc:             types.MakeCodec(),
-		capKeyMainStore: sdk.NewKVStoreKey("main"),
-		capKeyIBCStore:  sdk.NewKVStoreKey("ibc"),
-	}
-	// define the account mapper
-	app.accountMapper = auth.NewAccountMapperSealed(
-		app.capKeyMainStore, // target store
-		&types.AppAccount{}, // prototype
-	)
+		BaseApp:            baseapp.NewBaseApp(AppName, logger, dbs[types.MainStoreName]),
+		cdc:                types.MakeCodec(),
+		capKeyMainStore:    sdk.NewKVStoreKey(types.MainStoreName),
+		capKeyAccountStore: sdk.NewKVStoreKey(types.AccountsStoreName),
+		capKeyEntityStore:  sdk.NewKVStoreKey(types.EntitiesStoreName),
+		capKeyJournalStore: sdk.NewKVStoreKey(types.JournalStoreName),
+		capKeyParamsStore:  sdk.NewKVStoreKey(types.ParamsStoreName),
+	}
+	keys := []*sdk.KVStoreKey{app.capKeyMainStore, app.capKeyAccountStore,
+		app.capKeyEntityStore, app.capKeyJournalStore, app.capKeyParamsStore}
+	for _, key := range keys {
+		if dbs[key.Name()] == nil {
+			cmn.Exit(fmt.Sprintf("missing database for the %s store", key.Name()))
+		}
+	}
+	// chains committed before the stores were split keep all their data
+	// in the main store, where the mappers' keys do not overlap. They are
+	// loaded so that they can be exported: they have no journal, so the
+	// ledger invariants fail until a new chain is started from the export
+	if isSingleStoreChain(dbs, keys) {
+		logger.Info("The chain keeps all its data in the main store, export it to a new chain to use dedicated stores")
+		app.capKeyAccountStore = app.capKeyMainStore
+		app.capKeyEntityStore = app.capKeyMainStore
+		app.capKeyJournalStore = app.capKeyMainStore
+		app.capKeyParamsStore = app.capKeyMainStore
+		keys = keys[:1]
+	}
+	// define the entity mapper, it keeps the legal entities' registry
+	// and the account mapper's index of accounts by legal entity
+	app.entities = types.NewEntityMapper(app.capKeyEntityStore)
+	app.accountMapper = types.NewIndexedAccountMapper(app.capKeyAccountStore, app.entities)
+	// define the netting mapper, it shares the main store
+	app.nettingMapper = types.NewNettingMapper(app.capKeyMainStore)
+	// define the dual control mapper, it keeps the proposed
+	// transfers in the main store and its parameters in their own
+	app.params = types.NewParamsMapper(app.capKeyParamsStore)
+	app.dualControl = types.NewDualControlMapper(app.capKeyMainStore, app.params)
+	// define the reference mapper, it shares the main store
+	app.references = types.NewReferenceMapper(app.capKeyMainStore)
+	// define the history and journal mappers, they share the journal store
+	app.history = types.NewHistoryMapper(app.capKeyJournalStore)
+	app.journal = types.NewJournalMapper(app.capKeyJournalStore)
 	// add handlers and register routes
-	types.RegisterRoutes(app.Router(), app.accountMapper)
+	types.RegisterRoutes(app.Router(), app.accountMapper, app.entities, app.nettingMapper, app.dualControl,
+		app.references, types.NewLedger(app.journal, app.history))
+	app.registerQueryRoutes()
 
 	// initialise BaseApp
 	app.SetTxDecoder(app.txDecoder)
 	app.SetInitChainer(app.initChainer)
-	// Multi-store feature is currently broken
-	// https://github.com/cosmos/cosmos-sdk/issues/532
-	app.MountStoresIAVL(app.capKeyMainStore)
+	app.SetEndBlocker(app.endBlocker)
+	app.committed = store.NewCommitMultiStore(dbs[types.MainStoreName])
+	for _, key := range keys {
+		app.MountStoreWithDB(key, sdk.StoreTypeIAVL, dbs[key.Name()])
+		app.committed.MountStoreWithDB(key, sdk.StoreTypeIAVL, dbs[key.Name()])
+	}
 	app.SetAnteHandler(auth.NewAnteHandler(app.accountMapper))
 	err := app.LoadLatestVersion(app.capKeyMainStore)
 	if err != nil {
 		cmn.Exit(err.Error())
 	}
+	if err := app.committed.LoadLatestVersion(); err != nil {
+		cmn.Exit(err.Error())
+	}
 
 	return app
 }
 
+// Commit commits the block's state and
+// loads it into the store queries are served from.
+func (app *ClearchainApp) Commit() abci.ResponseCommit {
+	res := app.BaseApp.Commit()
+	if err := app.committed.LoadLatestVersion(); err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// isSingleStoreChain returns true if the chain was committed
+// with the main store only, before the stores were split.
+func isSingleStoreChain(dbs map[string]dbm.DB, keys []*sdk.KVStoreKey) bool {
+	split := store.NewCommitMultiStore(dbs[types.MainStoreName])
+	for _, key := range keys {
+		split.MountStoreWithDB(key, sdk.StoreTypeIAVL, dbs[key.Name()])
+	}
+	// new chains load too, with empty stores
+	if split.LoadLatestVersion() == nil {
+		return false
+	}
+	single := store.NewCommitMultiStore(dbs[types.MainStoreName])
+	single.MountStoreWithDB(keys[0], sdk.StoreTypeIAVL, dbs[types.MainStoreName])
+	return single.LoadLatestVersion() == nil
+}
+
 // RunForever starts the abci server
 func (app *ClearchainApp) RunForever(addrPtr string) {
 	srv, err := server.NewServer(addrPtr, "socket", app)
@@ -73,6 +157,284 @@ func (app *ClearchainApp) RunForever(addrPtr string) {
 	})
 }
 
+// queryHandler serves a clearchain query path; args
+// holds the path's segments after the route name.
+type queryHandler func(ctx sdk.Context, args []string) (interface{}, sdk.Error)
+
+// Query serves clearchain's own query paths and
+// forwards any other query to the underlying stores.
+func (app *ClearchainApp) Query(req abci.RequestQuery) abci.ResponseQuery {
+	if !strings.HasPrefix(req.Path, types.QueryPathPrefix) {
+		return app.BaseApp.Query(req)
+	}
+	args := strings.Split(strings.TrimPrefix(req.Path, types.QueryPathPrefix), "/")
+	handler, ok := app.queryRoutes[args[0]]
+	if !ok {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("unknown query path: %s", req.Path)).Result().ToQuery()
+	}
+	// queries are served against the last committed state,
+	// past heights are not kept
+	height := app.committed.LastCommitID().Version
+	if req.Height != 0 && req.Height != height {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("cannot query height %d, the last committed height is %d",
+			req.Height, height)).Result().ToQuery()
+	}
+	ctx := sdk.NewContext(app.committed.CacheMultiStore(), abci.Header{Height: height}, true, nil)
+	res, err := handler(ctx, args[1:])
+	if err != nil {
+		return err.Result().ToQuery()
+	}
+	bz, jsonErr := json.Marshal(res)
+	if jsonErr != nil {
+		return sdk.ErrInternal(jsonErr.Error()).Result().ToQuery()
+	}
+	return abci.ResponseQuery{Value: bz, Height: height}
+}
+
+// registerQueryRoutes maps the path segment
+// that follows /clearchain/ to its handler.
+func (app *ClearchainApp) registerQueryRoutes() {
+	app.queryRoutes = map[string]queryHandler{
+		"account":    app.queryAccount,
+		"balance":    app.queryBalance,
+		"entity":     app.queryEntity,
+		"entities":   app.queryEntities,
+		"currencies": app.queryCurrencies,
+		"transfers":  app.queryTransfers,
+		"references": app.queryReferences,
+		"history":    app.queryHistory,
+		"journal":    app.queryJournal,
+		"netting":    app.queryNetting,
+		"invariants": app.queryInvariants,
+	}
+}
+
+// /clearchain/account/<addr>
+func (app *ClearchainApp) queryAccount(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 1 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/account/<addr>")
+	}
+	return app.getQueriedAccount(ctx, args[0])
+}
+
+// /clearchain/balance/<addr>/<denom>
+func (app *ClearchainApp) queryBalance(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 2 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/balance/<addr>/<denom>")
+	}
+	if !types.ValidateDenom(args[1]) {
+		return nil, types.ErrInvalidCurrency(fmt.Sprintf("unknown denom %q", args[1]))
+	}
+	acc, err := app.getQueriedAccount(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return sdk.Coin{Denom: args[1], Amount: acc.GetCoins().AmountOf(args[1])}, nil
+}
+
+// /clearchain/entity/<name>
+func (app *ClearchainApp) queryEntity(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 1 || len(args[0]) == 0 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/entity/<name>")
+	}
+	accounts := []types.EntityAccount{}
+	for _, acc := range app.accountMapper.GetEntityAccounts(ctx, args[0]) {
+		accounts = append(accounts, types.NewEntityAccount(acc))
+	}
+	return accounts, nil
+}
+
+// /clearchain/entities
+// /clearchain/entities/<id>
+func (app *ClearchainApp) queryEntities(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	switch {
+	case len(args) == 0:
+		entities := []types.Entity{}
+		app.entities.IterateEntities(ctx, func(e types.Entity) bool {
+			entities = append(entities, e)
+			return false
+		})
+		return entities, nil
+	case len(args) == 1 && len(args[0]) != 0:
+		e, found := app.entities.GetEntity(ctx, args[0])
+		if !found {
+			return nil, types.ErrInvalidLegalEntity(fmt.Sprintf("%q is not registered", args[0]))
+		}
+		return e, nil
+	}
+	return nil, sdk.ErrUnknownRequest("expected /clearchain/entities or /clearchain/entities/<id>")
+}
+
+// /clearchain/currencies
+func (app *ClearchainApp) queryCurrencies(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 0 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/currencies")
+	}
+	return types.GetCurrencies(), nil
+}
+
+// /clearchain/transfers/pending
+// /clearchain/transfers/<id>
+func (app *ClearchainApp) queryTransfers(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 1 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/transfers/pending or /clearchain/transfers/<id>")
+	}
+	if args[0] == "pending" {
+		return app.dualControl.GetPendingTransfers(ctx), nil
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid transfer id %q", args[0]))
+	}
+	pt, found := app.dualControl.GetTransfer(ctx, id)
+	if !found {
+		return nil, types.ErrInvalidTransfer(fmt.Sprintf("%d does not exist", id))
+	}
+	return pt, nil
+}
+
+// /clearchain/references/<sender>/<reference>
+func (app *ClearchainApp) queryReferences(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 2 || len(args[1]) == 0 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/references/<sender>/<reference>")
+	}
+	sender, err := sdk.GetAddress(args[0])
+	if err != nil {
+		return nil, sdk.ErrInvalidAddress(err.Error())
+	}
+	rec, found := app.references.GetTransfer(ctx, sender, args[1])
+	if !found {
+		return nil, types.ErrInvalidReference(fmt.Sprintf("%s was not used by %s", args[1], args[0]))
+	}
+	return rec, nil
+}
+
+// /clearchain/history/<addr>
+// /clearchain/history/<addr>/<page>/<limit>
+func (app *ClearchainApp) queryHistory(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 1 && len(args) != 3 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/history/<addr> or /clearchain/history/<addr>/<page>/<limit>")
+	}
+	page, limit := int64(1), int64(types.DefaultHistoryLimit)
+	if len(args) == 3 {
+		var err error
+		if page, err = strconv.ParseInt(args[1], 10, 64); err != nil || page < 1 {
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid page %q", args[1]))
+		}
+		if limit, err = strconv.ParseInt(args[2], 10, 64); err != nil || limit < 1 || limit > types.MaxHistoryLimit {
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid limit %q, it must be between 1 and %d", args[2], types.MaxHistoryLimit))
+		}
+	}
+	acc, err := app.getQueriedAccount(ctx, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return app.history.GetPostings(ctx, acc.Address, page, limit), nil
+}
+
+// /clearchain/journal/<id>
+// /clearchain/journal/balance/<addr>
+func (app *ClearchainApp) queryJournal(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) == 2 && args[0] == "balance" {
+		acc, err := app.getQueriedAccount(ctx, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return types.NewJournalBalance(acc, app.journal.GetBalance(ctx, acc.Address)), nil
+	}
+	if len(args) != 1 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/journal/<id> or /clearchain/journal/balance/<addr>")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid journal entry id %q", args[0]))
+	}
+	entry, found := app.journal.GetEntry(ctx, id)
+	if !found {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("journal entry %d does not exist", id))
+	}
+	return entry, nil
+}
+
+// /clearchain/netting/<cycle>
+func (app *ClearchainApp) queryNetting(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 1 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/netting/<cycle>")
+	}
+	cycle, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, sdk.ErrUnknownRequest(fmt.Sprintf("invalid netting cycle %q", args[0]))
+	}
+	res, found := app.nettingMapper.GetNettingResult(ctx, cycle)
+	if !found {
+		return nil, types.ErrInvalidCycle(fmt.Sprintf("%d has not been settled", cycle))
+	}
+	return res, nil
+}
+
+// /clearchain/invariants
+func (app *ClearchainApp) queryInvariants(ctx sdk.Context, args []string) (interface{}, sdk.Error) {
+	if len(args) != 0 {
+		return nil, sdk.ErrUnknownRequest("expected /clearchain/invariants")
+	}
+	return types.CheckInvariants(ctx, app.accountMapper, app.journal), nil
+}
+
+func (app *ClearchainApp) getQueriedAccount(ctx sdk.Context, hexAddr string) (*types.AppAccount, sdk.Error) {
+	addr, err := sdk.GetAddress(hexAddr)
+	if err != nil {
+		return nil, sdk.ErrInvalidAddress(err.Error())
+	}
+	acc := app.accountMapper.GetAccount(ctx, addr)
+	if acc == nil {
+		return nil, sdk.ErrUnknownAddress(hexAddr)
+	}
+	return acc.(*types.AppAccount), nil
+}
+
+// ExportGenesis returns the genesis state that recreates the legal entities
+// and accounts committed at the given height, or at the latest height if it is 0.
+func (app *ClearchainApp) ExportGenesis(height int64) (types.GenesisState, error) {
+	if height != 0 {
+		if err := app.LoadVersion(height, app.capKeyMainStore); err != nil {
+			return types.GenesisState{}, err
+		}
+		if app.LastBlockHeight() != height {
+			return types.GenesisState{}, fmt.Errorf("couldn't load height %d", height)
+		}
+	}
+	ctx := app.NewContext(true, abci.Header{})
+	// open netting cycles and pending transfers are not exported,
+	// they must be settled, cancelled or resolved beforehand
+	cycle := app.nettingMapper.GetCurrentCycle(ctx)
+	if obligations := app.nettingMapper.GetObligations(ctx, cycle); len(obligations) != 0 {
+		return types.GenesisState{}, fmt.Errorf("netting cycle %d has %d open obligations, settle or cancel it first",
+			cycle, len(obligations))
+	}
+	if pending := app.dualControl.GetPendingTransfers(ctx); len(pending) != 0 {
+		return types.GenesisState{}, fmt.Errorf("%d transfers are pending, approve or reject them first", len(pending))
+	}
+	var entities []types.Entity
+	app.entities.IterateEntities(ctx, func(e types.Entity) bool {
+		entities = append(entities, e)
+		return false
+	})
+	var accounts []*types.AppAccount
+	app.accountMapper.IterateAccounts(ctx, func(acc *types.AppAccount) bool {
+		accounts = append(accounts, acc)
+		return false
+	})
+	genesisState, err := types.NewGenesisState(entities, accounts)
+	if err != nil {
+		return genesisState, err
+	}
+	params := app.dualControl.GetParams(ctx)
+	genesisState.DualControl = &params
+	invariants := app.params.GetInvariantParams(ctx)
+	genesisState.Invariants = &invariants
+	return genesisState, nil
+}
+
 // custom logic for transaction decoding
 func (app *ClearchainApp) txDecoder(txBytes []byte) (sdk.Tx, sdk.Error) {
 	// StdTx.Msg is an interface. The concrete types are registered by MakeCodec.
@@ -87,30 +449,74 @@ func (app *ClearchainApp) txDecoder(txBytes []byte) (sdk.Tx, sdk.Error) {
 	return tx, nil
 }
 
+// endBlocker expires the pending transfers that were not approved in time
+// and, every check interval, checks the ledger invariants: violations are
+// logged as errors and a conservation break halts the chain.
+func (app *ClearchainApp) endBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+	for _, pt := range app.dualControl.ExpireTransfers(ctx) {
+		app.Logger.Info("Pending transfer expired", "id", pt.ID)
+	}
+	if !app.params.GetInvariantParams(ctx).IsCheckDue(ctx.BlockHeight()) {
+		return abci.ResponseEndBlock{}
+	}
+	violations := types.CheckInvariants(ctx, app.accountMapper, app.journal)
+	conserved := true
+	for _, v := range violations {
+		app.Logger.Error("LEDGER INVARIANT VIOLATED", "height", ctx.BlockHeight(), "invariant", v.Invariant,
+			"account", v.Account, "description", v.Description)
+		conserved = conserved && v.Invariant != types.InvariantConservation
+	}
+	if len(violations) != 0 {
+		app.Logger.Error(fmt.Sprintf("%d ledger invariant violations at height %d", len(violations), ctx.BlockHeight()))
+	}
+	// money was created or destroyed: stop before more blocks build on it
+	if !conserved {
+		panic(fmt.Sprintf("ledger conservation violated at height %d", ctx.BlockHeight()))
+	}
+	return abci.ResponseEndBlock{}
+}
+
 // custom logic for clearchain initialization
 func (app *ClearchainApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
-	stateJSON := req.AppStateBytes
+	// ResponseInitChain cannot carry an error: log every problem and halt
+	// rather than start a chain without its genesis state, operators find
+	// them beforehand with validate-genesis.
 	genesisState := new(types.GenesisState)
-	err := json.Unmarshal(stateJSON, genesisState)
+	if err := json.Unmarshal(req.AppStateBytes, genesisState); err != nil {
+		app.Logger.Error("Malformed genesis state", "err", err)
+		panic(fmt.Sprintf("malformed genesis state: %v", err))
+	}
+	if err := genesisState.Validate(); err != nil {
+		app.Logger.Error("Invalid genesis state", "err", err)
+		panic(fmt.Sprintf("invalid genesis state: %v", err))
+	}
+	for _, e := range genesisState.ToEntities() {
+		app.entities.SetEntity(ctx, e)
+	}
+	accounts, err := genesisState.ToAppAccounts()
 	if err != nil {
-		panic(err) // TODO https://github.com/cosmos/cosmos-sdk/issues/468
-		// return sdk.ErrGenesisParse("").TraceCause(err, "")
+		app.Logger.Error("Invalid genesis accounts", "err", err)
+		panic(fmt.Sprintf("invalid genesis accounts: %v", err))
+	}
+	for _, acc := range accounts {
+		app.accountMapper.SetAccount(ctx, acc)
+	}
+	app.journal.AddOpeningBalances(ctx, accounts)
+	if genesisState.DualControl != nil {
+		app.dualControl.SetParams(ctx, *genesisState.DualControl)
+	}
+	if genesisState.Invariants != nil {
+		app.params.SetInvariantParams(ctx, *genesisState.Invariants)
 	}
-
 	genChAdmin := genesisState.ClearingHouseAdmin
 	if (genChAdmin != types.GenesisAccount{}) {
-		acc, err := genChAdmin.ToClearingHouseAdmin()
-		if err != nil {
-			panic(err) // TODO https://github.com/cosmos/cosmos-sdk/issues/468
-			//	return sdk.ErrGenesisParse("").TraceCause(err, "")
-		}
-		app.accountMapper.SetAccount(ctx, acc)
 		fmt.Println("***** Set Ch Admin *****")
-		fmt.Printf("Entity name: %v \n", acc.EntityName)
-		fmt.Printf("Entity type: %v \n", acc.EntityType)
+		fmt.Printf("Entity name: %v \n", genChAdmin.EntityName)
+		fmt.Printf("Entity type: %v \n", types.EntityClearingHouse)
 		fmt.Printf("Public key: %v \n", genChAdmin.PubKeyHexa)
 		fmt.Println("*****")
 	}
+	app.Logger.Info("Genesis accounts loaded", "count", len(accounts))
 
 	fmt.Println("Genesis file loaded successfully!")
 	return abci.ResponseInitChain{}
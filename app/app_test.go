@@ -3,17 +3,22 @@ package app
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/cosmos/cosmos-sdk/store"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 
 	abci "github.com/tendermint/abci/types"
 	crypto "github.com/tendermint/go-crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
 	common "github.com/tendermint/tmlibs/common"
 	dbm "github.com/tendermint/tmlibs/db"
 	"github.com/tendermint/tmlibs/log"
@@ -41,11 +46,11 @@ func TestApp_DepositMsg(t *testing.T) {
 	// get real working
 	dres = cc.DeliverTx(depositTx)
 	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
-	cc.Commit()
 	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
 
 	// Query data to verify the deposit
-	res := cc.Query(abci.RequestQuery{Data: memberAssetAddr, Path: "/main/key"})
+	res := cc.Query(abci.RequestQuery{Data: memberAssetAddr, Path: "/accounts/key"})
 	codec := cc.cdc
 	var foundAcc *types.AppAccount
 	err := codec.UnmarshalBinary(res.GetValue(), &foundAcc)
@@ -99,6 +104,645 @@ func TestApp_FreezeAdmin(t *testing.T) {
 	cc.EndBlock(abci.RequestEndBlock{})
 }
 
+func TestApp_UnfreezeOperator(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chAdmAddr, chAdmPrivKey := fakeAdminAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	depositMsg := types.DepositMsg{Operator: chOpAddr, Sender: custAssetAddr,
+		Recipient: memberAssetAddr, Amount: sdk.Coin{"USD", 700}}
+	// freeze the operator, then bring it back
+	freezeOpMsg := types.NewFreezeOperatorMsg(chAdmAddr, chOpAddr)
+	dres := cc.DeliverTx(makeTx(cc.cdc, freezeOpMsg, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	unfreezeOpMsg := types.NewUnfreezeOperatorMsg(chAdmAddr, chOpAddr)
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, unfreezeOpMsg, 1, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	// the operator can deposit again
+	dres = cc.DeliverTx(makeTx(cc.cdc, depositMsg, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+}
+
+func TestApp_FreezeAssetAccount(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chAdmAddr, chAdmPrivKey := fakeAdminAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	// the CH admin freezes the member's asset account
+	freezeMsg := types.NewFreezeAssetAccountMsg(chAdmAddr, memberAssetAddr)
+	dres := cc.DeliverTx(makeTx(cc.cdc, freezeMsg, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	// deposits against it now fail
+	depositMsg := types.DepositMsg{Operator: chOpAddr, Sender: custAssetAddr,
+		Recipient: memberAssetAddr, Amount: sdk.Coin{"USD", 700}}
+	dres = cc.DeliverTx(makeTx(cc.cdc, depositMsg, chOpPrivKey))
+	assert.EqualValues(t, types.CodeInactiveAccount, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+}
+
+func TestApp_QueryEntity(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chAdmAddr, chAdmPrivKey := fakeAdminAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	chOpAddr, _ := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	chAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityClearingHouse, "CH")
+	fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CHX")
+	// accounts created by handlers are indexed too
+	pub := crypto.GenPrivKeyEd25519().PubKey()
+	createMsg := types.NewCreateAssetAccountMsg(chAdmAddr, pub)
+	dres := cc.DeliverTx(makeTx(cc.cdc, createMsg, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.EntityQueryPath + "CH"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var accounts []types.EntityAccount
+	assert.Nil(t, json.Unmarshal(res.Value, &accounts))
+	assert.Equal(t, 4, len(accounts))
+	found := make(map[string]types.EntityAccount)
+	for _, acc := range accounts {
+		assert.Equal(t, "CH", acc.EntityName)
+		found[string(acc.Address)] = acc
+	}
+	assert.True(t, found[string(chAdmAddr)].Admin)
+	assert.Equal(t, types.AccountUser, found[string(chOpAddr)].AccountType)
+	assert.False(t, found[string(chOpAddr)].Admin)
+	assert.Equal(t, types.AccountAsset, found[string(chAssetAddr)].AccountType)
+	assert.True(t, found[string(pub.Address())].Active)
+
+	// unknown entities have no accounts
+	res = cc.Query(abci.RequestQuery{Path: types.EntityQueryPath + "nobody"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	assert.Equal(t, "[]", string(res.Value))
+}
+
+func TestApp_RegisterEntity(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chAdmAddr, chAdmPrivKey := fakeAdminAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	registerMsg := types.NewRegisterEntityMsg(chAdmAddr, "icm-1", "ICM", types.EntityIndividualClearingMember, "5493001KJTIIGC8Y1R12", "GB")
+	dres := cc.DeliverTx(makeTx(cc.cdc, registerMsg, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	// admins are created for registered entities only
+	pub := crypto.GenPrivKeyEd25519().PubKey()
+	createMsg := types.NewCreateAdminMsg(chAdmAddr, pub, "icm-2")
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, createMsg, 1, chAdmPrivKey))
+	assert.EqualValues(t, types.CodeInvalidEntity, dres.Code, dres.Log)
+	createMsg = types.NewCreateAdminMsg(chAdmAddr, pub, "icm-1")
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, createMsg, 2, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.RegisteredEntityQueryPath + "icm-1"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var entity types.Entity
+	assert.Nil(t, json.Unmarshal(res.Value, &entity))
+	assert.Equal(t, registerMsg.Entity(), entity)
+	res = cc.Query(abci.RequestQuery{Path: types.EntitiesQueryPath})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var entities []types.Entity
+	assert.Nil(t, json.Unmarshal(res.Value, &entities))
+	assert.Equal(t, []types.Entity{entity}, entities)
+	res = cc.Query(abci.RequestQuery{Path: types.AccountQueryPath + hex.EncodeToString(pub.Address())})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var acc types.AppAccount
+	assert.Nil(t, json.Unmarshal(res.Value, &acc))
+	assert.Equal(t, "ICM", acc.EntityName)
+	assert.Equal(t, types.EntityIndividualClearingMember, acc.EntityType)
+
+	res = cc.Query(abci.RequestQuery{Path: types.RegisteredEntityQueryPath + "icm-2"})
+	assert.EqualValues(t, types.CodeInvalidEntity, res.Code, res.Log)
+}
+
+func TestApp_DualControl(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	ctx := cc.NewContext(false, abci.Header{})
+	cc.dualControl.SetParams(ctx, types.DualControlParams{ExpiryBlocks: 1, Thresholds: sdk.Coins{{"USD", 500}}})
+	makerAddr, makerPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	checkerAddr, checkerPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	// a single operator cannot deposit above the threshold
+	depositMsg := types.NewDepositMsg(checkerAddr, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700})
+	dres := cc.DeliverTx(makeTx(cc.cdc, depositMsg, checkerPrivKey))
+	assert.EqualValues(t, types.CodeDualControl, dres.Code, dres.Log)
+	proposeMsg := types.NewProposeTransferMsg(makerAddr, types.DepositType, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700})
+	dres = cc.DeliverTx(makeTx(cc.cdc, proposeMsg, makerPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	assert.Equal(t, "1", string(dres.Data))
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.PendingTransfersQueryPath})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var pending []types.PendingTransfer
+	assert.Nil(t, json.Unmarshal(res.Value, &pending))
+	assert.Equal(t, 1, len(pending))
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 2}})
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, types.NewApproveTransferMsg(checkerAddr, 1), 1, checkerPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, proposeMsg, 1, makerPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res = cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + hex.EncodeToString(memberAssetAddr) + "/USD"})
+	assert.Equal(t, `{"denom":"USD","amount":700}`, string(res.Value))
+
+	// the second proposal expires unless approved within a block
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 3}})
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	res = cc.Query(abci.RequestQuery{Path: types.TransferQueryPath + "2"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var expired types.PendingTransfer
+	assert.Nil(t, json.Unmarshal(res.Value, &expired))
+	assert.Equal(t, types.TransferExpired, expired.Status)
+	res = cc.Query(abci.RequestQuery{Path: types.PendingTransfersQueryPath})
+	assert.Equal(t, "[]", string(res.Value))
+}
+
+func TestApp_References(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	ctx := cc.NewContext(false, abci.Header{})
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	depositMsg := types.NewDepositMsg(chOpAddr, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700})
+	depositMsg.Reference, depositMsg.Memo = "wire-42", "initial margin"
+	dres := cc.DeliverTx(makeTx(cc.cdc, depositMsg, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	// resubmitting the deposit does not credit the member twice
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, depositMsg, 1, chOpPrivKey))
+	assert.EqualValues(t, types.CodeDuplicateReference, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + hex.EncodeToString(memberAssetAddr) + "/USD"})
+	assert.Equal(t, `{"denom":"USD","amount":700}`, string(res.Value))
+	res = cc.Query(abci.RequestQuery{Path: types.ReferenceQueryPath + hex.EncodeToString(custAssetAddr) + "/wire-42"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var rec types.TransferRecord
+	assert.Nil(t, json.Unmarshal(res.Value, &rec))
+	assert.Equal(t, types.DepositType, rec.TransferType)
+	assert.Equal(t, memberAssetAddr, rec.Recipient)
+	assert.Equal(t, "initial margin", rec.Memo)
+	assert.Equal(t, int64(1), rec.Height)
+	res = cc.Query(abci.RequestQuery{Path: types.ReferenceQueryPath + hex.EncodeToString(memberAssetAddr) + "/wire-42"})
+	assert.EqualValues(t, types.CodeInvalidReference, res.Code, res.Log)
+}
+
+func TestApp_History(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	ctx := cc.NewContext(false, abci.Header{})
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	firstTx := makeTx(cc.cdc, types.NewDepositMsg(chOpAddr, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700}), chOpPrivKey)
+	dres := cc.DeliverTx(firstTx)
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	withdrawMsg := types.NewWithdrawMsg(chOpAddr, memberAssetAddr, custAssetAddr, sdk.Coin{"USD", 200})
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, withdrawMsg, 1, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.HistoryQueryPath + hex.EncodeToString(memberAssetAddr)})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var page types.HistoryPage
+	assert.Nil(t, json.Unmarshal(res.Value, &page))
+	assert.Equal(t, int64(2), page.Total)
+	assert.Equal(t, 2, len(page.Postings))
+	assert.Equal(t, types.WithdrawType, page.Postings[0].MsgType)
+	assert.Equal(t, sdk.Coin{"USD", -200}, page.Postings[0].Amount)
+	assert.Equal(t, custAssetAddr, page.Postings[0].Counterparty)
+
+	res = cc.Query(abci.RequestQuery{Path: types.HistoryQueryPath + hex.EncodeToString(memberAssetAddr) + "/2/1"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	assert.Nil(t, json.Unmarshal(res.Value, &page))
+	assert.Equal(t, 1, len(page.Postings))
+	assert.Equal(t, types.DepositType, page.Postings[0].MsgType)
+	assert.Equal(t, sdk.Coin{"USD", 700}, page.Postings[0].Amount)
+	assert.Equal(t, int64(1), page.Postings[0].Height)
+	assert.Equal(t, common.HexBytes(tmtypes.Tx(firstTx).Hash()), page.Postings[0].TxHash)
+
+	tests := []struct {
+		name string
+		path string
+		code sdk.CodeType
+	}{
+		{"unknown account", types.HistoryQueryPath + hex.EncodeToString(crypto.GenPrivKeyEd25519().PubKey().Address()), sdk.CodeUnknownAddress},
+		{"missing limit", types.HistoryQueryPath + hex.EncodeToString(memberAssetAddr) + "/1", sdk.CodeUnknownRequest},
+		{"zero page", types.HistoryQueryPath + hex.EncodeToString(memberAssetAddr) + "/0/10", sdk.CodeUnknownRequest},
+		{"limit too high", types.HistoryQueryPath + hex.EncodeToString(memberAssetAddr) + "/1/101", sdk.CodeUnknownRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := cc.Query(abci.RequestQuery{Path: tt.path})
+			assert.EqualValues(t, tt.code, res.Code, res.Log)
+		})
+	}
+}
+
+func TestApp_Journal(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	ctx := cc.NewContext(false, abci.Header{})
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	tx := makeTx(cc.cdc, types.NewDepositMsg(chOpAddr, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700}), chOpPrivKey)
+	dres := cc.DeliverTx(tx)
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	// failed transactions are not journaled
+	overdraft := types.NewWithdrawMsg(chOpAddr, memberAssetAddr, custAssetAddr, sdk.Coin{"USD", 800})
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, overdraft, 1, chOpPrivKey))
+	assert.NotEqual(t, sdk.CodeOK, dres.Code)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.JournalQueryPath + "1"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var entry types.JournalEntry
+	assert.Nil(t, json.Unmarshal(res.Value, &entry))
+	assert.Equal(t, types.JournalEntry{
+		ID:      1,
+		Height:  1,
+		TxHash:  tmtypes.Tx(tx).Hash(),
+		MsgType: types.DepositType,
+		Lines:   types.TransferLines(custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700}),
+	}, entry)
+
+	for _, addr := range []sdk.Address{custAssetAddr, memberAssetAddr} {
+		res = cc.Query(abci.RequestQuery{Path: types.JournalBalanceQueryPath + hex.EncodeToString(addr)})
+		assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+		var balance types.JournalBalance
+		assert.Nil(t, json.Unmarshal(res.Value, &balance))
+		assert.True(t, balance.Reconciled)
+		assert.Equal(t, balance.Coins, balance.Journaled)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		code sdk.CodeType
+	}{
+		{"unknown entry", types.JournalQueryPath + "2", sdk.CodeUnknownRequest},
+		{"invalid id", types.JournalQueryPath + "one", sdk.CodeUnknownRequest},
+		{"unknown account", types.JournalBalanceQueryPath + hex.EncodeToString(crypto.GenPrivKeyEd25519().PubKey().Address()), sdk.CodeUnknownAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := cc.Query(abci.RequestQuery{Path: tt.path})
+			assert.EqualValues(t, tt.code, res.Code, res.Log)
+		})
+	}
+}
+
+func TestApp_Netting(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	ctx := cc.NewContext(false, abci.Header{})
+	cycle := cc.nettingMapper.GetCurrentCycle(ctx)
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	chAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityClearingHouse, "CH")
+	debtorAddr := fakeAssetAccount(cc, ctx, sdk.Coins{{"USD", 100}}, types.EntityIndividualClearingMember, "ICM1")
+	creditorAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM2")
+	obligation := types.NewSubmitObligationMsg(chOpAddr, debtorAddr, creditorAddr, sdk.Coin{"USD", 100})
+	dres := cc.DeliverTx(makeTx(cc.cdc, obligation, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, types.NewNetSettlementMsg(chOpAddr, chAssetAddr), 1, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.NettingQueryPath + strconv.FormatInt(cycle, 10)})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var result types.NettingResult
+	assert.Nil(t, json.Unmarshal(res.Value, &result))
+	assert.Equal(t, cycle, result.Cycle)
+	assert.Equal(t, int64(1), result.Height)
+	assert.Equal(t, chAssetAddr, result.Sender)
+	assert.Equal(t, 2, len(result.Positions))
+
+	tests := []struct {
+		name string
+		path string
+		code sdk.CodeType
+	}{
+		{"open cycle", types.NettingQueryPath + strconv.FormatInt(cycle+1, 10), types.CodeInvalidCycle},
+		{"invalid cycle", types.NettingQueryPath + "one", sdk.CodeUnknownRequest},
+		{"missing cycle", types.NettingQueryPath, sdk.CodeUnknownRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := cc.Query(abci.RequestQuery{Path: tt.path})
+			assert.EqualValues(t, tt.code, res.Code, res.Log)
+		})
+	}
+}
+
+func TestApp_Invariants(t *testing.T) {
+	cc := newTestClearchainApp()
+	chAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), nil, "CH", types.EntityClearingHouse)
+	chOpPrivKey := crypto.GenPrivKeyEd25519().Wrap()
+	chOp := types.NewOpUser(chOpPrivKey.PubKey(), chAdmin.Address, "CH", types.EntityClearingHouse)
+	custAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), chAdmin.Address, "CUST", types.EntityCustodian)
+	cust := types.NewAssetAccount(crypto.GenPrivKeyEd25519().PubKey(), sdk.Coins{{"USD", 1000}}, custAdmin.Address, "CUST", types.EntityCustodian)
+	icmAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), chAdmin.Address, "ICM", types.EntityIndividualClearingMember)
+	member := types.NewAssetAccount(crypto.GenPrivKeyEd25519().PubKey(), nil, icmAdmin.Address, "ICM", types.EntityIndividualClearingMember)
+	genesisState, err := types.NewGenesisState(nil, []*types.AppAccount{chAdmin, chOp, custAdmin, cust, icmAdmin, member})
+	assert.Nil(t, err)
+	stateBytes, err := json.Marshal(genesisState)
+	assert.Nil(t, err)
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	cc.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	query := func() []types.InvariantViolation {
+		res := cc.Query(abci.RequestQuery{Path: types.InvariantsQueryPath})
+		assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+		var violations []types.InvariantViolation
+		assert.Nil(t, json.Unmarshal(res.Value, &violations))
+		return violations
+	}
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 2}})
+	depositMsg := types.NewDepositMsg(chOp.Address, cust.Address, member.Address, sdk.Coin{"USD", 700})
+	dres := cc.DeliverTx(makeTx(cc.cdc, depositMsg, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	assert.Empty(t, query())
+
+	// coins that were not journaled
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 3}})
+	ctx := cc.NewContext(false, abci.Header{})
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{{"USD", 10}}, types.EntityCustodian, "CUST")
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	violations := query()
+	assert.Equal(t, 2, len(violations))
+	assert.Equal(t, types.InvariantJournal, violations[0].Invariant)
+	assert.Equal(t, custAssetAddr, violations[0].Account)
+	assert.Equal(t, types.InvariantConservation, violations[1].Invariant)
+
+	res := cc.Query(abci.RequestQuery{Path: types.InvariantsQueryPath + "/all"})
+	assert.EqualValues(t, sdk.CodeUnknownRequest, res.Code, res.Log)
+
+	// the chain halts when the check is due
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: types.DefaultInvariantCheckInterval}})
+	assert.Panics(t, func() { cc.EndBlock(abci.RequestEndBlock{}) })
+}
+
+// TestApp_InvariantsReportOnly verifies that the violations
+// of the invariants the handlers allow do not halt the chain.
+func TestApp_InvariantsReportOnly(t *testing.T) {
+	cc := newTestClearchainApp()
+	chAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), nil, "CH", types.EntityClearingHouse)
+	chOpPrivKey := crypto.GenPrivKeyEd25519().Wrap()
+	chOp := types.NewOpUser(chOpPrivKey.PubKey(), chAdmin.Address, "CH", types.EntityClearingHouse)
+	custAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), chAdmin.Address, "CUST", types.EntityCustodian)
+	cust := types.NewAssetAccount(crypto.GenPrivKeyEd25519().PubKey(), sdk.Coins{{"USD", 100}}, custAdmin.Address, "CUST", types.EntityCustodian)
+	icmAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), chAdmin.Address, "ICM", types.EntityIndividualClearingMember)
+	member := types.NewAssetAccount(crypto.GenPrivKeyEd25519().PubKey(), nil, icmAdmin.Address, "ICM", types.EntityIndividualClearingMember)
+	genesisState, err := types.NewGenesisState(nil, []*types.AppAccount{chAdmin, chOp, custAdmin, cust, icmAdmin, member})
+	assert.Nil(t, err)
+	genesisState.Invariants = &types.InvariantParams{CheckInterval: 1}
+	stateBytes, err := json.Marshal(genesisState)
+	assert.Nil(t, err)
+	cc.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+
+	// the custodian overdraws
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	depositMsg := types.NewDepositMsg(chOp.Address, cust.Address, member.Address, sdk.Coin{"USD", 700})
+	dres := cc.DeliverTx(makeTx(cc.cdc, depositMsg, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	assert.NotPanics(t, func() { cc.EndBlock(abci.RequestEndBlock{}) })
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.InvariantsQueryPath})
+	var violations []types.InvariantViolation
+	assert.Nil(t, json.Unmarshal(res.Value, &violations))
+	assert.Equal(t, 1, len(violations))
+	assert.Equal(t, types.InvariantCustodianBalance, violations[0].Invariant)
+}
+
+// TestApp_SingleStoreChain verifies that chains committed before the
+// stores were split, with all their data in the main store and their
+// accounts in the legacy layout, are loaded and exported to a new chain.
+func TestApp_SingleStoreChain(t *testing.T) {
+	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "app")
+	dbs := newMemDBs()
+	mainKey := sdk.NewKVStoreKey(types.MainStoreName)
+	ms := store.NewCommitMultiStore(dbs[types.MainStoreName])
+	ms.MountStoreWithDB(mainKey, sdk.StoreTypeIAVL, dbs[types.MainStoreName])
+	assert.Nil(t, ms.LoadLatestVersion())
+	// a clearing house admin, operator and asset account
+	// as they were stored before the stores were split
+	legacyAccounts := map[string]string{
+		"dd8c9efa93f268ceb8d8336f9b05c4074c9f6d71": "010114dd8c9efa93f268ceb8d8336f9b05c4074c9f6d710001de7f5ec1c84e76a930b476df44f310c585274a17af4371a23fc9cf3ce9b618f700000000000000000102434801026368000104757365720101",
+		"3a7a3ce5028b384636d76db85a22c0bbbba2e7e2": "0101143a7a3ce5028b384636d76db85a22c0bbbba2e7e20001ce721b929f7c89a7d9e6bf636663d820db2d23930c1f8e547652dffc549e79cc000000000000000001024348010263680114dd8c9efa93f268ceb8d8336f9b05c4074c9f6d710104757365720100",
+		"a7f24d6bf91b9f4170f898d7e50317bb5f86a00e": "010114a7f24d6bf91b9f4170f898d7e50317bb5f86a00e01010103555344000000000000006401939d538b43a780725a7170864158f57b605af84610a7072d88303ad004a08c94000000000000000001024348010263680114dd8c9efa93f268ceb8d8336f9b05c4074c9f6d71010561737365740100",
+	}
+	for addrHex, accHex := range legacyAccounts {
+		addr, _ := hex.DecodeString(addrHex)
+		bz, _ := hex.DecodeString(accHex)
+		ms.GetKVStore(mainKey).Set(addr, bz)
+	}
+	ms.Commit()
+
+	keys := []*sdk.KVStoreKey{mainKey}
+	for _, name := range types.StoreNames[1:] {
+		keys = append(keys, sdk.NewKVStoreKey(name))
+	}
+	assert.True(t, isSingleStoreChain(dbs, keys))
+	assert.False(t, isSingleStoreChain(newMemDBs(), keys))
+
+	cc := NewClearchainApp(logger, dbs)
+	assert.Equal(t, int64(1), cc.LastBlockHeight())
+	res := cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + "a7f24d6bf91b9f4170f898d7e50317bb5f86a00e/USD"})
+	assert.Equal(t, `{"denom":"USD","amount":100}`, string(res.Value))
+	genesisState, err := cc.ExportGenesis(0)
+	assert.Nil(t, err)
+	assert.Nil(t, genesisState.Validate())
+	assert.Equal(t, 1, len(genesisState.Operators))
+	assert.Equal(t, types.DefaultOperatorRoles(), genesisState.Operators[0].Roles)
+	assert.Equal(t, 1, len(genesisState.AssetAccounts))
+
+	// a new chain started from the export uses the dedicated stores
+	// and journals the exported balances
+	stateBytes, err := json.Marshal(genesisState)
+	assert.Nil(t, err)
+	dbs = newMemDBs()
+	cc = NewClearchainApp(logger, dbs)
+	cc.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	assert.False(t, isSingleStoreChain(dbs, keys))
+	res = cc.Query(abci.RequestQuery{Path: types.InvariantsQueryPath})
+	assert.Equal(t, "[]", string(res.Value))
+	res = cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + "a7f24d6bf91b9f4170f898d7e50317bb5f86a00e/USD"})
+	assert.Equal(t, `{"denom":"USD","amount":100}`, string(res.Value))
+}
+
+func TestApp_RotateKey(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chAdmAddr, chAdmPrivKey := fakeAdminAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{{"USD", 1000}}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	newPrivKey := crypto.GenPrivKeyEd25519().Wrap()
+	rotateMsg := types.NewRotateKeyMsg(chAdmAddr, chOpAddr, newPrivKey.PubKey())
+	dres := cc.DeliverTx(makeTx(cc.cdc, rotateMsg, chAdmPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+
+	// the old key is rejected, the account keeps its address
+	depositMsg := types.NewDepositMsg(chOpAddr, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700})
+	dres = cc.DeliverTx(makeTx(cc.cdc, depositMsg, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeUnauthorized, dres.Code, dres.Log)
+	dres = cc.DeliverTx(makeTx(cc.cdc, depositMsg, newPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	res := cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + hex.EncodeToString(memberAssetAddr) + "/USD"})
+	assert.Equal(t, `{"denom":"USD","amount":700}`, string(res.Value))
+}
+
+func TestApp_QueryRoutes(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{{"USD", 700}}, types.EntityIndividualClearingMember, "ICM")
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	hexAddr := hex.EncodeToString(memberAssetAddr)
+
+	res := cc.Query(abci.RequestQuery{Path: types.AccountQueryPath + hexAddr})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var acc types.AppAccount
+	assert.Nil(t, json.Unmarshal(res.Value, &acc))
+	assert.Equal(t, "ICM", acc.EntityName)
+	assert.Equal(t, sdk.Coins{{"USD", 700}}, acc.Coins)
+
+	res = cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + hexAddr + "/USD"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var coin sdk.Coin
+	assert.Nil(t, json.Unmarshal(res.Value, &coin))
+	assert.Equal(t, sdk.Coin{"USD", 700}, coin)
+
+	res = cc.Query(abci.RequestQuery{Path: types.BalanceQueryPath + hexAddr + "/EUR"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	assert.Nil(t, json.Unmarshal(res.Value, &coin))
+	assert.Equal(t, sdk.Coin{"EUR", 0}, coin)
+
+	res = cc.Query(abci.RequestQuery{Path: types.CurrenciesQueryPath})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var ccys []types.Currency
+	assert.Nil(t, json.Unmarshal(res.Value, &ccys))
+	assert.Equal(t, len(types.Currencies()), len(ccys))
+
+	// raw store queries still work
+	res = cc.Query(abci.RequestQuery{Data: memberAssetAddr, Path: "/accounts/key"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	assert.NotEqual(t, 0, len(res.Value))
+
+	tests := []struct {
+		name string
+		path string
+		code sdk.CodeType
+	}{
+		{"unknown route", types.QueryPathPrefix + "nothing", sdk.CodeUnknownRequest},
+		{"missing address", types.AccountQueryPath, sdk.CodeInvalidAddress},
+		{"unknown address", types.AccountQueryPath + hex.EncodeToString(crypto.GenPrivKeyEd25519().PubKey().Address()), sdk.CodeUnknownAddress},
+		{"unknown denom", types.BalanceQueryPath + hexAddr + "/ATM", types.CodeInvalidCurrency},
+		{"missing denom", types.BalanceQueryPath + hexAddr, sdk.CodeUnknownRequest},
+		{"missing entity", types.EntityQueryPath, sdk.CodeUnknownRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := cc.Query(abci.RequestQuery{Path: tt.path})
+			assert.EqualValues(t, tt.code, res.Code, res.Log)
+		})
+	}
+}
+
+// TestApp_QueryCommittedState verifies that queries ignore the writes
+// of checked transactions and only serve the last committed height.
+func TestApp_QueryCommittedState(t *testing.T) {
+	cc := newTestClearchainApp()
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chOpAddr, chOpPrivKey := fakeOpAccount(cc, ctx, types.EntityClearingHouse, "CH")
+	custAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityCustodian, "CUST")
+	memberAssetAddr := fakeAssetAccount(cc, ctx, sdk.Coins{}, types.EntityIndividualClearingMember, "ICM")
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	balancePath := types.BalanceQueryPath + hex.EncodeToString(memberAssetAddr) + "/USD"
+	balance := func(height int64) (sdk.Coin, abci.ResponseQuery) {
+		var coin sdk.Coin
+		res := cc.Query(abci.RequestQuery{Path: balancePath, Height: height})
+		if res.Code == uint32(sdk.CodeOK) {
+			assert.Nil(t, json.Unmarshal(res.Value, &coin))
+		}
+		return coin, res
+	}
+
+	depositTx := makeTx(cc.cdc, types.NewDepositMsg(chOpAddr, custAssetAddr, memberAssetAddr, sdk.Coin{"USD", 700}), chOpPrivKey)
+	cres := cc.CheckTx(depositTx)
+	assert.EqualValues(t, sdk.CodeOK, cres.Code, cres.Log)
+	coin, res := balance(0)
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	assert.Equal(t, int64(0), coin.Amount)
+	assert.Equal(t, int64(1), res.Height)
+
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	dres := cc.DeliverTx(depositTx)
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	coin, res = balance(2)
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	assert.Equal(t, int64(700), coin.Amount)
+
+	// past and future heights are not kept
+	for _, height := range []int64{1, 3} {
+		_, res = balance(height)
+		assert.EqualValues(t, sdk.CodeUnknownRequest, res.Code, res.Log)
+	}
+}
+
 //Test_Genesis is an end-to-end test that verifies the complete process of loading a genesis file.
 // It makes the app read an external genesis file and then verifies that all accounts were created by using the Query interface
 func Test_Genesis(t *testing.T) {
@@ -112,16 +756,16 @@ func Test_Genesis(t *testing.T) {
 	adminCreated1 := types.NewAdminUser(publicKey1, nil, "ClearChain", "ch")
 	stateBytes, _ := common.ReadFile(absPathFileOk)
 	vals := []abci.Validator{}
-	res := app.Query(abci.RequestQuery{Data: []byte("nothing"), Path: "/main/key"})
+	res := app.Query(abci.RequestQuery{Data: []byte("nothing"), Path: "/accounts/key"})
 	assert.Equal(t, 0, len(res.Value))
 	app.BeginBlock(abci.RequestBeginBlock{})
 	app.InitChain(abci.RequestInitChain{Validators: vals, AppStateBytes: stateBytes})
-	app.Commit()
 	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
 
 	expAcc := adminCreated1
 	// Query the existing data
-	res = app.Query(abci.RequestQuery{Data: expAcc.GetAddress().Bytes(), Path: "/main/key"})
+	res = app.Query(abci.RequestQuery{Data: expAcc.GetAddress().Bytes(), Path: "/accounts/key"})
 	assert.NotNil(t, res.GetValue())
 	var foundAcc *types.AppAccount
 	err = codec.UnmarshalBinary(res.Value, &foundAcc)
@@ -143,14 +787,221 @@ func Test_Genesis(t *testing.T) {
 	assert.True(t, foundAcc.Admin)
 }
 
+// Test_GenesisAccounts loads a genesis file that declares entities,
+// users and asset accounts with opening balances.
+func Test_GenesisAccounts(t *testing.T) {
+	app := newTestClearchainApp()
+	absPath, err := filepath.Abs("test/genesis_ok_accounts_test.json")
+	assert.Nil(t, err)
+	stateBytes, err := common.ReadFile(absPath)
+	assert.Nil(t, err)
+	app.BeginBlock(abci.RequestBeginBlock{})
+	app.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+	app.EndBlock(abci.RequestEndBlock{})
+	app.Commit()
+
+	query := func(hexAddr string) *types.AppAccount {
+		res := app.Query(abci.RequestQuery{Path: types.AccountQueryPath + hexAddr})
+		assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+		var acc types.AppAccount
+		assert.Nil(t, json.Unmarshal(res.Value, &acc))
+		return &acc
+	}
+	icmAdmin := query("bfe98af3bc9ca68b1d6780738456a7d82ab5865b")
+	assert.True(t, icmAdmin.IsAdmin())
+	assert.True(t, icmAdmin.IsActive())
+	assert.Equal(t, types.EntityIndividualClearingMember, icmAdmin.EntityType)
+	custAdmin := query("75f8da13966fc1b71de0af57bba103e9483ae85a")
+	assert.False(t, custAdmin.IsActive())
+	icmOp := query("11bf54cd330e00d6ac91e52fa1df6e8c0514920c")
+	assert.False(t, icmOp.IsAdmin())
+	assert.Equal(t, "ICM1", icmOp.EntityName)
+	icmAsset := query("4dad5fad6a7d4da7b679b2d26e37b1a32af80560")
+	assert.Equal(t, sdk.Coins{{"USD", -50}}, icmAsset.Coins)
+	assert.Equal(t, int64(100), icmAsset.GetCreditLimit("USD"))
+	chAsset := query("601b8cf741f086528052040c38f7b39737f98cd1")
+	assert.Equal(t, sdk.Coins{{"EUR", 1000}, {"USD", 50}}, chAsset.Coins)
+	assert.Equal(t, types.EntityClearingHouse, chAsset.EntityType)
+
+	res := app.Query(abci.RequestQuery{Path: types.EntityQueryPath + "ICM1"})
+	var accounts []types.EntityAccount
+	assert.Nil(t, json.Unmarshal(res.Value, &accounts))
+	assert.Equal(t, 3, len(accounts))
+
+	// the genesis balances are journaled
+	for _, hexAddr := range []string{"4dad5fad6a7d4da7b679b2d26e37b1a32af80560", "601b8cf741f086528052040c38f7b39737f98cd1"} {
+		res = app.Query(abci.RequestQuery{Path: types.JournalBalanceQueryPath + hexAddr})
+		assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+		var balance types.JournalBalance
+		assert.Nil(t, json.Unmarshal(res.Value, &balance))
+		assert.True(t, balance.Reconciled, hexAddr)
+	}
+	res = app.Query(abci.RequestQuery{Path: types.JournalQueryPath + "1"})
+	assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+	var entry types.JournalEntry
+	assert.Nil(t, json.Unmarshal(res.Value, &entry))
+	assert.Equal(t, types.GenesisEntryType, entry.MsgType)
+}
+
+// Test_InvalidGenesis verifies that the node halts on
+// a malformed or invalid genesis state.
+func Test_InvalidGenesis(t *testing.T) {
+	for _, stateBytes := range [][]byte{
+		[]byte(`{"ch_admin": `),
+		[]byte(`{"ch_admin": {"public_key": "zz", "entity_name": "CH"}}`),
+	} {
+		app := newTestClearchainApp()
+		assert.Panics(t, func() {
+			app.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+		}, string(stateBytes))
+	}
+}
+
+// TestApp_ExportGenesis verifies that an exported genesis
+// state recreates the ledger it was exported from.
+func TestApp_ExportGenesis(t *testing.T) {
+	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "app")
+	dbs := newMemDBs()
+	cc := NewClearchainApp(logger, dbs)
+	absPath, err := filepath.Abs("test/genesis_ok_accounts_test.json")
+	assert.Nil(t, err)
+	stateBytes, err := common.ReadFile(absPath)
+	assert.Nil(t, err)
+	cc.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: stateBytes})
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	// at height 2 the clearing house settles 50 USD with the member
+	cc.BeginBlock(abci.RequestBeginBlock{})
+	ctx := cc.NewContext(false, abci.Header{})
+	chAdmin, _ := sdk.GetAddress("ddbfaffab1edd93f79c6808eb2a6c6ce25c70a2b")
+	chOpPrivKey := crypto.GenPrivKeyEd25519()
+	chOpAddr := chOpPrivKey.PubKey().Address()
+	cc.accountMapper.SetAccount(ctx, types.NewOpUser(chOpPrivKey.PubKey(), chAdmin, "ClearChain", types.EntityClearingHouse))
+	chAsset, _ := sdk.GetAddress("601b8cf741f086528052040c38f7b39737f98cd1")
+	memberAsset, _ := sdk.GetAddress("4dad5fad6a7d4da7b679b2d26e37b1a32af80560")
+	settleMsg := types.NewSettleMsg(chOpAddr, chAsset, memberAsset, sdk.Coin{"USD", 50})
+	dres := cc.DeliverTx(makeTx(cc.cdc, settleMsg, chOpPrivKey.Wrap()))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+
+	genesisState, err := cc.ExportGenesis(0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(genesisState.Admins))
+	assert.Equal(t, 3, len(genesisState.Entities))
+	for _, ge := range genesisState.Entities {
+		assert.Equal(t, ge.Name, ge.ID)
+		assert.Equal(t, types.EntityStatusActive, ge.Status)
+	}
+	accounts, err := genesisState.ToAppAccounts()
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(accounts))
+
+	// re-genesis a new chain from the export
+	exported, err := json.Marshal(genesisState)
+	assert.Nil(t, err)
+	cc2 := newTestClearchainApp()
+	cc2.InitChain(abci.RequestInitChain{Validators: []abci.Validator{}, AppStateBytes: exported})
+	cc2.BeginBlock(abci.RequestBeginBlock{})
+	cc2.EndBlock(abci.RequestEndBlock{})
+	cc2.Commit()
+	for _, acc := range accounts {
+		res := cc2.Query(abci.RequestQuery{Path: types.AccountQueryPath + hex.EncodeToString(acc.Address)})
+		assert.EqualValues(t, sdk.CodeOK, res.Code, res.Log)
+		var found types.AppAccount
+		assert.Nil(t, json.Unmarshal(res.Value, &found))
+		assert.True(t, acc.Coins.IsEqual(found.Coins))
+		assert.Equal(t, acc.Active, found.Active)
+		assert.True(t, bytes.Equal(acc.Creator, found.Creator))
+	}
+	res := cc2.Query(abci.RequestQuery{Path: types.BalanceQueryPath + hex.EncodeToString(memberAsset) + "/USD"})
+	assert.Equal(t, `{"denom":"USD","amount":0}`, string(res.Value))
+	res = cc2.Query(abci.RequestQuery{Path: types.EntitiesQueryPath})
+	var entities []types.Entity
+	assert.Nil(t, json.Unmarshal(res.Value, &entities))
+	assert.Equal(t, genesisState.ToEntities(), entities)
+
+	// the first height predates the settlement
+	old, err := NewClearchainApp(logger, dbs).ExportGenesis(1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(old.Operators))
+	for _, ga := range old.AssetAccounts {
+		if ga.PubKeyHexa == "0190a4524cc186a197a872042d1140548ab52850254fd85f2d4659f7f9be5aa9be" {
+			assert.Equal(t, sdk.Coins{{"USD", -50}}, ga.Coins)
+		}
+	}
+	_, err = NewClearchainApp(logger, dbs).ExportGenesis(10)
+	assert.NotNil(t, err)
+}
+
+// TestApp_ExportGenesisOpenState verifies that chains are not exported
+// while a netting cycle is open or transfers are pending.
+func TestApp_ExportGenesisOpenState(t *testing.T) {
+	cc := newTestClearchainApp()
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 1}})
+	ctx := cc.NewContext(false, abci.Header{})
+	cycle := cc.nettingMapper.GetCurrentCycle(ctx)
+	// exported accounts need a creator
+	chAdmin := types.NewAdminUser(crypto.GenPrivKeyEd25519().PubKey(), nil, "CH", types.EntityClearingHouse)
+	cc.accountMapper.SetAccount(ctx, chAdmin)
+	newUser := func() (sdk.Address, crypto.PrivKey) {
+		priv := crypto.GenPrivKeyEd25519()
+		cc.accountMapper.SetAccount(ctx, types.NewOpUser(priv.PubKey(), chAdmin.Address, "CH", types.EntityClearingHouse))
+		return priv.PubKey().Address(), priv.Wrap()
+	}
+	newAsset := func(entityName, typ string) sdk.Address {
+		pub := crypto.GenPrivKeyEd25519().PubKey()
+		cc.accountMapper.SetAccount(ctx, types.NewAssetAccount(pub, sdk.Coins{}, chAdmin.Address, entityName, typ))
+		return pub.Address()
+	}
+	chOpAddr, chOpPrivKey := newUser()
+	checkerAddr, checkerPrivKey := newUser()
+	custAssetAddr := newAsset("CUST", types.EntityCustodian)
+	debtorAddr := newAsset("ICM1", types.EntityIndividualClearingMember)
+	creditorAddr := newAsset("ICM2", types.EntityIndividualClearingMember)
+	obligation := types.NewSubmitObligationMsg(chOpAddr, debtorAddr, creditorAddr, sdk.Coin{"USD", 100})
+	dres := cc.DeliverTx(makeTx(cc.cdc, obligation, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	_, err := cc.ExportGenesis(0)
+	assert.EqualError(t, err, fmt.Sprintf("netting cycle %d has 1 open obligations, settle or cancel it first", cycle))
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 2}})
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, types.NewCancelNettingCycleMsg(chOpAddr, cycle), 1, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	proposeMsg := types.NewProposeTransferMsg(chOpAddr, types.DepositType, custAssetAddr, debtorAddr, sdk.Coin{"USD", 700})
+	dres = cc.DeliverTx(makeTxWithSequence(cc.cdc, proposeMsg, 2, chOpPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	_, err = cc.ExportGenesis(0)
+	assert.EqualError(t, err, "1 transfers are pending, approve or reject them first")
+
+	cc.BeginBlock(abci.RequestBeginBlock{Header: abci.Header{Height: 3}})
+	dres = cc.DeliverTx(makeTx(cc.cdc, types.NewRejectTransferMsg(checkerAddr, 1), checkerPrivKey))
+	assert.EqualValues(t, sdk.CodeOK, dres.Code, dres.Log)
+	cc.EndBlock(abci.RequestEndBlock{})
+	cc.Commit()
+	_, err = cc.ExportGenesis(0)
+	assert.Nil(t, err)
+}
+
 func makeTx(cdc *wire.Codec, msg sdk.Msg, keys ...crypto.PrivKey) []byte {
+	return makeTxWithSequence(cdc, msg, 0, keys...)
+}
+
+func makeTxWithSequence(cdc *wire.Codec, msg sdk.Msg, seq int64, keys ...crypto.PrivKey) []byte {
 	sigs := make([]sdk.StdSignature, len(keys))
 	for i, k := range keys {
-		sig := k.Sign(sdk.StdSignBytes("", []int64{0}, sdk.StdFee{}, msg))
+		sig := k.Sign(sdk.StdSignBytes("", []int64{seq}, sdk.StdFee{}, msg))
 		sigs[i] = sdk.StdSignature{
 			PubKey:    k.PubKey(),
 			Signature: sig,
-			Sequence:  0,
+			Sequence:  seq,
 		}
 	}
 	tx := sdk.NewStdTx(msg, sdk.StdFee{}, sigs)
@@ -192,6 +1043,14 @@ func fakeAdminAccount(cc *ClearchainApp, ctx sdk.Context, typ string, entityName
 // newTestClearchainApp a ClearchainApp with an in-memory datastore
 func newTestClearchainApp() *ClearchainApp {
 	logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).With("module", "app")
-	db := dbm.NewMemDB()
-	return NewClearchainApp(logger, db)
+	return NewClearchainApp(logger, newMemDBs())
+}
+
+// newMemDBs returns an in-memory database for every store
+func newMemDBs() map[string]dbm.DB {
+	dbs := make(map[string]dbm.DB)
+	for _, name := range types.StoreNames {
+		dbs[name] = dbm.NewMemDB()
+	}
+	return dbs
 }
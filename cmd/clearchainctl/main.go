@@ -5,7 +5,6 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/keys"
-	"github.com/cosmos/cosmos-sdk/client/lcd"
 	"github.com/cosmos/cosmos-sdk/client/rpc"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/commands"
@@ -37,21 +36,48 @@ func main() {
 	// add clearchain-specific commands
 	clearchainctlCmd.AddCommand(
 		client.GetCommands(
-			authcmd.GetAccountCmd("main", cdc, types.GetAccountDecoder(cdc)),
+			authcmd.GetAccountCmd(types.AccountsStoreName, cdc, types.GetAccountDecoder(cdc)),
+			commands.GetNettingResultCmd(),
+			commands.GetHistoryCmd(),
 		)...)
 	clearchainctlCmd.AddCommand(
 		client.PostCommands(
+			commands.GetRegisterEntityTxCmd(cdc),
+			commands.GetUpdateEntityStatusTxCmd(cdc),
 			commands.GetCreateAdminTxCmd(cdc),
 			commands.GetCreateOperatorTxCmd(cdc),
 			commands.GetCreateAssetAccountTxCmd(cdc),
+			commands.GetFreezeOperatorTxCmd(cdc),
+			commands.GetFreezeAdminTxCmd(cdc),
+			commands.GetUnfreezeOperatorTxCmd(cdc),
+			commands.GetUnfreezeAdminTxCmd(cdc),
+			commands.GetSetCreditLimitTxCmd(cdc),
+			commands.GetSetOperatorLimitsTxCmd(cdc),
+			commands.GetGrantRoleTxCmd(cdc),
+			commands.GetRevokeRoleTxCmd(cdc),
+			commands.GetRotateKeyTxCmd(cdc),
+			commands.GetCloseAssetAccountTxCmd(cdc),
+			commands.GetDepositTxCmd(cdc),
+			commands.GetSettleTxCmd(cdc),
+			commands.GetWithdrawTxCmd(cdc),
+			commands.GetProposeTransferTxCmd(cdc),
+			commands.GetApproveTransferTxCmd(cdc),
+			commands.GetRejectTransferTxCmd(cdc),
+			commands.GetSubmitObligationTxCmd(cdc),
+			commands.GetNetSettleTxCmd(cdc),
+			commands.GetCancelNettingCycleTxCmd(cdc),
 		)...)
+	clearchainctlCmd.AddCommand(commands.GetEntityCmd())
+	clearchainctlCmd.AddCommand(commands.GetTransfersCmd())
+	clearchainctlCmd.AddCommand(commands.GetJournalCmd())
+	clearchainctlCmd.AddCommand(commands.GetInvariantsCmd())
 	clearchainctlCmd.AddCommand(commands.GetExportPubCmd(cdc))
 	//clearchainctlCmd.AddCommand(commands.GetImportPubCmd(cdc))
 
 	// add proxy, version and key info
 	clearchainctlCmd.AddCommand(
 		client.LineBreak,
-		lcd.ServeCommand(cdc),
+		commands.ServeCommand(cdc),
 		keys.Commands(),
 		client.LineBreak,
 		commands.VersionCmd,
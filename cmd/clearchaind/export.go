@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/app"
+	"github.com/tendermint/tmlibs/cli"
+	"github.com/tendermint/tmlibs/log"
+)
+
+const (
+	flagHeight = "height"
+	flagOutput = "output"
+)
+
+// exportCommand dumps the ledger as a genesis state that can be
+// used as the app_state of a new chain's genesis file.
+func exportCommand(logger log.Logger) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the ledger as the app state of a genesis file",
+		Long: `Export walks the account store and prints a genesis app state made of
+the legal entities, users and asset accounts, with their balances, active
+and admin flags and creators. The node must not be running.
+
+The export fails while the current netting cycle has obligations or
+transfers are pending approval: settle or cancel the cycle and approve
+or reject the transfers first.
+
+Chains started before the accounts, entities, journal and params stores
+were split from the main store must be exported and restarted: their
+accounts are read in their legacy layout, users get the default roles of
+their kind, and starting a new chain from the export moves them to the
+dedicated stores. The new chain's journal opens with the exported balances.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbs, err := openDBs(viper.GetString(cli.HomeFlag))
+			if err != nil {
+				return err
+			}
+			defer closeDBs(dbs)
+			genesisState, err := app.NewClearchainApp(logger, dbs).ExportGenesis(viper.GetInt64(flagHeight))
+			if err != nil {
+				return err
+			}
+			bz, err := json.MarshalIndent(genesisState, "", "  ")
+			if err != nil {
+				return err
+			}
+			if output := viper.GetString(flagOutput); output != "" {
+				return ioutil.WriteFile(output, bz, 0644)
+			}
+			fmt.Println(string(bz))
+			return nil
+		},
+	}
+	cmd.Flags().Int64(flagHeight, 0, "Height to export, defaults to the latest")
+	cmd.Flags().String(flagOutput, "", "File to write to, defaults to stdout")
+	return cmd
+}
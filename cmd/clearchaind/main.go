@@ -69,6 +69,8 @@ func main() {
 		server.UnsafeResetAllCmd(logger),
 		server.ShowNodeIdCmd(logger),
 		server.ShowValidatorCmd(logger),
+		exportCommand(logger),
+		validateGenesisCommand(),
 		versionCmd,
 	)
 	// prepare and add flags
@@ -105,14 +107,45 @@ func defaultOptions(args []string) (json.RawMessage, string, cmn.HexBytes, error
 }
 
 func generateApp(rootDir string, logger log.Logger) (abci.Application, error) {
-	db, err := dbm.NewGoLevelDB("clearchain", rootDir)
+	dbs, err := openDBs(rootDir)
 	if err != nil {
 		return nil, err
 	}
-	bapp := app.NewClearchainApp(logger, db)
+	bapp := app.NewClearchainApp(logger, dbs)
+	// the genesis file is only read when the chain is initialized
+	if bapp.LastBlockHeight() == 0 {
+		if err := validateHomeGenesis(rootDir); err != nil {
+			return nil, err
+		}
+	}
 	return bapp, nil
 }
 
+// openDBs opens the stores' databases under rootDir. The main store keeps
+// the clearchain database, which held every store before they were split.
+func openDBs(rootDir string) (map[string]dbm.DB, error) {
+	dbs := make(map[string]dbm.DB)
+	for _, name := range types.StoreNames {
+		dbName := "clearchain"
+		if name != types.MainStoreName {
+			dbName += "-" + name
+		}
+		db, err := dbm.NewGoLevelDB(dbName, rootDir)
+		if err != nil {
+			closeDBs(dbs)
+			return nil, err
+		}
+		dbs[name] = db
+	}
+	return dbs, nil
+}
+
+func closeDBs(dbs map[string]dbm.DB) {
+	for _, db := range dbs {
+		db.Close()
+	}
+}
+
 func generateKey() (crypto.PubKey, string, error) {
 	// construct an in-memory key store
 	codec, err := words.LoadCodec("english")
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+	cfg "github.com/tendermint/tendermint/config"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tmlibs/cli"
+)
+
+// validateGenesisCommand checks a genesis file's app state and
+// lists all of its problems, so that they are fixed before nodes start.
+func validateGenesisCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate-genesis [genesis-file]",
+		Short: "Validate the app state of a genesis file",
+		Long: `Validate-genesis checks the genesis app state against the rules the
+handlers enforce and prints every problem found. It defaults to the
+genesis file in the node's home directory.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			genesisFile := defaultGenesisFile(viper.GetString(cli.HomeFlag))
+			if len(args) != 0 {
+				genesisFile = args[0]
+			}
+			if err := validateGenesisFile(genesisFile); err != nil {
+				return err
+			}
+			fmt.Printf("%s is a valid genesis file\n", genesisFile)
+			return nil
+		},
+	}
+}
+
+func defaultGenesisFile(rootDir string) string {
+	return cfg.DefaultConfig().SetRoot(rootDir).GenesisFile()
+}
+
+// validateGenesisFile reads a genesis file and validates its app state.
+func validateGenesisFile(genesisFile string) error {
+	genDoc, err := tmtypes.GenesisDocFromFile(genesisFile)
+	if err != nil {
+		return err
+	}
+	if _, err = types.ParseGenesisState(genDoc.AppState()); err != nil {
+		return fmt.Errorf("invalid app state in %s:\n%v", genesisFile, err)
+	}
+	return nil
+}
+
+// validateHomeGenesis validates the home directory's genesis
+// file, if any, before the chain is initialized from it.
+func validateHomeGenesis(rootDir string) error {
+	genesisFile := defaultGenesisFile(rootDir)
+	if _, err := os.Stat(genesisFile); os.IsNotExist(err) {
+		return nil
+	}
+	return validateGenesisFile(genesisFile)
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetCancelNettingCycleTxCmd returns a cancelNettingCycleTxCmd.
+func GetCancelNettingCycleTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "cancel-netting-cycle",
+		Short: "Create and sign a CancelNettingCycleTx",
+		RunE:  cmdr.cancelNettingCycleTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().Int64(flagCycle, 0, "Current netting cycle")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+	return cmd
+}
+
+func (c Commander) cancelNettingCycleTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	operator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg := types.NewCancelNettingCycleMsg(operator, viper.GetInt64(flagCycle))
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetApproveTransferTxCmd returns an approveTransferTxCmd.
+func GetApproveTransferTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "approve-transfer",
+		Short: "Create and sign an ApproveTransferTx",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdr.checkTransferTxCmd(args[0], func(checker sdk.Address, id int64) sdk.Msg {
+				return types.NewApproveTransferMsg(checker, id)
+			})
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	addCheckTransferFlags(cmd)
+	return cmd
+}
+
+// GetRejectTransferTxCmd returns a rejectTransferTxCmd.
+func GetRejectTransferTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "reject-transfer",
+		Short: "Create and sign a RejectTransferTx",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdr.checkTransferTxCmd(args[0], func(checker sdk.Address, id int64) sdk.Msg {
+				return types.NewRejectTransferMsg(checker, id)
+			})
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	addCheckTransferFlags(cmd)
+	return cmd
+}
+
+func addCheckTransferFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64(flagID, 0, "Pending transfer's ID")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+}
+
+func (c Commander) checkTransferTxCmd(name string, newMsg func(sdk.Address, int64) sdk.Msg) error {
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	checker, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg := newMsg(checker, viper.GetInt64(flagID))
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
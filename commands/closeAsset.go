@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetCloseAssetAccountTxCmd returns a closeAssetAccountTxCmd.
+func GetCloseAssetAccountTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "close-asset",
+		Short: "Create and sign a CloseAssetAccountTx",
+		RunE:  cmdr.closeAssetAccountTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().String(flagTarget, "", "Asset account's address")
+	cmd.Flags().String(flagSweepTo, "", "Address of the asset account remaining balances are swept to")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+	return cmd
+}
+
+func (c Commander) closeAssetAccountTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	admin, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := buildCloseAssetAccountMsg(admin)
+	if err != nil {
+		return err
+	}
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
+
+func buildCloseAssetAccountMsg(admin sdk.Address) (sdk.Msg, error) {
+	// parse target account address
+	target, err := sdk.GetAddress(viper.GetString(flagTarget))
+	if err != nil {
+		return nil, err
+	}
+	// the sweep account is optional
+	var sweepTo sdk.Address
+	if s := viper.GetString(flagSweepTo); s != "" {
+		if sweepTo, err = sdk.GetAddress(s); err != nil {
+			return nil, err
+		}
+	}
+	msg := types.NewCloseAssetAccountMsg(admin, target, sweepTo)
+	return msg, nil
+}
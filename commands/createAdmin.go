@@ -14,10 +14,32 @@ import (
 )
 
 const (
-	flagPubKey     = "pubkey"
-	flagEntityName = "entityname"
-	flagEntityType = "entitytype"
-	flagSequence   = "seq"
+	flagPubKey       = "pubkey"
+	flagEntityID     = "entity-id"
+	flagEntityName   = "entityname"
+	flagEntityType   = "entitytype"
+	flagLEI          = "lei"
+	flagCountry      = "country"
+	flagStatus       = "status"
+	flagSequence     = "seq"
+	flagFromAddress  = "from-address"
+	flagTarget       = "target"
+	flagSender       = "sender"
+	flagRecipient    = "recipient"
+	flagAmount       = "amount"
+	flagDebtor       = "debtor"
+	flagCreditor     = "creditor"
+	flagCycle        = "cycle"
+	flagTransferType = "type"
+	flagID           = "id"
+	flagMaxAmount    = "max-amount"
+	flagDailyLimit   = "daily-limit"
+	flagRole         = "role"
+	flagSweepTo      = "sweep-to"
+	flagReference    = "reference"
+	flagMemo         = "memo"
+	flagPage         = "page"
+	flagLimit        = "limit"
 )
 
 type Commander struct {
@@ -34,9 +56,9 @@ func GetCreateAdminTxCmd(cdc *wire.Codec) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 	}
 	cmd.Flags().String(flagPubKey, "", "New admin's pubkey")
-	cmd.Flags().String(flagEntityName, "", "New admin's entity name")
-	cmd.Flags().String(flagEntityType, "", "New admin's entity type (ch|gcm|icm|custodian)")
+	cmd.Flags().String(flagEntityID, "", "ID of the new admin's registered entity")
 	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
 	return cmd
 }
 
@@ -50,8 +72,11 @@ func (c Commander) createAdminTxCmd(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	creator := info.PubKey.Address()
-	msg, err := BuildCreateAdminMsg(creator, viper.GetString(flagEntityName), viper.GetString(flagEntityType), viper.GetString(flagPubKey))
+	creator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := BuildCreateAdminMsg(creator, viper.GetString(flagEntityID), viper.GetString(flagPubKey))
 	if err != nil {
 		return err
 	}
@@ -66,11 +91,11 @@ func (c Commander) createAdminTxCmd(cmd *cobra.Command, args []string) error {
 }
 
 // BuildCreateAdminMsg makes a new CreateAdminMsg.
-func BuildCreateAdminMsg(creator sdk.Address, entityName, entityType, pubKey string) (sdk.Msg, error) {
+func BuildCreateAdminMsg(creator sdk.Address, entityID, pubKey string) (sdk.Msg, error) {
 	// parse new account pubkey
 	pub, err := types.PubKeyFromHexString(pubKey)
 	if err != nil {
 		return nil, err
 	}
-	return types.NewCreateAdminMsg(creator, pub, entityName, entityType), nil
+	return types.NewCreateAdminMsg(creator, pub, entityID), nil
 }
@@ -24,6 +24,7 @@ func GetCreateOperatorTxCmd(cdc *wire.Codec) *cobra.Command {
 	}
 	cmd.Flags().String(flagPubKey, "", "New operator's pubkey")
 	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
 	return cmd
 }
 
@@ -37,7 +38,10 @@ func (c Commander) createOperatorTxCmd(cmd *cobra.Command, args []string) error
 	if err != nil {
 		return err
 	}
-	creator := info.PubKey.Address()
+	creator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
 	msg, err := buildCreateOperatorMsg(creator)
 	if err != nil {
 		return err
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetDepositTxCmd returns a depositTxCmd.
+func GetDepositTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "deposit",
+		Short: "Create and sign a DepositTx",
+		RunE:  cmdr.depositTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	addTransferFlags(cmd)
+	return cmd
+}
+
+func (c Commander) depositTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	operator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := buildDepositMsg(operator)
+	if err != nil {
+		return err
+	}
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+
+}
+
+func buildDepositMsg(operator sdk.Address) (sdk.Msg, error) {
+	targs, err := parseTransferFlags()
+	if err != nil {
+		return nil, err
+	}
+	msg := types.NewDepositMsg(operator, targs.sender, targs.recipient, targs.amount)
+	msg.Reference, msg.Memo = targs.reference, targs.memo
+	return msg, nil
+}
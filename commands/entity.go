@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetEntityCmd returns the legal entity query commands.
+func GetEntityCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "entity",
+		Short: "Query legal entities",
+	}
+	cmd.AddCommand(client.GetCommands(
+		&cobra.Command{
+			Use:   "accounts <name>",
+			Short: "List the users and asset accounts of a legal entity",
+			Args:  cobra.ExactArgs(1),
+			RunE:  entityAccountsCmd,
+		},
+		&cobra.Command{
+			Use:   "list",
+			Short: "List the registered legal entities",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.EntitiesQueryPath, &[]types.Entity{})
+			},
+		},
+		&cobra.Command{
+			Use:   "get <id>",
+			Short: "Show a registered legal entity",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.RegisteredEntityQueryPath+args[0], &types.Entity{})
+			},
+		},
+	)...)
+	return cmd
+}
+
+func entityAccountsCmd(cmd *cobra.Command, args []string) error {
+	bz, err := queryPath(types.EntityQueryPath + args[0])
+	if err != nil {
+		return err
+	}
+	var accounts []types.EntityAccount
+	if err := json.Unmarshal(bz, &accounts); err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(accounts, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetHistoryCmd returns the account history query command.
+func GetHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history <address>",
+		Short: "List the deposits, settlements and withdrawals that touched an asset account",
+		Long: `History lists an asset account's postings, newest first: the
+counterparty, the signed amount, the message type and the transaction hash.`,
+		Args: cobra.ExactArgs(1),
+		RunE: historyCmd,
+	}
+	cmd.Flags().Int64(flagPage, 1, "Page number, starting at 1")
+	cmd.Flags().Int64(flagLimit, types.DefaultHistoryLimit, fmt.Sprintf("Postings per page, at most %d", types.MaxHistoryLimit))
+	return cmd
+}
+
+func historyCmd(cmd *cobra.Command, args []string) error {
+	path := fmt.Sprintf("%s%s/%d/%d", types.HistoryQueryPath, args[0], viper.GetInt64(flagPage), viper.GetInt64(flagLimit))
+	bz, err := queryPath(path)
+	if err != nil {
+		return err
+	}
+	var page types.HistoryPage
+	if err := json.Unmarshal(bz, &page); err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetInvariantsCmd returns the ledger invariants check command.
+func GetInvariantsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "invariants",
+		Short: "Check the ledger invariants against the last committed state",
+		Long: `Invariants checks that asset balances sum to the external funds,
+that members are within their credit limits, that custodians are not
+negative, that every account's creator exists and that balances match
+the journal. It lists the violations and fails if there is any.`,
+		Args: cobra.NoArgs,
+		RunE: invariantsCmd,
+	}
+}
+
+func invariantsCmd(cmd *cobra.Command, args []string) error {
+	var violations []types.InvariantViolation
+	if err := printQueryResult(types.InvariantsQueryPath, &violations); err != nil {
+		return err
+	}
+	if len(violations) != 0 {
+		return fmt.Errorf("%d ledger invariants violated", len(violations))
+	}
+	return nil
+}
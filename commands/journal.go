@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetJournalCmd returns the journal query commands.
+func GetJournalCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Query the double-entry journal of balance changes",
+	}
+	cmd.AddCommand(client.GetCommands(
+		&cobra.Command{
+			Use:   "get <id>",
+			Short: "Show a journal entry and its lines",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.JournalQueryPath+args[0], &types.JournalEntry{})
+			},
+		},
+		&cobra.Command{
+			Use:   "balance <address>",
+			Short: "Compare an asset account's balance with the sum of its journal lines",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.JournalBalanceQueryPath+args[0], &types.JournalBalance{})
+			},
+		},
+	)...)
+	return cmd
+}
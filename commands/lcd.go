@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/client/rpc"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/version"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	auth "github.com/cosmos/cosmos-sdk/x/auth/rest"
+	"github.com/gorilla/mux"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+	tmserver "github.com/tendermint/tendermint/rpc/lib/server"
+	cmn "github.com/tendermint/tmlibs/common"
+	"github.com/tendermint/tmlibs/log"
+)
+
+const (
+	flagListenAddr = "laddr"
+	flagCORS       = "cors"
+)
+
+// ServeCommand returns a command that starts the LCD (light-client daemon),
+// a local REST server that exposes the SDK's routes and clearchain's queries.
+func ServeCommand(cdc *wire.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rest-server",
+		Short: "Start LCD (light-client daemon), a local REST server",
+		RunE:  startRESTServerFn(cdc),
+	}
+	cmd.Flags().StringP(flagListenAddr, "a", "tcp://localhost:1317", "Address for server to listen on")
+	cmd.Flags().String(flagCORS, "", "Set to domains that can make CORS requests (* for all)")
+	cmd.Flags().StringP(client.FlagChainID, "c", "", "ID of chain we connect to")
+	cmd.Flags().StringP(client.FlagNode, "n", "tcp://localhost:46657", "Node to connect to")
+	return cmd
+}
+
+func startRESTServerFn(cdc *wire.Codec) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		listenAddr := viper.GetString(flagListenAddr)
+		handler := createHandler(cdc)
+		logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout)).
+			With("module", "rest-server")
+		listener, err := tmserver.StartHTTPServer(listenAddr, handler, logger)
+		if err != nil {
+			return err
+		}
+
+		// Wait forever and cleanup
+		cmn.TrapSignal(func() {
+			err := listener.Close()
+			logger.Error("Error closing listener", "err", err)
+		})
+		return nil
+	}
+}
+
+// createHandler registers the SDK's generic routes and clearchain's own;
+// bank and ibc routes are left out as the app doesn't handle their messages.
+func createHandler(cdc *wire.Codec) http.Handler {
+	r := mux.NewRouter()
+	r.HandleFunc("/version", version.VersionRequestHandler).Methods("GET")
+	keys.RegisterRoutes(r)
+	rpc.RegisterRoutes(r)
+	tx.RegisterRoutes(r, cdc)
+	r.HandleFunc("/accounts/{address}", auth.QueryAccountRequestHandler(types.AccountsStoreName, cdc, types.GetAccountDecoder(cdc))).Methods("GET")
+	RegisterRoutes(r)
+	return r
+}
+
+// RegisterRoutes exposes clearchain's ABCI query paths over REST.
+func RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/clearchain/account/{address}", queryRequestHandler(func(vars map[string]string) string {
+		return types.AccountQueryPath + vars["address"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/balance/{address}/{denom}", queryRequestHandler(func(vars map[string]string) string {
+		return types.BalanceQueryPath + vars["address"] + "/" + vars["denom"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/entity/{name}", queryRequestHandler(func(vars map[string]string) string {
+		return types.EntityQueryPath + vars["name"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/entities", queryRequestHandler(func(map[string]string) string {
+		return types.EntitiesQueryPath
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/entities/{id}", queryRequestHandler(func(vars map[string]string) string {
+		return types.RegisteredEntityQueryPath + vars["id"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/currencies", queryRequestHandler(func(map[string]string) string {
+		return types.CurrenciesQueryPath
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/transfers/{id}", queryRequestHandler(func(vars map[string]string) string {
+		return types.TransferQueryPath + vars["id"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/references/{sender}/{reference}", queryRequestHandler(func(vars map[string]string) string {
+		return types.ReferenceQueryPath + vars["sender"] + "/" + vars["reference"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/history/{address}", queryRequestHandler(func(vars map[string]string) string {
+		return types.HistoryQueryPath + vars["address"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/history/{address}/{page}/{limit}", queryRequestHandler(func(vars map[string]string) string {
+		return types.HistoryQueryPath + vars["address"] + "/" + vars["page"] + "/" + vars["limit"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/journal/{id}", queryRequestHandler(func(vars map[string]string) string {
+		return types.JournalQueryPath + vars["id"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/journal/balance/{address}", queryRequestHandler(func(vars map[string]string) string {
+		return types.JournalBalanceQueryPath + vars["address"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/netting/{cycle}", queryRequestHandler(func(vars map[string]string) string {
+		return types.NettingQueryPath + vars["cycle"]
+	})).Methods("GET")
+	r.HandleFunc("/clearchain/invariants", queryRequestHandler(func(map[string]string) string {
+		return types.InvariantsQueryPath
+	})).Methods("GET")
+}
+
+// queryRequestHandler forwards a request to the ABCI query path
+// built from the request's route variables and writes back its JSON.
+func queryRequestHandler(path func(vars map[string]string) string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := queryPath(path(mux.Vars(r)))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(fmt.Sprintf("Couldn't query clearchain. Error: %s", err.Error())))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(res)
+	}
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetNetSettleTxCmd returns a netSettleTxCmd.
+func GetNetSettleTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "net-settle",
+		Short: "Create and sign a NetSettlementTx",
+		RunE:  cmdr.netSettleTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().String(flagSender, "", "Clearing house asset account's address")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+	return cmd
+}
+
+func (c Commander) netSettleTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	operator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := buildNetSettlementMsg(operator)
+	if err != nil {
+		return err
+	}
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+
+}
+
+func buildNetSettlementMsg(operator sdk.Address) (sdk.Msg, error) {
+	sender, err := sdk.GetAddress(viper.GetString(flagSender))
+	if err != nil {
+		return nil, err
+	}
+	msg := types.NewNetSettlementMsg(operator, sender)
+	return msg, nil
+}
@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetNettingResultCmd returns a command that queries the result
+// of a closed netting cycle.
+func GetNettingResultCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "netting-result <cycle>",
+		Short: "Query the result of a netting cycle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printQueryResult(types.NettingQueryPath+args[0], &types.NettingResult{})
+		},
+	}
+}
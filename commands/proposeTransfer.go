@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetProposeTransferTxCmd returns a proposeTransferTxCmd.
+func GetProposeTransferTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "propose-transfer",
+		Short: "Create and sign a ProposeTransferTx",
+		RunE:  cmdr.proposeTransferTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().String(flagTransferType, types.DepositType,
+		fmt.Sprintf("Transfer type (%s|%s|%s)", types.DepositType, types.SettlementType, types.WithdrawType))
+	addTransferFlags(cmd)
+	return cmd
+}
+
+func (c Commander) proposeTransferTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	operator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := buildProposeTransferMsg(operator)
+	if err != nil {
+		return err
+	}
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	fmt.Fprintf(os.Stderr, "Pending transfer ID: %s\n", res.DeliverTx.Data)
+	return nil
+}
+
+func buildProposeTransferMsg(operator sdk.Address) (sdk.Msg, error) {
+	targs, err := parseTransferFlags()
+	if err != nil {
+		return nil, err
+	}
+	msg := types.NewProposeTransferMsg(operator, viper.GetString(flagTransferType),
+		targs.sender, targs.recipient, targs.amount)
+	msg.Reference, msg.Memo = targs.reference, targs.memo
+	return msg, nil
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/viper"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+// queryPath runs an ABCI query against one of clearchain's own query paths.
+func queryPath(path string) ([]byte, error) {
+	node, err := client.GetNode()
+	if err != nil {
+		return nil, err
+	}
+	opts := rpcclient.ABCIQueryOptions{
+		Height:  viper.GetInt64(client.FlagHeight),
+		Trusted: viper.GetBool(client.FlagTrustNode),
+	}
+	result, err := node.ABCIQueryWithOptions(path, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp := result.Response
+	if resp.Code != uint32(0) {
+		return nil, fmt.Errorf("Query failed: (%d) %s", resp.Code, resp.Log)
+	}
+	return resp.Value, nil
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetRegisterEntityTxCmd returns a registerEntityTxCmd.
+func GetRegisterEntityTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "register-entity",
+		Short: "Create and sign a RegisterEntityTx",
+		RunE:  cmdr.registerEntityTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().String(flagEntityID, "", "New entity's unique ID")
+	cmd.Flags().String(flagEntityName, "", "New entity's unique name")
+	cmd.Flags().String(flagEntityType, "", "New entity's type (ch|gcm|icm|custodian)")
+	cmd.Flags().String(flagLEI, "", "New entity's legal entity identifier (optional)")
+	cmd.Flags().String(flagCountry, "", "New entity's ISO 3166-1 alpha-2 country code (optional)")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+	return cmd
+}
+
+func (c Commander) registerEntityTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	admin, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg := types.NewRegisterEntityMsg(admin, viper.GetString(flagEntityID), viper.GetString(flagEntityName),
+		viper.GetString(flagEntityType), viper.GetString(flagLEI), viper.GetString(flagCountry))
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
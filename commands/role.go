@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+var roles = []string{
+	types.RoleDepositOperator,
+	types.RoleSettlementOperator,
+	types.RoleWithdrawalOperator,
+	types.RoleUserAdmin,
+	types.RoleAuditor,
+}
+
+// GetGrantRoleTxCmd returns a grantRoleTxCmd.
+func GetGrantRoleTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "grant-role",
+		Short: "Create and sign a GrantRoleTx",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdr.roleTxCmd(args[0], func(admin, target sdk.Address, role string) sdk.Msg {
+				return types.NewGrantRoleMsg(admin, target, role)
+			})
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	addRoleFlags(cmd)
+	return cmd
+}
+
+// GetRevokeRoleTxCmd returns a revokeRoleTxCmd.
+func GetRevokeRoleTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "revoke-role",
+		Short: "Create and sign a RevokeRoleTx",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmdr.roleTxCmd(args[0], func(admin, target sdk.Address, role string) sdk.Msg {
+				return types.NewRevokeRoleMsg(admin, target, role)
+			})
+		},
+		Args: cobra.ExactArgs(1),
+	}
+	addRoleFlags(cmd)
+	return cmd
+}
+
+func addRoleFlags(cmd *cobra.Command) {
+	cmd.Flags().String(flagTarget, "", "User's address")
+	cmd.Flags().String(flagRole, "", fmt.Sprintf("Role, one of: %s", strings.Join(roles, ", ")))
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+}
+
+func (c Commander) roleTxCmd(name string, newMsg func(sdk.Address, sdk.Address, string) sdk.Msg) error {
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	// parse target account address
+	target, err := sdk.GetAddress(viper.GetString(flagTarget))
+	if err != nil {
+		return err
+	}
+	admin, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg := newMsg(admin, target, viper.GetString(flagRole))
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
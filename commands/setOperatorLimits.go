@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetSetOperatorLimitsTxCmd returns a setOperatorLimitsTxCmd.
+func GetSetOperatorLimitsTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "set-operator-limits",
+		Short: "Create and sign a SetOperatorLimitsTx",
+		RunE:  cmdr.setOperatorLimitsTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().String(flagTarget, "", "Operator's address")
+	cmd.Flags().String(flagMaxAmount, "", "Maximum amount of a single transfer with denom, e.g. 1000USD")
+	cmd.Flags().String(flagDailyLimit, "", "Maximum total amount in a day with denom, e.g. 10000USD")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+	return cmd
+}
+
+func (c Commander) setOperatorLimitsTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	admin, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := buildSetOperatorLimitsMsg(admin)
+	if err != nil {
+		return err
+	}
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+}
+
+func buildSetOperatorLimitsMsg(admin sdk.Address) (sdk.Msg, error) {
+	// parse target account address
+	target, err := sdk.GetAddress(viper.GetString(flagTarget))
+	if err != nil {
+		return nil, err
+	}
+	maxAmount, err := sdk.ParseCoin(viper.GetString(flagMaxAmount))
+	if err != nil {
+		return nil, err
+	}
+	dailyLimit, err := sdk.ParseCoin(viper.GetString(flagDailyLimit))
+	if err != nil {
+		return nil, err
+	}
+	msg := types.NewSetOperatorLimitsMsg(admin, target, maxAmount, dailyLimit)
+	return msg, nil
+}
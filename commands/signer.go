@@ -0,0 +1,17 @@
+package commands
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/viper"
+	"github.com/tendermint/go-crypto/keys"
+)
+
+// signerAddress returns the address of the account the key signs for.
+// Accounts keep their address when their key is rotated, so the address
+// can be given with the from-address flag; it defaults to the key's address.
+func signerAddress(info keys.Info) (sdk.Address, error) {
+	if addr := viper.GetString(flagFromAddress); addr != "" {
+		return sdk.GetAddress(addr)
+	}
+	return info.PubKey.Address(), nil
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cosmos/cosmos-sdk/client/builder"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	wire "github.com/cosmos/cosmos-sdk/wire"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetSubmitObligationTxCmd returns a submitObligationTxCmd.
+func GetSubmitObligationTxCmd(cdc *wire.Codec) *cobra.Command {
+	cmdr := Commander{Cdc: cdc}
+	cmd := &cobra.Command{
+		Use:   "submit-obligation",
+		Short: "Create and sign a SubmitObligationTx",
+		RunE:  cmdr.submitObligationTxCmd,
+		Args:  cobra.ExactArgs(1),
+	}
+	cmd.Flags().String(flagDebtor, "", "Debtor asset account's address")
+	cmd.Flags().String(flagCreditor, "", "Creditor asset account's address")
+	cmd.Flags().String(flagAmount, "", "Amount with denom, e.g. 1000USD")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+	return cmd
+}
+
+func (c Commander) submitObligationTxCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	keybase, err := keys.GetKeyBase()
+	if err != nil {
+		return err
+	}
+	info, err := keybase.Get(name)
+	if err != nil {
+		return err
+	}
+	operator, err := signerAddress(info)
+	if err != nil {
+		return err
+	}
+	msg, err := buildSubmitObligationMsg(operator)
+	if err != nil {
+		return err
+	}
+
+	res, err := builder.SignBuildBroadcast(name, msg, c.Cdc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Committed at block %d. Hash: %s\n", res.Height, res.Hash.String())
+	return nil
+
+}
+
+func buildSubmitObligationMsg(operator sdk.Address) (sdk.Msg, error) {
+	debtor, err := sdk.GetAddress(viper.GetString(flagDebtor))
+	if err != nil {
+		return nil, err
+	}
+	creditor, err := sdk.GetAddress(viper.GetString(flagCreditor))
+	if err != nil {
+		return nil, err
+	}
+	amount, err := sdk.ParseCoin(viper.GetString(flagAmount))
+	if err != nil {
+		return nil, err
+	}
+	msg := types.NewSubmitObligationMsg(operator, debtor, creditor, amount)
+	return msg, nil
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// transferArgs holds the parsed arguments shared by
+// deposit, settlement and withdraw commands.
+type transferArgs struct {
+	sender    sdk.Address
+	recipient sdk.Address
+	amount    sdk.Coin
+	reference string
+	memo      string
+}
+
+func addTransferFlags(cmd *cobra.Command) {
+	cmd.Flags().String(flagSender, "", "Sender asset account's address")
+	cmd.Flags().String(flagRecipient, "", "Recipient asset account's address")
+	cmd.Flags().String(flagAmount, "", "Amount with denom, e.g. 1000USD")
+	cmd.Flags().String(flagReference, "", "Optional external reference, unique per sender")
+	cmd.Flags().String(flagMemo, "", "Optional free-text memo")
+	cmd.Flags().Int64(flagSequence, 0, "Sequence number")
+	cmd.Flags().String(flagFromAddress, "", "Address of the signing account, defaults to the key's address")
+}
+
+func parseTransferFlags() (args transferArgs, err error) {
+	if args.sender, err = sdk.GetAddress(viper.GetString(flagSender)); err != nil {
+		return
+	}
+	if args.recipient, err = sdk.GetAddress(viper.GetString(flagRecipient)); err != nil {
+		return
+	}
+	args.reference = viper.GetString(flagReference)
+	args.memo = viper.GetString(flagMemo)
+	args.amount, err = parseAmount(viper.GetString(flagAmount))
+	return
+}
+
+// parseAmount parses a coin expression, allowing negative amounts.
+func parseAmount(s string) (sdk.Coin, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	coin, err := sdk.ParseCoin(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return sdk.Coin{}, err
+	}
+	if negative {
+		coin.Amount = -coin.Amount
+	}
+	return coin, nil
+}
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/types"
+)
+
+// GetTransfersCmd returns the proposed transfers query commands.
+func GetTransfersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transfers",
+		Short: "Query proposed and referenced transfers",
+	}
+	cmd.AddCommand(client.GetCommands(
+		&cobra.Command{
+			Use:   "pending",
+			Short: "List the transfers waiting for approval",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.PendingTransfersQueryPath, &[]types.PendingTransfer{})
+			},
+		},
+		&cobra.Command{
+			Use:   "get <id>",
+			Short: "Show a proposed transfer and its status",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.TransferQueryPath+args[0], &types.PendingTransfer{})
+			},
+		},
+		&cobra.Command{
+			Use:   "reference <sender> <reference>",
+			Short: "Show the transfer a sender executed with a reference",
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return printQueryResult(types.ReferenceQueryPath+args[0]+"/"+args[1], &types.TransferRecord{})
+			},
+		},
+	)...)
+	return cmd
+}
+
+// printQueryResult unmarshals the JSON returned by the query path into ptr
+// and prints it indented.
+func printQueryResult(path string, ptr interface{}) error {
+	bz, err := queryPath(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(bz, ptr); err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(ptr, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
@@ -2,11 +2,13 @@ package types
 
 import (
 	"bytes"
+	"fmt"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/tendermint/go-crypto"
+	oldwire "github.com/tendermint/go-wire"
 )
 
 // EntityType string identifiers
@@ -29,6 +31,17 @@ type AppAccount struct {
 	AccountType string
 	Active      bool
 	Admin       bool
+	// Closed asset accounts are permanently inactive,
+	// their address cannot be reused.
+	Closed bool
+	// Roles holds the user's roles, which grant the
+	// permissions the handlers check signers against.
+	Roles []string
+	// CreditLimits holds, per currency, how far below
+	// zero the account's balance is allowed to go.
+	CreditLimits sdk.Coins
+	// OperatorLimits restricts the amounts an operator can move.
+	OperatorLimits OperatorLimits
 }
 
 // NewAppAccount constructs a new account instance.
@@ -54,13 +67,19 @@ func newAppAccount(pub crypto.PubKey, cash sdk.Coins, creator sdk.Address, typ s
 }
 
 // NewOpUser constructs a new account instance, setting cash to nil.
+// Operators are assigned the default operator roles.
 func NewOpUser(pub crypto.PubKey, creator sdk.Address, entityName, entityType string) *AppAccount {
-	return newAppAccount(pub, nil, creator, AccountUser, true, false, entityName, entityType)
+	acc := newAppAccount(pub, nil, creator, AccountUser, true, false, entityName, entityType)
+	acc.Roles = DefaultOperatorRoles()
+	return acc
 }
 
 // NewAdminUser constructs a new account instance, setting cash to nil.
+// Admins are assigned the default admin roles.
 func NewAdminUser(pub crypto.PubKey, creator sdk.Address, entityName, entityType string) *AppAccount {
-	return newAppAccount(pub, nil, creator, AccountUser, true, true, entityName, entityType)
+	acc := newAppAccount(pub, nil, creator, AccountUser, true, true, entityName, entityType)
+	acc.Roles = DefaultAdminRoles()
+	return acc
 }
 
 // NewAssetAccount constructs a new account instance.
@@ -78,6 +97,11 @@ func (a AppAccount) IsActive() bool {
 	return a.Active
 }
 
+// IsClosed returns true if the account is closed; false otherwise.
+func (a AppAccount) IsClosed() bool {
+	return a.Closed
+}
+
 // IsAdmin returns true if the account is admin; false otherwise.
 func (a AppAccount) IsAdmin() bool {
 	return a.Admin
@@ -93,9 +117,21 @@ func (a AppAccount) IsAsset() bool {
 	return a.GetAccountType() == AccountAsset
 }
 
-// NewAccountMapper creates an account mapper given a storekey
+// GetCreditLimit returns the account's credit limit for the given denom.
+func (a AppAccount) GetCreditLimit(denom string) int64 {
+	return a.CreditLimits.AmountOf(denom)
+}
+
+// SetCreditLimit replaces the account's credit limit for the limit's denom.
+// A zero amount removes the credit limit.
+func (a *AppAccount) SetCreditLimit(limit sdk.Coin) {
+	a.CreditLimits = replaceAmountOf(a.CreditLimits, limit)
+}
+
+// NewAccountMapper creates an account mapper given a storekey,
+// the accounts and their index by legal entity share the store.
 func NewAccountMapper(capKey sdk.StoreKey) sdk.AccountMapper {
-	return auth.NewAccountMapperSealed(capKey, &AppAccount{})
+	return NewIndexedAccountMapper(capKey, NewEntityMapper(capKey))
 }
 
 // Get the AccountDecoder function for the custom AppAccount
@@ -107,21 +143,104 @@ func GetAccountDecoder(cdc *wire.Codec) sdk.AccountDecoder {
 		acct := new(AppAccount)
 		err = cdc.UnmarshalBinary(accBytes, &acct)
 		if err != nil {
-			panic(err)
+			return decodeAppAccount(accBytes)
 		}
 		return acct, err
 	}
 }
 
+// legacyAppAccount is the layout of the accounts stored before
+// AppAccount gained the Closed, Roles, CreditLimits and OperatorLimits
+// fields: go-wire cannot decode them as AppAccount.
+type legacyAppAccount struct {
+	auth.BaseAccount
+	BaseLegalEntity
+	Creator     sdk.Address
+	AccountType string
+	Active      bool
+	Admin       bool
+}
+
+// legacyAccount wraps legacy accounts like sdk.Account wraps
+// AppAccount, they were stored with the same type byte.
+type legacyAccount interface{}
+
+var _ = oldwire.RegisterInterface(
+	struct{ Account legacyAccount }{},
+	oldwire.ConcreteType{&legacyAppAccount{}, typeAppAccount},
+)
+
+// migrate returns the account in the current layout. Users are
+// assigned the default roles of their kind, as if they were created
+// with roles, otherwise they would hold no permission.
+func (la legacyAppAccount) migrate() *AppAccount {
+	acc := &AppAccount{
+		BaseAccount:     la.BaseAccount,
+		BaseLegalEntity: la.BaseLegalEntity,
+		Creator:         la.Creator,
+		AccountType:     la.AccountType,
+		Active:          la.Active,
+		Admin:           la.Admin,
+	}
+	switch {
+	case acc.IsUser() && acc.IsAdmin():
+		acc.Roles = DefaultAdminRoles()
+	case acc.IsUser():
+		acc.Roles = DefaultOperatorRoles()
+	}
+	return acc
+}
+
+// decodeAppAccount decodes an account like the account mapper does,
+// legacy accounts are migrated to the current layout.
+func decodeAppAccount(bz []byte) (*AppAccount, error) {
+	n, err := new(int), new(error)
+	decoded := oldwire.ReadBinary(struct{ sdk.Account }{}, bytes.NewBuffer(bz), len(bz), n, err)
+	if *err == nil {
+		acc, ok := decoded.(struct{ sdk.Account }).Account.(*AppAccount)
+		if !ok {
+			return nil, fmt.Errorf("%T is not an AppAccount", decoded.(struct{ sdk.Account }).Account)
+		}
+		return acc, nil
+	}
+	n, legacyErr := new(int), new(error)
+	decoded = oldwire.ReadBinary(struct{ Account legacyAccount }{}, bytes.NewBuffer(bz), len(bz), n, legacyErr)
+	if *legacyErr != nil || *n != len(bz) {
+		return nil, *err
+	}
+	legacy, ok := decoded.(struct{ Account legacyAccount }).Account.(*legacyAppAccount)
+	if !ok {
+		return nil, *err
+	}
+	return legacy.migrate(), nil
+}
+
 /* auxiliary functions */
 
 func accountEqual(a1, a2 *AppAccount) bool {
 	return ((a1.AccountType == a2.AccountType) &&
 		(a1.Admin == a2.Admin) &&
 		(a1.Active == a2.Active) &&
+		(a1.Closed == a2.Closed) &&
 		bytes.Equal(a1.Address, a2.Address) &&
 		(bytes.Equal(a1.GetPubKey().Bytes(), a2.GetPubKey().Bytes())) &&
 		BelongToSameEntity(a1, a2) &&
 		bytes.Equal(a1.Creator, a2.Creator) &&
-		a1.GetCoins().IsEqual(a2.GetCoins()))
+		a1.GetCoins().IsEqual(a2.GetCoins()) &&
+		rolesEqual(a1.Roles, a2.Roles) &&
+		a1.CreditLimits.IsEqual(a2.CreditLimits) &&
+		a1.OperatorLimits.MaxAmounts.IsEqual(a2.OperatorLimits.MaxAmounts) &&
+		a1.OperatorLimits.DailyLimits.IsEqual(a2.OperatorLimits.DailyLimits))
+}
+
+func rolesEqual(r1, r2 []string) bool {
+	if len(r1) != len(r2) {
+		return false
+	}
+	for i := range r1 {
+		if r1[i] != r2[i] {
+			return false
+		}
+	}
+	return true
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/hex"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -36,6 +37,18 @@ func TestBelongToSameEntity(t *testing.T) {
 	}
 }
 
+func TestAppAccount_SetCreditLimit(t *testing.T) {
+	acct, _ := makeAssetAccount(nil, "ent1", EntityIndividualClearingMember)
+	assert.Equal(t, int64(0), acct.GetCreditLimit("USD"))
+	acct.SetCreditLimit(sdk.Coin{"USD", 500})
+	acct.SetCreditLimit(sdk.Coin{"EUR", 100})
+	assert.Equal(t, sdk.Coins{{"EUR", 100}, {"USD", 500}}, acct.CreditLimits)
+	acct.SetCreditLimit(sdk.Coin{"USD", 200})
+	assert.Equal(t, int64(200), acct.GetCreditLimit("USD"))
+	acct.SetCreditLimit(sdk.Coin{"EUR", 0})
+	assert.Equal(t, sdk.Coins{{"USD", 200}}, acct.CreditLimits)
+}
+
 func Test_sliceContainsString(t *testing.T) {
 	stringSlice := []string{"xxx", "yyy", "zzz"}
 	type args struct {
@@ -103,3 +116,56 @@ func TestGetAccountDecoder(t *testing.T) {
 		})
 	}
 }
+
+// legacyAccounts holds a clearing house admin, operator and asset account
+// as the accounts were stored before AppAccount gained roles and limits,
+// hex encoded and indexed by address.
+var legacyAccounts = map[string]string{
+	"dd8c9efa93f268ceb8d8336f9b05c4074c9f6d71": "010114dd8c9efa93f268ceb8d8336f9b05c4074c9f6d710001de7f5ec1c84e76a930b476df44f310c585274a17af4371a23fc9cf3ce9b618f700000000000000000102434801026368000104757365720101",
+	"3a7a3ce5028b384636d76db85a22c0bbbba2e7e2": "0101143a7a3ce5028b384636d76db85a22c0bbbba2e7e20001ce721b929f7c89a7d9e6bf636663d820db2d23930c1f8e547652dffc549e79cc000000000000000001024348010263680114dd8c9efa93f268ceb8d8336f9b05c4074c9f6d710104757365720100",
+	"a7f24d6bf91b9f4170f898d7e50317bb5f86a00e": "010114a7f24d6bf91b9f4170f898d7e50317bb5f86a00e01010103555344000000000000006401939d538b43a780725a7170864158f57b605af84610a7072d88303ad004a08c94000000000000000001024348010263680114dd8c9efa93f268ceb8d8336f9b05c4074c9f6d71010561737365740100",
+}
+
+func TestIndexedAccountMapper_LegacyAccounts(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewIndexedAccountMapper(key, NewEntityMapper(key))
+	for addrHex, accHex := range legacyAccounts {
+		addr, _ := hex.DecodeString(addrHex)
+		bz, _ := hex.DecodeString(accHex)
+		ctx.KVStore(key).Set(addr, bz)
+	}
+	get := func(addrHex string) *AppAccount {
+		addr, _ := hex.DecodeString(addrHex)
+		return accts.GetAccount(ctx, addr).(*AppAccount)
+	}
+	admin := get("dd8c9efa93f268ceb8d8336f9b05c4074c9f6d71")
+	assert.True(t, admin.IsAdmin())
+	assert.Equal(t, DefaultAdminRoles(), admin.Roles)
+	assert.True(t, admin.HasPermission(PermManageUsers))
+	op := get("3a7a3ce5028b384636d76db85a22c0bbbba2e7e2")
+	assert.Equal(t, DefaultOperatorRoles(), op.Roles)
+	assert.True(t, op.HasPermission(PermSettle))
+	assert.Equal(t, admin.Address, op.Creator)
+	asset := get("a7f24d6bf91b9f4170f898d7e50317bb5f86a00e")
+	assert.Nil(t, asset.Roles)
+	assert.Equal(t, sdk.Coins{{"USD", 100}}, asset.Coins)
+	assert.Equal(t, "CH", asset.EntityName)
+
+	count := 0
+	accts.IterateAccounts(ctx, func(*AppAccount) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 3, count)
+
+	// saved again, migrated accounts are stored in the current layout
+	accts.SetAccount(ctx, op)
+	assert.NotEqual(t, legacyAccounts["3a7a3ce5028b384636d76db85a22c0bbbba2e7e2"], hex.EncodeToString(ctx.KVStore(key).Get(op.Address)))
+	assert.Equal(t, op.Roles, get("3a7a3ce5028b384636d76db85a22c0bbbba2e7e2").Roles)
+	// accounts stored in the current layout keep their roles, even none
+	op.Roles = nil
+	accts.SetAccount(ctx, op)
+	assert.False(t, get("3a7a3ce5028b384636d76db85a22c0bbbba2e7e2").HasPermission(PermSettle))
+	_, err := decodeAppAccount([]byte{0x1, 0x1, 0x14})
+	assert.NotNil(t, err)
+}
@@ -1,5 +1,12 @@
 package types
 
+import (
+	"fmt"
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
 var (
 	// Currencies contains the in-memory database of supported currencies
 	currencies map[string]ccy
@@ -197,7 +204,45 @@ func Currencies() (ccys []string) {
 	return
 }
 
+// Currency describes a supported currency.
+type Currency struct {
+	Denom         string `json:"denom"`
+	DecimalPlaces uint   `json:"decimal_places"`
+	MinimumUnit   int64  `json:"minimum_unit"`
+}
+
+// GetCurrencies returns the supported currencies sorted by denom.
+func GetCurrencies() []Currency {
+	ccys := make([]Currency, 0, len(currencies))
+	for _, c := range currencies {
+		ccys = append(ccys, Currency{Denom: c.denom, DecimalPlaces: c.decimalPlaces, MinimumUnit: c.minimumUnit})
+	}
+	sort.Slice(ccys, func(i, j int) bool { return ccys[i].Denom < ccys[j].Denom })
+	return ccys
+}
+
 func ValidateDenom(denom string) bool {
 	_, ok := currencies[denom]
 	return ok
 }
+
+// ValidateCoin ensures that the coin's denom is a supported currency
+// and that its amount is a multiple of the currency's minimum unit.
+func ValidateCoin(coin sdk.Coin) sdk.Error {
+	if err := validateCoin(coin); err != nil {
+		return ErrInvalidCurrency(err.Error())
+	}
+	return nil
+}
+
+func validateCoin(coin sdk.Coin) error {
+	c, ok := currencies[coin.Denom]
+	if !ok {
+		return fmt.Errorf("unknown denom %q", coin.Denom)
+	}
+	if coin.Amount%c.minimumUnit != 0 {
+		return fmt.Errorf("%d is not a multiple of %s minimum unit %d",
+			coin.Amount, c.denom, c.minimumUnit)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"testing"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -200,3 +201,43 @@ func TestValidateDenom(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCoin(t *testing.T) {
+	// XTS is the code reserved for testing, no supported currency has a minimum unit other than 1
+	currencies["XTS"] = ccy{decimalPlaces: 2, minimumUnit: 5, denom: "XTS"}
+	defer delete(currencies, "XTS")
+	tests := []struct {
+		name string
+		coin sdk.Coin
+		want sdk.CodeType
+	}{
+		{"empty", sdk.Coin{}, CodeInvalidCurrency},
+		{"USD", sdk.Coin{Denom: "USD", Amount: 100}, sdk.CodeOK},
+		{"negative JPY", sdk.Coin{Denom: "JPY", Amount: -7}, sdk.CodeOK},
+		{"typo", sdk.Coin{Denom: "USDD", Amount: 100}, CodeInvalidCurrency},
+		{"lowercase", sdk.Coin{Denom: "usd", Amount: 100}, CodeInvalidCurrency},
+		{"minimum unit", sdk.Coin{Denom: "XTS", Amount: 105}, sdk.CodeOK},
+		{"negative minimum unit", sdk.Coin{Denom: "XTS", Amount: -10}, sdk.CodeOK},
+		{"below minimum unit", sdk.Coin{Denom: "XTS", Amount: 3}, CodeInvalidCurrency},
+		{"not a multiple", sdk.Coin{Denom: "XTS", Amount: -107}, CodeInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateCoin(tt.coin)
+			if got == nil {
+				assert.Equal(t, tt.want, sdk.CodeOK)
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestGetCurrencies(t *testing.T) {
+	ccys := GetCurrencies()
+	assert.Equal(t, len(Currencies()), len(ccys))
+	for i := 1; i < len(ccys); i++ {
+		assert.True(t, ccys[i-1].Denom < ccys[i].Denom)
+	}
+	assert.Contains(t, ccys, Currency{Denom: "USD", DecimalPlaces: 2, MinimumUnit: 1})
+}
@@ -0,0 +1,203 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// Pending transfers' statuses.
+const (
+	TransferPending  = "pending"
+	TransferApproved = "approved"
+	TransferRejected = "rejected"
+	TransferExpired  = "expired"
+)
+
+// DefaultExpiryBlocks is the number of blocks a proposed
+// transfer waits for approval unless configured otherwise.
+const DefaultExpiryBlocks int64 = 100
+
+// DualControlParams configures the maker-checker flow.
+// Deposits, settlements and withdrawals whose absolute amount is
+// above the currency's threshold must be proposed and approved,
+// currencies without a threshold need a single signature.
+type DualControlParams struct {
+	ExpiryBlocks int64     `json:"expiry_blocks"`
+	Thresholds   sdk.Coins `json:"thresholds,omitempty"`
+}
+
+// DefaultDualControlParams returns the parameters used when
+// the genesis state does not configure dual control.
+func DefaultDualControlParams() DualControlParams {
+	return DualControlParams{ExpiryBlocks: DefaultExpiryBlocks}
+}
+
+// Validate ensures that the parameters are consistent.
+func (p DualControlParams) Validate() error {
+	if p.ExpiryBlocks <= 0 {
+		return fmt.Errorf("expiry_blocks must be positive")
+	}
+	if len(p.Thresholds) > 0 && (!p.Thresholds.IsValid() || !p.Thresholds.IsPositive()) {
+		return fmt.Errorf("thresholds %v must be positive and sorted by denom", p.Thresholds)
+	}
+	for _, coin := range p.Thresholds {
+		if err := validateCoin(coin); err != nil {
+			return fmt.Errorf("thresholds: %v", err)
+		}
+	}
+	return nil
+}
+
+// RequiresDualControl tells whether the amount is above its currency's threshold.
+func (p DualControlParams) RequiresDualControl(amount sdk.Coin) bool {
+	threshold := p.Thresholds.AmountOf(amount.Denom)
+	if threshold == 0 {
+		return false
+	}
+	if amount.Amount < 0 {
+		return -amount.Amount > threshold
+	}
+	return amount.Amount > threshold
+}
+
+// PendingTransfer defines a proposed transfer and its approval status.
+// It can be approved up to and including the ExpiresAt height.
+type PendingTransfer struct {
+	ID        int64
+	Proposal  ProposeTransferMsg
+	Height    int64
+	ExpiresAt int64
+	Status    string
+	Checker   sdk.Address
+}
+
+// DualControlMapper stores the proposed transfers,
+// its parameters are kept by the params mapper.
+type DualControlMapper struct {
+	key    sdk.StoreKey
+	cdc    *wire.Codec
+	params ParamsMapper
+}
+
+// NewDualControlMapper creates a dual control mapper given
+// a storekey and the mapper of its parameters.
+func NewDualControlMapper(key sdk.StoreKey, params ParamsMapper) DualControlMapper {
+	return DualControlMapper{
+		key:    key,
+		cdc:    wire.NewCodec(),
+		params: params,
+	}
+}
+
+// GetParams returns the dual control parameters.
+func (dm DualControlMapper) GetParams(ctx sdk.Context) DualControlParams {
+	return dm.params.GetDualControlParams(ctx)
+}
+
+// SetParams stores the dual control parameters.
+func (dm DualControlMapper) SetParams(ctx sdk.Context, params DualControlParams) {
+	dm.params.SetDualControlParams(ctx, params)
+}
+
+// ProposeTransfer stores a new pending transfer and returns its ID.
+func (dm DualControlMapper) ProposeTransfer(ctx sdk.Context, proposal ProposeTransferMsg) PendingTransfer {
+	store := ctx.KVStore(dm.key)
+	var id int64 = 1
+	if bz := store.Get(NextTransferIDKey()); bz != nil {
+		dm.mustUnmarshal(bz, &id)
+	}
+	pt := PendingTransfer{
+		ID:        id,
+		Proposal:  proposal,
+		Height:    ctx.BlockHeight(),
+		ExpiresAt: ctx.BlockHeight() + dm.GetParams(ctx).ExpiryBlocks,
+		Status:    TransferPending,
+	}
+	store.Set(NextTransferIDKey(), dm.mustMarshal(id+1))
+	store.Set(TransferKey(id), dm.mustMarshal(pt))
+	store.Set(PendingTransferKey(id), dm.mustMarshal(id))
+	return pt
+}
+
+// GetTransfer returns a proposed transfer, whatever its status.
+func (dm DualControlMapper) GetTransfer(ctx sdk.Context, id int64) (pt PendingTransfer, found bool) {
+	bz := ctx.KVStore(dm.key).Get(TransferKey(id))
+	if bz == nil {
+		return pt, false
+	}
+	dm.mustUnmarshal(bz, &pt)
+	return pt, true
+}
+
+// CloseTransfer stores a transfer that is no longer pending.
+func (dm DualControlMapper) CloseTransfer(ctx sdk.Context, pt PendingTransfer) {
+	store := ctx.KVStore(dm.key)
+	store.Set(TransferKey(pt.ID), dm.mustMarshal(pt))
+	store.Delete(PendingTransferKey(pt.ID))
+}
+
+// GetPendingTransfers returns the transfers waiting for approval, oldest first.
+func (dm DualControlMapper) GetPendingTransfers(ctx sdk.Context) []PendingTransfer {
+	prefix := []byte("dualcontrol/pending/")
+	iter := ctx.KVStore(dm.key).Iterator(prefix, prefixEndBytes(prefix))
+	var ids []int64
+	for ; iter.Valid(); iter.Next() {
+		var id int64
+		dm.mustUnmarshal(iter.Value(), &id)
+		ids = append(ids, id)
+	}
+	iter.Close()
+	transfers := []PendingTransfer{}
+	for _, id := range ids {
+		pt, _ := dm.GetTransfer(ctx, id)
+		transfers = append(transfers, pt)
+	}
+	return transfers
+}
+
+// ExpireTransfers closes the pending transfers that can no longer
+// be approved at the context's height and returns them.
+func (dm DualControlMapper) ExpireTransfers(ctx sdk.Context) []PendingTransfer {
+	var expired []PendingTransfer
+	for _, pt := range dm.GetPendingTransfers(ctx) {
+		if pt.ExpiresAt > ctx.BlockHeight() {
+			continue
+		}
+		pt.Status = TransferExpired
+		dm.CloseTransfer(ctx, pt)
+		expired = append(expired, pt)
+	}
+	return expired
+}
+
+func (dm DualControlMapper) mustMarshal(v interface{}) []byte {
+	bz, err := dm.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (dm DualControlMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := dm.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+// NextTransferIDKey stores the next proposed transfer's ID under "dualcontrol/next".
+func NextTransferIDKey() []byte {
+	return []byte("dualcontrol/next")
+}
+
+// TransferKey stores a proposed transfer under "dualcontrol/transfers/id".
+func TransferKey(id int64) []byte {
+	return []byte(fmt.Sprintf("dualcontrol/transfers/%d", id))
+}
+
+// PendingTransferKey indexes a pending transfer under "dualcontrol/pending/id",
+// the ID is zero-padded so that pending transfers are iterated in order.
+func PendingTransferKey(id int64) []byte {
+	return []byte(fmt.Sprintf("dualcontrol/pending/%020d", id))
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestDualControlParams_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  DualControlParams
+		wantErr bool
+	}{
+		{"default", DefaultDualControlParams(), false},
+		{"thresholds", DualControlParams{10, sdk.Coins{{"EUR", 100}, {"USD", 100}}}, false},
+		{"no expiry", DualControlParams{0, nil}, true},
+		{"unsorted thresholds", DualControlParams{10, sdk.Coins{{"USD", 100}, {"EUR", 100}}}, true},
+		{"negative threshold", DualControlParams{10, sdk.Coins{{"USD", -100}}}, true},
+		{"unknown currency", DualControlParams{10, sdk.Coins{{"ATM", 100}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, "%v", err)
+		})
+	}
+}
+
+func TestDualControlMapper(t *testing.T) {
+	key, ctx := fakeStore()
+	dm := NewDualControlMapper(key, NewParamsMapper(key))
+	assert.Equal(t, DefaultDualControlParams(), dm.GetParams(ctx))
+	dm.SetParams(ctx, DualControlParams{ExpiryBlocks: 5})
+	assert.Equal(t, int64(5), dm.GetParams(ctx).ExpiryBlocks)
+
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	proposal := NewProposeTransferMsg(addr, DepositType, addr2, addr3, sdk.Coin{"USD", 100})
+	first := dm.ProposeTransfer(ctx, proposal)
+	assert.Equal(t, int64(1), first.ID)
+	assert.Equal(t, ctx.BlockHeight()+5, first.ExpiresAt)
+	second := dm.ProposeTransfer(ctx.WithBlockHeight(ctx.BlockHeight()+1), proposal)
+	assert.Equal(t, int64(2), second.ID)
+	assert.Equal(t, []int64{1, 2}, transferIDs(dm.GetPendingTransfers(ctx)))
+
+	// transfers expire once their last approval height is over
+	assert.Empty(t, dm.ExpireTransfers(ctx.WithBlockHeight(first.ExpiresAt-1)))
+	expired := dm.ExpireTransfers(ctx.WithBlockHeight(first.ExpiresAt))
+	assert.Equal(t, []int64{1}, transferIDs(expired))
+	got, found := dm.GetTransfer(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, TransferExpired, got.Status)
+	assert.Equal(t, []int64{2}, transferIDs(dm.GetPendingTransfers(ctx)))
+}
+
+func transferIDs(transfers []PendingTransfer) []int64 {
+	ids := make([]int64, len(transfers))
+	for i, pt := range transfers {
+		ids[i] = pt.ID
+	}
+	return ids
+}
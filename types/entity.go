@@ -3,6 +3,7 @@ package types
 import (
 	"fmt"
 	"strings"
+	"unicode"
 )
 
 // Identifiers for legal entities types.
@@ -13,6 +14,22 @@ const (
 	EntityCustodian                = "custodian"
 )
 
+// Statuses of registered legal entities. Only the accounts of
+// active entities can move money, and only active entities can
+// be assigned new accounts.
+const (
+	EntityStatusActive    = "active"
+	EntityStatusSuspended = "suspended"
+	EntityStatusClosed    = "closed"
+)
+
+const (
+	// MaxEntityIDLength caps the length of legal entities' IDs.
+	MaxEntityIDLength = 64
+	// LEILength is the length of ISO 17442 legal entity identifiers.
+	LEILength = 20
+)
+
 // LegalEntity is the interface that wraps the basic accessor methods
 // to set and get entities attributes.
 type LegalEntity interface {
@@ -89,6 +106,120 @@ func ValidateLegalEntity(e LegalEntity) error {
 	return nil
 }
 
+// Entity is a legal entity registered by the clearing house. Accounts
+// carry its name and type, both IDs and names are unique.
+type Entity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// LEI is the entity's ISO 17442 legal entity identifier, if any.
+	LEI string `json:"lei,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code of the
+	// entity's country of incorporation, if known.
+	Country string `json:"country,omitempty"`
+	Status  string `json:"status"`
+}
+
+// NewEntity creates a new active legal entity.
+func NewEntity(id, name, typ, lei, country string) Entity {
+	return Entity{ID: id, Name: name, Type: typ, LEI: lei, Country: country, Status: EntityStatusActive}
+}
+
+// LegalEntityName returns the entity's name.
+func (e Entity) LegalEntityName() string {
+	return e.Name
+}
+
+// LegalEntityType returns the entity's type.
+func (e Entity) LegalEntityType() string {
+	return e.Type
+}
+
+// IsActive returns true if the entity can move money and be assigned new accounts; false otherwise.
+func (e Entity) IsActive() bool {
+	return e.Status == EntityStatusActive
+}
+
+// Validate ensures that the entity's ID, name, type,
+// status and metadata, if any, are well formed.
+func (e Entity) Validate() error {
+	if err := ValidateEntityID(e.ID); err != nil {
+		return err
+	}
+	if err := ValidateLegalEntity(e); err != nil {
+		return err
+	}
+	if len(e.LEI) != 0 && !validateLEI(e.LEI) {
+		return fmt.Errorf("LEI %q is invalid", e.LEI)
+	}
+	if len(e.Country) != 0 && !validateCountryCode(e.Country) {
+		return fmt.Errorf("country %q is not an ISO 3166-1 alpha-2 code", e.Country)
+	}
+	return ValidateEntityStatus(e.Status)
+}
+
+// ValidateEntityStatus ensures that the status is a known entity status.
+func ValidateEntityStatus(status string) error {
+	if !sliceContainsString([]string{
+		EntityStatusActive,
+		EntityStatusSuspended,
+		EntityStatusClosed}, status) {
+		return fmt.Errorf("entity status %q is invalid", status)
+	}
+	return nil
+}
+
+// ValidateEntityID ensures that the ID is made of up to MaxEntityIDLength
+// letters, digits, dots, dashes and underscores.
+func ValidateEntityID(id string) error {
+	if len(id) == 0 || len(id) > MaxEntityIDLength {
+		return fmt.Errorf("entity IDs must be 1 to %d characters long, got %d", MaxEntityIDLength, len(id))
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || isUpperAlphanumeric(r) || strings.ContainsRune("-_.", r)) {
+			return fmt.Errorf("invalid character %q in entity ID", r)
+		}
+	}
+	return nil
+}
+
+// validateLEI checks the LEI's format and its ISO 7064 MOD 97-10
+// check digits: read as a number, letters standing for 10 to 35,
+// a valid LEI leaves 1 when divided by 97.
+func validateLEI(lei string) bool {
+	if len(lei) != LEILength {
+		return false
+	}
+	remainder := 0
+	for i, c := range lei {
+		if !isUpperAlphanumeric(c) || (i >= LEILength-2 && !unicode.IsDigit(c)) {
+			return false
+		}
+		if unicode.IsDigit(c) {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		} else {
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return remainder == 1
+}
+
+func validateCountryCode(country string) bool {
+	if len(country) != 2 {
+		return false
+	}
+	for _, c := range country {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isUpperAlphanumeric(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
 func sliceContainsString(slice []string, target string) bool {
 	for _, s := range slice {
 		if s == target {
@@ -0,0 +1,190 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+var _ sdk.AccountMapper = IndexedAccountMapper{}
+
+// IndexedAccountMapper is an account mapper that keeps secondary
+// indexes of accounts by legal entity name and by key.
+type IndexedAccountMapper struct {
+	sdk.AccountMapper
+	key      sdk.StoreKey
+	entities EntityMapper
+}
+
+// EntityMapper stores the registry of legal entities
+// and the indexes of accounts by legal entity name and by key.
+type EntityMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+// EntityAccount summarises an account that belongs to a legal entity.
+type EntityAccount struct {
+	Address     sdk.Address `json:"address"`
+	AccountType string      `json:"account_type"`
+	EntityName  string      `json:"entity_name"`
+	EntityType  string      `json:"entity_type"`
+	Admin       bool        `json:"admin"`
+	Active      bool        `json:"active"`
+	Closed      bool        `json:"closed,omitempty"`
+}
+
+// NewIndexedAccountMapper creates an indexed account mapper given
+// the storekey of the accounts and the mapper of their index.
+func NewIndexedAccountMapper(key sdk.StoreKey, entities EntityMapper) IndexedAccountMapper {
+	return IndexedAccountMapper{
+		AccountMapper: auth.NewAccountMapperSealed(key, &AppAccount{}),
+		key:           key,
+		entities:      entities,
+	}
+}
+
+// NewEntityMapper creates an entity mapper given a storekey.
+func NewEntityMapper(key sdk.StoreKey) EntityMapper {
+	return EntityMapper{
+		key: key,
+		cdc: wire.NewCodec(),
+	}
+}
+
+// GetAccount returns the account stored at the address, or nil.
+// Accounts stored in a legacy layout are migrated to the current one.
+func (am IndexedAccountMapper) GetAccount(ctx sdk.Context, addr sdk.Address) sdk.Account {
+	bz := ctx.KVStore(am.key).Get(addr)
+	if bz == nil {
+		return nil
+	}
+	acc, err := decodeAppAccount(bz)
+	if err != nil {
+		panic(err)
+	}
+	return acc
+}
+
+// SetAccount saves the account and indexes it by its legal entity and its key.
+// Keys stay bound to the account once rotated away, so they can't be reused.
+func (am IndexedAccountMapper) SetAccount(ctx sdk.Context, acc sdk.Account) {
+	am.AccountMapper.SetAccount(ctx, acc)
+	if entity, ok := acc.(LegalEntity); ok {
+		am.entities.IndexAccount(ctx, entity.LegalEntityName(), acc.GetAddress())
+	}
+	if pub := acc.GetPubKey(); !pub.Empty() {
+		am.entities.BindKey(ctx, pub.Address(), acc.GetAddress())
+	}
+}
+
+// GetKeyAccount returns the address of the account the key is bound to.
+func (am IndexedAccountMapper) GetKeyAccount(ctx sdk.Context, keyAddr sdk.Address) (addr sdk.Address, found bool) {
+	addr = ctx.KVStore(am.entities.key).Get(KeyAccountKey(keyAddr))
+	return addr, addr != nil
+}
+
+// GetEntityAccounts returns the accounts that belong to the named legal entity.
+func (am IndexedAccountMapper) GetEntityAccounts(ctx sdk.Context, name string) []*AppAccount {
+	var accounts []*AppAccount
+	for _, addr := range am.entities.indexedAddresses(ctx, EntityAccountsKey(name)) {
+		acc, ok := am.GetAccount(ctx, addr).(*AppAccount)
+		// names sharing a prefix land in the same key range
+		if !ok || acc.LegalEntityName() != name {
+			continue
+		}
+		accounts = append(accounts, acc)
+	}
+	return accounts
+}
+
+// IterateAccounts calls process on every account, ordered by address,
+// until process returns true. It walks the accounts' store rather than
+// the index, which misses the accounts saved before it was introduced.
+// Chains that keep all their data in one store hold other entries too,
+// only the values that decode to an account stored at its address count.
+func (am IndexedAccountMapper) IterateAccounts(ctx sdk.Context, process func(*AppAccount) (stop bool)) {
+	// the store's iterator needs an upper bound: one byte longer than
+	// an address, all set, sorts after every address and index key
+	iter := ctx.KVStore(am.key).Iterator(nil, bytes.Repeat([]byte{0xff}, 21))
+	var accounts []*AppAccount
+	for ; iter.Valid(); iter.Next() {
+		acc, err := decodeAppAccount(iter.Value())
+		if err == nil && bytes.Equal(acc.Address, iter.Key()) {
+			accounts = append(accounts, acc)
+		}
+	}
+	// release the iterator before handing the accounts over
+	iter.Close()
+	for _, acc := range accounts {
+		if process(acc) {
+			return
+		}
+	}
+}
+
+// IndexAccount indexes the account's address under its legal entity's name.
+func (em EntityMapper) IndexAccount(ctx sdk.Context, name string, addr sdk.Address) {
+	ctx.KVStore(em.key).Set(EntityAccountKey(name, addr), addr)
+}
+
+// BindKey indexes the account's address under the address of its key.
+func (em EntityMapper) BindKey(ctx sdk.Context, keyAddr, addr sdk.Address) {
+	ctx.KVStore(em.key).Set(KeyAccountKey(keyAddr), addr)
+}
+
+// indexedAddresses collects the addresses indexed under prefix,
+// so that accounts are loaded once the iterator is released.
+func (em EntityMapper) indexedAddresses(ctx sdk.Context, prefix []byte) []sdk.Address {
+	iter := ctx.KVStore(em.key).Iterator(prefix, prefixEndBytes(prefix))
+	defer iter.Close()
+	var addrs []sdk.Address
+	for ; iter.Valid(); iter.Next() {
+		addrs = append(addrs, iter.Value())
+	}
+	return addrs
+}
+
+// NewEntityAccount builds the entity summary of an account.
+func NewEntityAccount(acc *AppAccount) EntityAccount {
+	return EntityAccount{
+		Address:     acc.Address,
+		AccountType: acc.AccountType,
+		EntityName:  acc.EntityName,
+		EntityType:  acc.EntityType,
+		Admin:       acc.Admin,
+		Active:      acc.Active,
+		Closed:      acc.Closed,
+	}
+}
+
+// EntityAccountsKey prefixes the index entries of a legal entity
+// under "entities/name/".
+func EntityAccountsKey(name string) []byte {
+	return []byte(fmt.Sprintf("entities/%s/", name))
+}
+
+// EntityAccountKey indexes an account under "entities/name/address".
+func EntityAccountKey(name string, addr sdk.Address) []byte {
+	return append(EntityAccountsKey(name), addr...)
+}
+
+// KeyAccountKey indexes the account a key is bound to under "keys/keyaddress".
+func KeyAccountKey(keyAddr sdk.Address) []byte {
+	return append([]byte("keys/"), keyAddr...)
+}
+
+// prefixEndBytes returns the smallest key that is
+// greater than all the keys with the given prefix.
+func prefixEndBytes(prefix []byte) []byte {
+	end := bytes.TrimRight(prefix, "\xff")
+	if len(end) == 0 {
+		return nil
+	}
+	end = append([]byte(nil), end...)
+	end[len(end)-1]++
+	return end
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestIndexedAccountMapper_GetEntityAccounts(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewIndexedAccountMapper(key, NewEntityMapper(key))
+	admin, _ := fakeAdminWithEntityName(accts, ctx, "ent", EntityCustodian)
+	_, asset := fakeAssetWithEntityName(accts, ctx, sdk.Coins{}, "ent", EntityCustodian)
+	fakeAdminWithEntityName(accts, ctx, "ent/2", EntityCustodian)
+	fakeAdminWithEntityName(accts, ctx, "other", EntityCustodian)
+
+	got := accts.GetEntityAccounts(ctx, "ent")
+	assert.Equal(t, 2, len(got))
+	addrs := []sdk.Address{got[0].Address, got[1].Address}
+	assert.Contains(t, addrs, admin.Address)
+	assert.Contains(t, addrs, asset)
+
+	// saving an account again doesn't duplicate its index entry
+	admin.Active = false
+	accts.SetAccount(ctx, admin)
+	assert.Equal(t, 2, len(accts.GetEntityAccounts(ctx, "ent")))
+	assert.Equal(t, 0, len(accts.GetEntityAccounts(ctx, "nobody")))
+}
+
+func TestIndexedAccountMapper_GetKeyAccount(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewIndexedAccountMapper(key, NewEntityMapper(key))
+	admin, _ := fakeAdminWithEntityName(accts, ctx, "ent", EntityCustodian)
+	oldKey := admin.PubKey
+	addr, found := accts.GetKeyAccount(ctx, oldKey.Address())
+	assert.True(t, found)
+	assert.Equal(t, admin.Address, addr)
+
+	// rotated keys stay bound to the account
+	admin.PubKey = crypto.GenPrivKeyEd25519().PubKey()
+	accts.SetAccount(ctx, admin)
+	for _, k := range []crypto.PubKey{oldKey, admin.PubKey} {
+		addr, found = accts.GetKeyAccount(ctx, k.Address())
+		assert.True(t, found)
+		assert.Equal(t, admin.Address, addr)
+	}
+	_, found = accts.GetKeyAccount(ctx, crypto.GenPrivKeyEd25519().PubKey().Address())
+	assert.False(t, found)
+}
+
+func Test_prefixEndBytes(t *testing.T) {
+	assert.Equal(t, []byte("entities0"), prefixEndBytes([]byte("entities/")))
+	assert.Equal(t, []byte{0x1, 0x3}, prefixEndBytes([]byte{0x1, 0x2, 0xff}))
+	assert.Nil(t, prefixEndBytes([]byte{0xff}))
+}
+
+func TestIndexedAccountMapper_IterateAccounts(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewIndexedAccountMapper(key, NewEntityMapper(key))
+	admin, _ := fakeAdminWithEntityName(accts, ctx, "ent", EntityCustodian)
+	// an account saved without the index, as before it existed
+	legacy := &AppAccount{BaseAccount: auth.BaseAccount{Address: crypto.GenPrivKeyEd25519().PubKey().Address()}}
+	auth.NewAccountMapperSealed(key, &AppAccount{}).SetAccount(ctx, legacy)
+
+	var addrs []sdk.Address
+	accts.IterateAccounts(ctx, func(acc *AppAccount) bool {
+		addrs = append(addrs, acc.Address)
+		return false
+	})
+	assert.Equal(t, 2, len(addrs))
+	assert.Contains(t, addrs, admin.Address)
+	assert.Contains(t, addrs, legacy.Address)
+
+	// process stops the iteration
+	count := 0
+	accts.IterateAccounts(ctx, func(*AppAccount) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 1, count)
+}
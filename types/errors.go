@@ -18,7 +18,17 @@ const (
 	CodeSelfCreate         sdk.CodeType = 1005
 	CodeSelfFreeze         sdk.CodeType = 1006
 	CodeInactiveAccount    sdk.CodeType = 1007
+	CodeInvalidCurrency    sdk.CodeType = 1008
+	CodeCreditLimit        sdk.CodeType = 1009
 	CodeWrongSigner        sdk.CodeType = 1010
+	CodeDualControl        sdk.CodeType = 1011
+	CodeInvalidTransfer    sdk.CodeType = 1012
+	CodeOperatorLimit      sdk.CodeType = 1013
+	CodeInvalidRole        sdk.CodeType = 1014
+	CodeInvalidReference   sdk.CodeType = 1015
+	CodeDuplicateReference sdk.CodeType = 1016
+	CodeDuplicateEntity    sdk.CodeType = 1017
+	CodeInvalidCycle       sdk.CodeType = 1018
 	CodeWrongMessageFormat sdk.CodeType = 1100
 )
 
@@ -53,6 +63,59 @@ func ErrSelfCreate(typ string) sdk.Error {
 		"why on earth are you trying to create yourself? %s", typ))
 }
 
+// ErrCreditLimitExceeded signals that a transfer would take
+// an account's balance below its credit limit.
+func ErrCreditLimitExceeded(typ string) sdk.Error {
+	return sdk.NewError(CodeCreditLimit, fmt.Sprintf("credit limit exceeded: %s", typ))
+}
+
+// ErrDualControlRequired signals that a transfer must be
+// proposed and approved by a second user.
+func ErrDualControlRequired(typ string) sdk.Error {
+	return sdk.NewError(CodeDualControl, fmt.Sprintf("dual control required: %s", typ))
+}
+
+// ErrInvalidTransfer signals that a pending transfer
+// does not exist or cannot be approved or rejected.
+func ErrInvalidTransfer(typ string) sdk.Error {
+	return sdk.NewError(CodeInvalidTransfer, fmt.Sprintf("invalid pending transfer: %s", typ))
+}
+
+// ErrOperatorLimitExceeded signals that a transfer would
+// exceed the limits set on the operator that signed it.
+func ErrOperatorLimitExceeded(typ string) sdk.Error {
+	return sdk.NewError(CodeOperatorLimit, fmt.Sprintf("operator limit exceeded: %s", typ))
+}
+
+// ErrInvalidRole signals that a role is unknown or
+// cannot be granted or revoked.
+func ErrInvalidRole(typ string) sdk.Error {
+	return sdk.NewError(CodeInvalidRole, fmt.Sprintf("invalid role: %s", typ))
+}
+
+// ErrInvalidReference signals that a transfer's reference or memo is malformed.
+func ErrInvalidReference(typ string) sdk.Error {
+	return sdk.NewError(CodeInvalidReference, fmt.Sprintf("invalid reference: %s", typ))
+}
+
+// ErrDuplicateReference signals that the sender already
+// sent a transfer with the same reference.
+func ErrDuplicateReference(typ string) sdk.Error {
+	return sdk.NewError(CodeDuplicateReference, fmt.Sprintf("duplicate reference: %s", typ))
+}
+
+// ErrDuplicateEntity signals that a legal entity's
+// ID or name is already registered.
+func ErrDuplicateEntity(typ string) sdk.Error {
+	return sdk.NewError(CodeDuplicateEntity, fmt.Sprintf("duplicate entity: %s", typ))
+}
+
+// ErrInvalidCycle signals that a netting
+// cycle is not open or has no obligations.
+func ErrInvalidCycle(typ string) sdk.Error {
+	return sdk.NewError(CodeInvalidCycle, fmt.Sprintf("invalid netting cycle: %s", typ))
+}
+
 // ErrSelfFreeze signals that an admin user attempted to freeze itself.
 func ErrSelfFreeze(typ string) sdk.Error {
 	return sdk.NewError(CodeSelfFreeze, fmt.Sprintf("self-freeze attempted: %s", typ))
@@ -63,6 +126,11 @@ func ErrInactiveUser(typ string) sdk.Error {
 	return sdk.NewError(CodeInactiveAccount, fmt.Sprintf("inactive user: %s", typ))
 }
 
+// ErrInvalidCurrency signals that an amount violated the currency's rules.
+func ErrInvalidCurrency(typ string) sdk.Error {
+	return sdk.NewError(CodeInvalidCurrency, fmt.Sprintf("invalid currency: %s", typ))
+}
+
 // ErrWrongSigner signals that a message carried invalid signatures.
 func ErrWrongSigner(typ string) sdk.Error {
 	return sdk.NewError(CodeWrongSigner, fmt.Sprintf("wrong signer: %s", typ))
@@ -1,20 +1,93 @@
 package types
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	crypto "github.com/tendermint/go-crypto"
 )
 
 // GenesisState defines the app's initial state to unmarshal.
 type GenesisState struct {
-	ClearingHouseAdmin GenesisAccount `json:"ch_admin"`
+	ClearingHouseAdmin GenesisAccount        `json:"ch_admin"`
+	Entities           []GenesisEntity       `json:"entities,omitempty"`
+	Admins             []GenesisUser         `json:"admins,omitempty"`
+	Operators          []GenesisUser         `json:"operators,omitempty"`
+	AssetAccounts      []GenesisAssetAccount `json:"asset_accounts,omitempty"`
+	DualControl        *DualControlParams    `json:"dual_control,omitempty"`
+	Invariants         *InvariantParams      `json:"invariants,omitempty"`
 }
 
-// GenesisAccount is an abstraction of the accounts specified in a genesis file
+// GenesisAccount is an abstraction of the accounts specified in a genesis file.
+// Address is only needed when the account's key was rotated, accounts
+// are otherwise found at their public key's address.
 type GenesisAccount struct {
 	PubKeyHexa string `json:"public_key"`
+	Address    string `json:"address,omitempty"`
 	EntityName string `json:"entity_name"`
+	Inactive   bool   `json:"inactive,omitempty"`
+}
+
+// GenesisEntity declares a legal entity in a genesis file, it is
+// registered at genesis. Its ID defaults to its name and its status
+// to active, the LEI and the country are optional.
+type GenesisEntity struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	LEI     string `json:"lei,omitempty"`
+	Country string `json:"country,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+// ToEntity converts a GenesisEntity into the Entity to be registered.
+func (ge GenesisEntity) ToEntity() Entity {
+	e := Entity{ID: ge.ID, Name: ge.Name, Type: ge.Type, LEI: ge.LEI, Country: ge.Country, Status: ge.Status}
+	if len(e.ID) == 0 {
+		e.ID = ge.Name
+	}
+	if len(e.Status) == 0 {
+		e.Status = EntityStatusActive
+	}
+	return e
+}
+
+// NewGenesisEntity declares a registered legal entity.
+func NewGenesisEntity(e Entity) GenesisEntity {
+	return GenesisEntity{Name: e.Name, Type: e.Type, ID: e.ID, LEI: e.LEI, Country: e.Country, Status: e.Status}
+}
+
+// GenesisUser declares an admin or an operator in a genesis file.
+// Creator is the hex address of the admin that created the user.
+// Users without roles are assigned their default roles,
+// an empty list of roles assigns none. Only operators have limits.
+type GenesisUser struct {
+	PubKeyHexa  string    `json:"public_key"`
+	Address     string    `json:"address,omitempty"`
+	EntityName  string    `json:"entity_name"`
+	Creator     string    `json:"creator"`
+	Inactive    bool      `json:"inactive,omitempty"`
+	Roles       []string  `json:"roles"`
+	MaxAmounts  sdk.Coins `json:"max_amounts,omitempty"`
+	DailyLimits sdk.Coins `json:"daily_limits,omitempty"`
+}
+
+// GenesisAssetAccount declares an asset account and
+// its opening balances in a genesis file. Closed accounts
+// are inactive and have neither balances nor credit limits.
+type GenesisAssetAccount struct {
+	PubKeyHexa   string    `json:"public_key"`
+	Address      string    `json:"address,omitempty"`
+	EntityName   string    `json:"entity_name"`
+	Creator      string    `json:"creator"`
+	Inactive     bool      `json:"inactive,omitempty"`
+	Closed       bool      `json:"closed,omitempty"`
+	Coins        sdk.Coins `json:"coins,omitempty"`
+	CreditLimits sdk.Coins `json:"credit_limits,omitempty"`
 }
 
 // ToClearingHouseAdmin converts  a GenesisAccount into an AppAccount (a Clearing House admin user)
@@ -30,9 +103,424 @@ func (ga *GenesisAccount) ToClearingHouseAdmin() (acc *AppAccount, err error) {
 	}
 
 	adminUser := NewAdminUser(publicKey, nil, ga.EntityName, EntityClearingHouse)
+	adminUser.Active = !ga.Inactive
+	if ga.Address != "" {
+		if adminUser.Address, err = sdk.GetAddress(ga.Address); err != nil {
+			return nil, err
+		}
+	}
 	return adminUser, nil
 }
 
+// NewGenesisState builds the genesis state that recreates the given registered
+// legal entities and accounts; the entities of the accounts are declared even
+// if they are not registered. The clearing house admin is the only account
+// with no creator.
+func NewGenesisState(registered []Entity, accounts []*AppAccount) (GenesisState, error) {
+	var gs GenesisState
+	entities := make(map[string]string)
+	for _, e := range registered {
+		entities[e.Name] = e.Type
+		gs.Entities = append(gs.Entities, NewGenesisEntity(e))
+	}
+	for _, acc := range accounts {
+		if typ, ok := entities[acc.EntityName]; ok && typ != acc.EntityType {
+			return GenesisState{}, fmt.Errorf("entity %q has accounts of types %q and %q", acc.EntityName, typ, acc.EntityType)
+		}
+		if _, ok := entities[acc.EntityName]; !ok {
+			entities[acc.EntityName] = acc.EntityType
+			gs.Entities = append(gs.Entities, GenesisEntity{Name: acc.EntityName, Type: acc.EntityType})
+		}
+		pubHex := hex.EncodeToString(acc.PubKey.Bytes())
+		creatorHex := hex.EncodeToString(acc.Creator)
+		// rotated keys no longer match their account's address
+		var addrHex string
+		if !bytes.Equal(acc.Address, acc.PubKey.Address()) {
+			addrHex = hex.EncodeToString(acc.Address)
+		}
+		switch {
+		case len(acc.Creator) == 0:
+			if !acc.IsAdmin() || !IsClearingHouse(acc) || (gs.ClearingHouseAdmin != GenesisAccount{}) {
+				return GenesisState{}, fmt.Errorf("account %v has no creator", acc.Address)
+			}
+			gs.ClearingHouseAdmin = GenesisAccount{PubKeyHexa: pubHex, Address: addrHex, EntityName: acc.EntityName, Inactive: !acc.Active}
+		case acc.IsAsset():
+			gs.AssetAccounts = append(gs.AssetAccounts, GenesisAssetAccount{
+				PubKeyHexa:   pubHex,
+				Address:      addrHex,
+				EntityName:   acc.EntityName,
+				Creator:      creatorHex,
+				Inactive:     !acc.Active,
+				Closed:       acc.Closed,
+				Coins:        acc.Coins,
+				CreditLimits: acc.CreditLimits,
+			})
+		case acc.IsAdmin():
+			gs.Admins = append(gs.Admins, GenesisUser{
+				PubKeyHexa: pubHex,
+				Address:    addrHex,
+				EntityName: acc.EntityName,
+				Creator:    creatorHex,
+				Inactive:   !acc.Active,
+				Roles:      append([]string{}, acc.Roles...),
+			})
+		default:
+			gs.Operators = append(gs.Operators, GenesisUser{
+				PubKeyHexa:  pubHex,
+				Address:     addrHex,
+				EntityName:  acc.EntityName,
+				Creator:     creatorHex,
+				Inactive:    !acc.Active,
+				Roles:       append([]string{}, acc.Roles...),
+				MaxAmounts:  acc.OperatorLimits.MaxAmounts,
+				DailyLimits: acc.OperatorLimits.DailyLimits,
+			})
+		}
+	}
+	return gs, nil
+}
+
+// GenesisErrors lists all the problems found in a genesis state.
+type GenesisErrors []error
+
+// Error implements the error interface, one problem per line.
+func (errs GenesisErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseGenesisState unmarshals and validates a genesis state.
+func ParseGenesisState(bz []byte) (gs GenesisState, err error) {
+	if err = json.Unmarshal(bz, &gs); err != nil {
+		return gs, GenesisErrors{fmt.Errorf("malformed genesis state: %v", err)}
+	}
+	return gs, gs.Validate()
+}
+
+// Validate checks the genesis state against the rules the handlers enforce,
+// it returns GenesisErrors listing all the problems found, if any.
+func (gs GenesisState) Validate() error {
+	var errs GenesisErrors
+	if _, err := gs.ToAppAccounts(); err != nil {
+		errs = append(errs, err.(GenesisErrors)...)
+	}
+	if gs.DualControl != nil {
+		if err := gs.DualControl.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("dual_control: %v", err))
+		}
+	}
+	if gs.Invariants != nil {
+		if err := gs.Invariants.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invariants: %v", err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ToEntities returns the legal entities to be registered at genesis, the
+// clearing house admin's entity is registered under its name if undeclared.
+// The genesis state must be valid.
+func (gs GenesisState) ToEntities() []Entity {
+	var entities []Entity
+	chDeclared := false
+	for _, ge := range gs.Entities {
+		entities = append(entities, ge.ToEntity())
+		chDeclared = chDeclared || ge.Name == gs.ClearingHouseAdmin.EntityName
+	}
+	if (gs.ClearingHouseAdmin != GenesisAccount{}) && !chDeclared {
+		name := gs.ClearingHouseAdmin.EntityName
+		entities = append(entities, NewEntity(name, name, EntityClearingHouse, "", ""))
+	}
+	return entities
+}
+
+// ToAppAccounts validates the genesis state and converts it
+// into the accounts to be stored at genesis; it returns
+// GenesisErrors listing all the problems found, if any.
+// Creators must be declared in the genesis state too, though they
+// need not be active as they may have been frozen after the fact.
+func (gs GenesisState) ToAppAccounts() ([]*AppAccount, error) {
+	var errs GenesisErrors
+	failer := func(prefix string) func(string, ...interface{}) {
+		return func(format string, args ...interface{}) {
+			errs = append(errs, fmt.Errorf(prefix+": "+format, args...))
+		}
+	}
+	entities := gs.entityTypes(failer)
+	var accounts []*AppAccount
+	if (gs.ClearingHouseAdmin != GenesisAccount{}) {
+		acc, err := gs.ClearingHouseAdmin.ToClearingHouseAdmin()
+		if err != nil {
+			failer("ch_admin")("public key: %v", err)
+		} else {
+			accounts = append(accounts, acc)
+		}
+	}
+	var newAdmins []*AppAccount
+	for i, gu := range gs.Admins {
+		fail := failer(fmt.Sprintf("admins[%d]", i))
+		if acc := gu.toAppAccount(entities, NewAdminUser, fail); acc != nil {
+			newAdmins = append(newAdmins, acc)
+		}
+	}
+	accounts = append(accounts, newAdmins...)
+	// admins may be created by admins declared after them
+	admins := make(map[string]*AppAccount)
+	for _, acc := range accounts {
+		admins[string(acc.Address)] = acc
+	}
+	for _, acc := range newAdmins {
+		checkGenesisCreator(admins, acc, failer(fmt.Sprintf("admin %v", acc.Address)))
+	}
+	for i, gu := range gs.Operators {
+		fail := failer(fmt.Sprintf("operators[%d]", i))
+		if acc := gu.toAppAccount(entities, NewOpUser, fail); acc != nil {
+			checkGenesisCreator(admins, acc, fail)
+			accounts = append(accounts, acc)
+		}
+	}
+	for i, ga := range gs.AssetAccounts {
+		fail := failer(fmt.Sprintf("asset_accounts[%d]", i))
+		if acc := ga.toAppAccount(entities, fail); acc != nil {
+			checkGenesisCreator(admins, acc, fail)
+			accounts = append(accounts, acc)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, acc := range accounts {
+		if seen[string(acc.Address)] {
+			failer(fmt.Sprintf("account %v", acc.Address))("declared more than once")
+		}
+		seen[string(acc.Address)] = true
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return accounts, nil
+}
+
+// entityTypes maps the declared legal entities' names to their types.
+// The clearing house admin's entity needs no declaration.
+func (gs GenesisState) entityTypes(failer func(string) func(string, ...interface{})) map[string]string {
+	entities := make(map[string]string)
+	ids := make(map[string]bool)
+	for i, ge := range gs.Entities {
+		fail := failer(fmt.Sprintf("entities[%d]", i))
+		e := ge.ToEntity()
+		if err := e.Validate(); err != nil {
+			fail("%v", err)
+			continue
+		}
+		if _, ok := entities[e.Name]; ok {
+			fail("entity %q is declared more than once", e.Name)
+			continue
+		}
+		if ids[e.ID] {
+			fail("entity ID %q is declared more than once", e.ID)
+			continue
+		}
+		entities[e.Name] = e.Type
+		ids[e.ID] = true
+	}
+	if (gs.ClearingHouseAdmin != GenesisAccount{}) {
+		name := gs.ClearingHouseAdmin.EntityName
+		typ, declared := entities[name]
+		switch {
+		case declared && typ != EntityClearingHouse:
+			failer("ch_admin")("entity %q is not a clearing house", name)
+		case !declared && ids[name]:
+			failer("ch_admin")("entity %q is undeclared and its name is another entity's ID", name)
+		case !declared:
+			if err := NewEntity(name, name, EntityClearingHouse, "", "").Validate(); err != nil {
+				failer("ch_admin")("entity %q must be declared with an ID: %v", name, err)
+			}
+		}
+		entities[name] = EntityClearingHouse
+	}
+	return entities
+}
+
+// toAppAccount reports the user's problems to fail
+// and returns nil if there are any.
+func (gu GenesisUser) toAppAccount(entities map[string]string,
+	newUser func(crypto.PubKey, sdk.Address, string, string) *AppAccount,
+	fail func(string, ...interface{})) *AppAccount {
+	pub, creator, typ, ok := parseGenesisAccount(entities, gu.PubKeyHexa, gu.Creator, gu.EntityName, fail)
+	addr, addrOk := parseGenesisAddress(gu.Address, fail)
+	ok = addrOk && ok
+	ok = validateGenesisLimits("max_amounts", gu.MaxAmounts, fail) && ok
+	ok = validateGenesisLimits("daily_limits", gu.DailyLimits, fail) && ok
+	for _, role := range gu.Roles {
+		if err := ValidateRole(role); err != nil {
+			fail("roles: %v", err)
+			ok = false
+		}
+	}
+	if !ok {
+		return nil
+	}
+	acc := newUser(pub, creator, gu.EntityName, typ)
+	acc.Active = !gu.Inactive
+	if addr != nil {
+		acc.Address = addr
+	}
+	if gu.Roles != nil {
+		acc.Roles = nil
+		for _, role := range gu.Roles {
+			acc.GrantRole(role)
+		}
+	}
+	if len(gu.MaxAmounts) > 0 || len(gu.DailyLimits) > 0 {
+		if acc.IsAdmin() {
+			fail("only operators have limits")
+			return nil
+		}
+		acc.OperatorLimits.MaxAmounts = gu.MaxAmounts
+		acc.OperatorLimits.DailyLimits = gu.DailyLimits
+	}
+	return acc
+}
+
+// validateGenesisLimits reports invalid limits to fail.
+func validateGenesisLimits(field string, limits sdk.Coins, fail func(string, ...interface{})) bool {
+	if len(limits) == 0 {
+		return true
+	}
+	if !limits.IsValid() || !limits.IsPositive() {
+		fail("%s %v must be positive and sorted by denom", field, limits)
+		return false
+	}
+	ok := true
+	for _, limit := range limits {
+		if err := validateCoin(limit); err != nil {
+			fail("%s: %v", field, err)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// toAppAccount reports the asset account's problems
+// to fail and returns nil if there are any.
+func (ga GenesisAssetAccount) toAppAccount(entities map[string]string,
+	fail func(string, ...interface{})) *AppAccount {
+	pub, creator, typ, ok := parseGenesisAccount(entities, ga.PubKeyHexa, ga.Creator, ga.EntityName, fail)
+	addr, addrOk := parseGenesisAddress(ga.Address, fail)
+	ok = addrOk && ok
+	if len(ga.Coins) > 0 && !ga.Coins.IsValid() {
+		fail("coins %v must be sorted by denom and non-zero", ga.Coins)
+		ok = false
+	}
+	for _, coin := range ga.Coins {
+		if err := validateCoin(coin); err != nil {
+			fail("coins: %v", err)
+			ok = false
+		}
+	}
+	for _, limit := range ga.CreditLimits {
+		if limit.Amount < 0 {
+			fail("negative credit limit %v", limit)
+			ok = false
+		}
+		if err := validateCoin(limit); err != nil {
+			fail("credit_limits: %v", err)
+			ok = false
+		}
+	}
+	if ga.Closed && (!ga.Inactive || len(ga.Coins) > 0 || len(ga.CreditLimits) > 0) {
+		fail("closed accounts must be inactive with no coins nor credit limits")
+		ok = false
+	}
+	if !ok {
+		return nil
+	}
+	acc := NewAssetAccount(pub, ga.Coins, creator, ga.EntityName, typ)
+	acc.Active = !ga.Inactive
+	acc.Closed = ga.Closed
+	if addr != nil {
+		acc.Address = addr
+	}
+	if !IsMember(acc) {
+		if len(ga.CreditLimits) > 0 {
+			fail("only members' asset accounts have credit limits")
+			return nil
+		}
+		return acc
+	}
+	for _, limit := range ga.CreditLimits {
+		acc.SetCreditLimit(limit)
+	}
+	// members' balances cannot be below their credit limits
+	for _, coin := range ga.Coins {
+		if coin.Amount < -acc.GetCreditLimit(coin.Denom) {
+			fail("balance %v is below the credit limit", coin)
+			ok = false
+		}
+	}
+	if !ok {
+		return nil
+	}
+	return acc
+}
+
+func parseGenesisAccount(entities map[string]string, pubHex, creatorHex, entityName string,
+	fail func(string, ...interface{})) (pub crypto.PubKey, creator sdk.Address, typ string, ok bool) {
+	ok = true
+	pub, err := PubKeyFromHexString(pubHex)
+	if err != nil {
+		fail("public key: %v", err)
+		ok = false
+	}
+	creator, err = sdk.GetAddress(creatorHex)
+	if err != nil {
+		fail("creator: %v", err)
+		ok = false
+	}
+	typ, declared := entities[entityName]
+	if !declared {
+		fail("entity %q is not declared", entityName)
+		ok = false
+	}
+	return
+}
+
+// parseGenesisAddress parses the address of an account whose key
+// was rotated, it returns nil if the address is omitted.
+func parseGenesisAddress(addrHex string, fail func(string, ...interface{})) (sdk.Address, bool) {
+	if addrHex == "" {
+		return nil, true
+	}
+	addr, err := sdk.GetAddress(addrHex)
+	if err != nil {
+		fail("address: %v", err)
+		return nil, false
+	}
+	return addr, true
+}
+
+// checkGenesisCreator ensures that the account's creator is a declared admin
+// that was entitled to create it: admins are created by clearing house
+// admins, operators and asset accounts by admins of their own entity.
+func checkGenesisCreator(admins map[string]*AppAccount, acc *AppAccount, fail func(string, ...interface{})) {
+	creator, ok := admins[string(acc.Creator)]
+	switch {
+	case !ok:
+		fail("creator %v is not a declared admin", acc.Creator)
+	case acc.IsAdmin() && bytes.Equal(acc.Address, acc.Creator):
+		fail("admin %v cannot create itself", acc.Address)
+	case acc.IsAdmin() && !IsClearingHouse(creator):
+		fail("creator %v is not a clearing house admin", acc.Creator)
+	case !acc.IsAdmin() && !BelongToSameEntity(creator, acc):
+		fail("creator %v belongs to another entity", acc.Creator)
+	}
+}
+
 // PubKeyFromHexString converts a hexadecimal string representation of
 // a public key into a crypto.PubKey instance.
 func PubKeyFromHexString(s string) (crypto.PubKey, error) {
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"testing"
 
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/wire"
 	"github.com/stretchr/testify/assert"
 	crypto "github.com/tendermint/go-crypto"
@@ -89,3 +90,227 @@ func TestPubKeyFromHexString(t *testing.T) {
 		})
 	}
 }
+
+func TestGenesisState_ToAppAccounts(t *testing.T) {
+	hexPub := func() string { return hex.EncodeToString(crypto.GenPrivKeyEd25519().PubKey().Bytes()) }
+	hexAddr := func(pub string) string {
+		pk, _ := PubKeyFromHexString(pub)
+		return hex.EncodeToString(pk.Address())
+	}
+	chAdmin := GenesisAccount{PubKeyHexa: hexPub(), EntityName: "CH"}
+	chAdminAddr := hexAddr(chAdmin.PubKeyHexa)
+	icmAdminPub := hexPub()
+	icmAdmin := GenesisUser{PubKeyHexa: icmAdminPub, EntityName: "ICM", Creator: chAdminAddr}
+	icmAdminAddr := hexAddr(icmAdminPub)
+	entities := []GenesisEntity{
+		{Name: "ICM", Type: EntityIndividualClearingMember, ID: "icm-1", LEI: "5493001KJTIIGC8Y1R12", Country: "GB"},
+		{Name: "CUST", Type: EntityCustodian},
+	}
+	usd := func(amount int64) sdk.Coins { return sdk.Coins{{"USD", amount}} }
+
+	tests := []struct {
+		name    string
+		state   GenesisState
+		wantLen int
+		wantErr bool
+	}{
+		{"empty", GenesisState{}, 0, false},
+		{"ch admin only", GenesisState{ClearingHouseAdmin: chAdmin}, 1, false},
+		{"full", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin},
+			Operators:          []GenesisUser{{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: icmAdminAddr}},
+			AssetAccounts: []GenesisAssetAccount{
+				{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: icmAdminAddr, Coins: usd(-50), CreditLimits: usd(50)},
+				{PubKeyHexa: hexPub(), EntityName: "CH", Creator: chAdminAddr, Coins: usd(50)},
+			},
+		}, 5, false},
+		{"duplicate entity", GenesisState{Entities: []GenesisEntity{
+			{Name: "ICM", Type: EntityIndividualClearingMember}, {Name: "ICM", Type: EntityCustodian}}}, 0, true},
+		{"duplicate entity ID", GenesisState{Entities: []GenesisEntity{
+			{Name: "ICM", Type: EntityIndividualClearingMember, ID: "e1"}, {Name: "CUST", Type: EntityCustodian, ID: "e1"}}}, 0, true},
+		{"invalid entity type", GenesisState{Entities: []GenesisEntity{{Name: "ICM", Type: "bank"}}}, 0, true},
+		{"invalid LEI", GenesisState{Entities: []GenesisEntity{
+			{Name: "ICM", Type: EntityIndividualClearingMember, LEI: "5493001KJTIIGC8Y1R13"}}}, 0, true},
+		{"invalid status", GenesisState{Entities: []GenesisEntity{
+			{Name: "ICM", Type: EntityIndividualClearingMember, Status: "dormant"}}}, 0, true},
+		{"name is not a valid default ID", GenesisState{Entities: []GenesisEntity{
+			{Name: "Member One", Type: EntityIndividualClearingMember}}}, 0, true},
+		{"ch admin entity declared with another type", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           []GenesisEntity{{Name: "CH", Type: EntityCustodian}},
+		}, 0, true},
+		{"undeclared ch admin entity named after another entity's ID", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           []GenesisEntity{{Name: "ICM", Type: EntityIndividualClearingMember, ID: "CH"}},
+		}, 0, true},
+		{"undeclared entity", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Admins:             []GenesisUser{icmAdmin},
+		}, 0, true},
+		{"admin created by a member admin", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin, {PubKeyHexa: hexPub(), EntityName: "CUST", Creator: icmAdminAddr}},
+		}, 0, true},
+		{"operator created by another entity's admin", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Operators:          []GenesisUser{{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: chAdminAddr}},
+		}, 0, true},
+		{"unknown creator", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{{PubKeyHexa: icmAdminPub, EntityName: "ICM", Creator: icmAdminAddr}},
+		}, 0, true},
+		{"member below its credit limit", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin},
+			AssetAccounts:      []GenesisAssetAccount{{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: icmAdminAddr, Coins: usd(-100), CreditLimits: usd(50)}},
+		}, 0, true},
+		{"unknown currency", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			AssetAccounts:      []GenesisAssetAccount{{PubKeyHexa: hexPub(), EntityName: "CH", Creator: chAdminAddr, Coins: sdk.Coins{{"ATM", 1}}}},
+		}, 0, true},
+		{"credit limit on a clearing house account", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			AssetAccounts:      []GenesisAssetAccount{{PubKeyHexa: hexPub(), EntityName: "CH", Creator: chAdminAddr, CreditLimits: usd(50)}},
+		}, 0, true},
+		{"operator limits", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin},
+			Operators:          []GenesisUser{{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: icmAdminAddr, MaxAmounts: usd(10), DailyLimits: usd(100)}},
+		}, 3, false},
+		{"negative operator limit", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin},
+			Operators:          []GenesisUser{{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: icmAdminAddr, DailyLimits: usd(-100)}},
+		}, 0, true},
+		{"admin with limits", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{{PubKeyHexa: icmAdminPub, EntityName: "ICM", Creator: chAdminAddr, MaxAmounts: usd(10)}},
+		}, 0, true},
+		{"roles", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin},
+			Operators:          []GenesisUser{{PubKeyHexa: hexPub(), EntityName: "ICM", Creator: icmAdminAddr, Roles: []string{RoleAuditor}}},
+		}, 3, false},
+		{"unknown role", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{{PubKeyHexa: icmAdminPub, EntityName: "ICM", Creator: chAdminAddr, Roles: []string{"superuser"}}},
+		}, 0, true},
+		{"closed account", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			AssetAccounts:      []GenesisAssetAccount{{PubKeyHexa: hexPub(), EntityName: "CH", Creator: chAdminAddr, Inactive: true, Closed: true}},
+		}, 2, false},
+		{"closed account with coins", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			AssetAccounts:      []GenesisAssetAccount{{PubKeyHexa: hexPub(), EntityName: "CH", Creator: chAdminAddr, Inactive: true, Closed: true, Coins: usd(50)}},
+		}, 0, true},
+		{"duplicate account", GenesisState{
+			ClearingHouseAdmin: chAdmin,
+			Entities:           entities,
+			Admins:             []GenesisUser{icmAdmin, icmAdmin},
+		}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.state.ToAppAccounts()
+			assert.Equal(t, tt.wantErr, err != nil, "%v", err)
+			assert.Equal(t, tt.wantLen, len(got))
+		})
+	}
+}
+
+func TestParseGenesisState(t *testing.T) {
+	chAdminKey := crypto.GenPrivKeyEd25519().PubKey()
+	chAdminPub := hex.EncodeToString(chAdminKey.Bytes())
+	chAdminAddr := hex.EncodeToString(chAdminKey.Address())
+	adminPub := hex.EncodeToString(crypto.GenPrivKeyEd25519().PubKey().Bytes())
+	tests := []struct {
+		name     string
+		state    string
+		wantErrs int
+	}{
+		{"valid", `{"ch_admin": {"public_key": "` + chAdminPub + `", "entity_name": "CH"}}`, 0},
+		{"malformed", `{"ch_admin": [}`, 1},
+		{"dual control", `{"dual_control": {"expiry_blocks": 0, "thresholds": [{"denom": "ATM", "amount": 1}]}}`, 1},
+		{"invariants", `{"invariants": {"check_interval": 0}}`, 1},
+		{"all problems at once", `{
+			"ch_admin": {"public_key": "` + chAdminPub + `", "entity_name": "CH"},
+			"entities": [{"name": "ICM", "type": "bank"}],
+			"admins": [
+				{"public_key": "zz", "entity_name": "CH", "creator": "` + chAdminAddr + `"},
+				{"public_key": "` + adminPub + `", "entity_name": "CH", "creator": "` + chAdminAddr + `"},
+				{"public_key": "` + adminPub + `", "entity_name": "CH", "creator": "` + chAdminAddr + `"}
+			]
+		}`, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseGenesisState([]byte(tt.state))
+			if tt.wantErrs == 0 {
+				assert.Nil(t, err)
+				return
+			}
+			if assert.IsType(t, GenesisErrors{}, err) {
+				assert.Equal(t, tt.wantErrs, len(err.(GenesisErrors)), "%v", err)
+			}
+		})
+	}
+}
+
+func TestNewGenesisState(t *testing.T) {
+	chAdmin, _ := makeAdminUser("CH", EntityClearingHouse)
+	chAdmin.Creator = nil
+	icmAdmin, _ := makeAdminUser("ICM", EntityIndividualClearingMember)
+	icmAdmin.Creator = chAdmin.Address
+	icmAdmin.Active = false
+	asset, _ := makeAssetAccount(sdk.Coins{{"USD", -10}}, "ICM", EntityIndividualClearingMember)
+	asset.Creator = icmAdmin.Address
+	asset.SetCreditLimit(sdk.Coin{"USD", 10})
+	operator, _ := makeUser("ICM", EntityIndividualClearingMember)
+	operator.Creator = icmAdmin.Address
+	operator.Roles = []string{}
+	// rotated keys no longer match their account's address
+	asset.PubKey = crypto.GenPrivKeyEd25519().PubKey()
+
+	icm := NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "5493001KJTIIGC8Y1R12", "GB")
+	gs, err := NewGenesisState([]Entity{icm}, []*AppAccount{chAdmin, icmAdmin, asset, operator})
+	assert.Nil(t, err)
+	assert.Equal(t, hex.EncodeToString(chAdmin.PubKey.Bytes()), gs.ClearingHouseAdmin.PubKeyHexa)
+	// unregistered entities are declared under their name
+	assert.Equal(t, []GenesisEntity{NewGenesisEntity(icm), {Name: "CH", Type: EntityClearingHouse}}, gs.Entities)
+	assert.Equal(t, []Entity{icm, NewEntity("CH", "CH", EntityClearingHouse, "", "")}, gs.ToEntities())
+	assert.True(t, gs.Admins[0].Inactive)
+	assert.Equal(t, asset.CreditLimits, gs.AssetAccounts[0].CreditLimits)
+	assert.Equal(t, hex.EncodeToString(asset.Address), gs.AssetAccounts[0].Address)
+	assert.Equal(t, "", gs.Admins[0].Address)
+	accounts, err := gs.ToAppAccounts()
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(accounts))
+	assert.True(t, accountEqual(asset, accounts[3]))
+	// users keep their roles, even when they have none
+	assert.Equal(t, DefaultAdminRoles(), accounts[1].Roles)
+	assert.Equal(t, 0, len(accounts[2].Roles))
+
+	// only the clearing house admin can have no creator
+	orphan, _ := makeUser("ICM", EntityIndividualClearingMember)
+	orphan.Creator = nil
+	_, err = NewGenesisState(nil, []*AppAccount{chAdmin, orphan})
+	assert.NotNil(t, err)
+	// entity names identify entities
+	clash, _ := makeAssetAccount(nil, "ICM", EntityCustodian)
+	_, err = NewGenesisState(nil, []*AppAccount{icmAdmin, clash})
+	assert.NotNil(t, err)
+	// accounts cannot change their registered entity's type
+	_, err = NewGenesisState([]Entity{NewEntity("cust", "ICM", EntityCustodian, "", "")}, []*AppAccount{icmAdmin})
+	assert.NotNil(t, err)
+}
@@ -1,7 +1,9 @@
 package types
 
 import (
+	"bytes"
 	"fmt"
+	"strconv"
 
 	"github.com/cosmos/cosmos-sdk/baseapp"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -9,15 +11,35 @@ import (
 )
 
 // RegisterRoutes routes the message (request) to a proper handler.
-func RegisterRoutes(r baseapp.Router, accts sdk.AccountMapper) {
-	r.AddRoute(DepositType, DepositMsgHandler(accts)).
-		AddRoute(SettlementType, SettleMsgHandler(accts)).
-		AddRoute(WithdrawType, WithdrawMsgHandler(accts)).
+func RegisterRoutes(r baseapp.Router, accts sdk.AccountMapper, entities EntityMapper, netting NettingMapper,
+	dualControl DualControlMapper, refs ReferenceMapper, ledger Ledger) {
+	r.AddRoute(DepositType, SingleControlHandler(dualControl, ReferenceHandler(refs, DepositMsgHandler(accts, ledger)))).
+		AddRoute(SettlementType, SingleControlHandler(dualControl, ReferenceHandler(refs, SettleMsgHandler(accts, ledger)))).
+		AddRoute(BatchSettlementType, BatchSettleMsgHandler(accts, dualControl, ledger)).
+		AddRoute(SubmitObligationType, SubmitObligationMsgHandler(accts, netting)).
+		AddRoute(NetSettlementType, NetSettlementMsgHandler(accts, netting, dualControl, ledger)).
+		AddRoute(CancelNettingCycleType, CancelNettingCycleMsgHandler(accts, netting)).
+		AddRoute(WithdrawType, SingleControlHandler(dualControl, ReferenceHandler(refs, WithdrawMsgHandler(accts, ledger)))).
 		AddRoute(CreateOperatorType, CreateOperatorMsgHandler(accts)).
-		AddRoute(CreateAdminType, CreateAdminMsgHandler(accts)).
+		AddRoute(RegisterEntityType, RegisterEntityMsgHandler(accts, entities)).
+		AddRoute(UpdateEntityStatusType, UpdateEntityStatusMsgHandler(accts, entities)).
+		AddRoute(CreateAdminType, CreateAdminMsgHandler(accts, entities)).
 		AddRoute(CreateAssetAccountType, CreateAssetAccountMsgHandler(accts)).
 		AddRoute(FreezeOperatorType, FreezeOperatorMsgHandler(accts)).
-		AddRoute(FreezeAdminType, FreezeAdminMsgHandler(accts))
+		AddRoute(FreezeAdminType, FreezeAdminMsgHandler(accts)).
+		AddRoute(UnfreezeOperatorType, UnfreezeOperatorMsgHandler(accts)).
+		AddRoute(UnfreezeAdminType, UnfreezeAdminMsgHandler(accts)).
+		AddRoute(FreezeAssetAccountType, FreezeAssetAccountMsgHandler(accts)).
+		AddRoute(UnfreezeAssetAccountType, UnfreezeAssetAccountMsgHandler(accts)).
+		AddRoute(SetCreditLimitType, SetCreditLimitMsgHandler(accts)).
+		AddRoute(SetOperatorLimitsType, SetOperatorLimitsMsgHandler(accts)).
+		AddRoute(GrantRoleType, GrantRoleMsgHandler(accts)).
+		AddRoute(RevokeRoleType, RevokeRoleMsgHandler(accts)).
+		AddRoute(RotateKeyType, RotateKeyMsgHandler(accts)).
+		AddRoute(CloseAssetAccountType, CloseAssetAccountMsgHandler(accts, ledger)).
+		AddRoute(ProposeTransferType, ProposeTransferMsgHandler(accts, dualControl, refs)).
+		AddRoute(ApproveTransferType, ApproveTransferMsgHandler(accts, dualControl, refs, ledger)).
+		AddRoute(RejectTransferType, RejectTransferMsgHandler(accts, dualControl))
 }
 
 /*
@@ -27,11 +49,14 @@ Deposit functionality.
 Sender is Custodian
 Rec is Member
 */
-func DepositMsgHandler(accts sdk.AccountMapper) sdk.Handler {
-	return depositMsgHandler{accts}.Do
+func DepositMsgHandler(accts sdk.AccountMapper, ledger Ledger) sdk.Handler {
+	return depositMsgHandler{accts, ledger}.Do
 }
 
-type depositMsgHandler struct{ accts sdk.AccountMapper }
+type depositMsgHandler struct {
+	accts  sdk.AccountMapper
+	ledger Ledger
+}
 
 // Deposit logic
 func (d depositMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
@@ -41,7 +66,8 @@ func (d depositMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		return ErrWrongMsgFormat("expected DepositMsg").Result()
 	}
 	// ensure proper types
-	if _, err := getCHActiveOperator(ctx, d.accts, dm.Operator); err != nil {
+	operator, err := getCHActiveUserWithPermission(ctx, d.accts, dm.Operator, PermDeposit)
+	if err != nil {
 		return err.Result()
 	}
 	sender, err := getActiveAssetWithEntityType(ctx, d.accts, dm.Sender, IsCustodian)
@@ -52,11 +78,16 @@ func (d depositMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 	if err != nil {
 		return err.Result()
 	}
+	if err := operator.OperatorLimits.Use(dm.Amount, ctx.BlockHeader().Time); err != nil {
+		return err.Result()
+	}
 	// Exchange cash
 	if err := moveMoney(d.accts, ctx, sender, rcpt, dm.Amount, false, true); err != nil {
 		return err.Result()
 	}
-	return sdk.Result{}
+	d.accts.SetAccount(ctx, operator)
+	d.ledger.AddTransfer(ctx, DepositType, sender.Address, rcpt.Address, dm.Amount)
+	return transferTags(DepositType, operator, sender, rcpt, dm.Amount, dm.Reference).Result()
 }
 
 /*
@@ -66,9 +97,14 @@ Operator is CH
 Sender is CH
 Rec is member
 */
-func SettleMsgHandler(accts sdk.AccountMapper) sdk.Handler { return settleMsgHandler{accts}.Do }
+func SettleMsgHandler(accts sdk.AccountMapper, ledger Ledger) sdk.Handler {
+	return settleMsgHandler{accts, ledger}.Do
+}
 
-type settleMsgHandler struct{ accts sdk.AccountMapper }
+type settleMsgHandler struct {
+	accts  sdk.AccountMapper
+	ledger Ledger
+}
 
 // Settlement logic
 func (sh settleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
@@ -78,7 +114,7 @@ func (sh settleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		return ErrWrongMsgFormat("expected SettleMsg").Result()
 	}
 	// ensure proper types
-	operator, err := getCHActiveOperator(ctx, sh.accts, sm.Operator)
+	operator, err := getCHActiveUserWithPermission(ctx, sh.accts, sm.Operator, PermSettle)
 	if err != nil {
 		return err.Result()
 	}
@@ -93,10 +129,268 @@ func (sh settleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 	if err != nil {
 		return err.Result()
 	}
+	if err := operator.OperatorLimits.Use(sm.Amount, ctx.BlockHeader().Time); err != nil {
+		return err.Result()
+	}
 	if err := moveMoney(sh.accts, ctx, sender, rcpt, sm.Amount, false, true); err != nil {
 		return err.Result()
 	}
-	return sdk.Result{}
+	sh.accts.SetAccount(ctx, operator)
+	sh.ledger.AddTransfer(ctx, SettlementType, sender.Address, rcpt.Address, sm.Amount)
+	return transferTags(SettlementType, operator, sender, rcpt, sm.Amount, sm.Reference).Result()
+}
+
+// BatchSettleMsgHandler implements the multi-leg settlement functionality.
+//
+// Operator is CH
+// Sender is CH
+// Recipients are members
+func BatchSettleMsgHandler(accts sdk.AccountMapper, dualControl DualControlMapper, ledger Ledger) sdk.Handler {
+	return batchSettleMsgHandler{accts, dualControl, ledger}.Do
+}
+
+type batchSettleMsgHandler struct {
+	accts       sdk.AccountMapper
+	dualControl DualControlMapper
+	ledger      Ledger
+}
+
+// Batch settlement logic.
+// Legs are applied in memory first, accounts are
+// saved only if all of them succeed. Batches above
+// the dual control thresholds are rejected, every leg
+// is charged to the operator's limits.
+func (h batchSettleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	bm, ok := msg.(BatchSettleMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected BatchSettleMsg").Result()
+	}
+	// ensure proper types
+	operator, err := getCHActiveUserWithPermission(ctx, h.accts, bm.Operator, PermSettle)
+	if err != nil {
+		return err.Result()
+	}
+	sender, err := getActiveAssetWithEntityType(ctx, h.accts, bm.Sender, IsClearingHouse)
+	if err != nil {
+		return err.Result()
+	}
+	if !BelongToSameEntity(operator, sender) {
+		return ErrWrongSigner("operator and sender must belong to the same entity").Result()
+	}
+	amounts := make([]sdk.Coin, len(bm.Legs))
+	for i, leg := range bm.Legs {
+		amounts[i] = leg.Amount
+	}
+	if err := checkSingleControl(h.dualControl.GetParams(ctx), amounts); err != nil {
+		return err.Result()
+	}
+	for i, amount := range amounts {
+		if err := operator.OperatorLimits.Use(amount, ctx.BlockHeader().Time); err != nil {
+			return err.Trace(fmt.Sprintf("leg %d", i)).Result()
+		}
+	}
+	// recipients may appear in more than one leg
+	rcpts := make(map[string]*AppAccount)
+	var touched []*AppAccount
+	for i, leg := range bm.Legs {
+		rcpt, ok := rcpts[string(leg.Recipient)]
+		if !ok {
+			rcpt, err = getActiveAssetWithEntityType(ctx, h.accts, leg.Recipient, IsMember)
+			if err != nil {
+				return err.Trace(fmt.Sprintf("leg %d", i)).Result()
+			}
+			rcpts[string(leg.Recipient)] = rcpt
+			touched = append(touched, rcpt)
+		}
+		if err := transferMoney(sender, rcpt, leg.Amount, false, true); err != nil {
+			return err.Trace(fmt.Sprintf("leg %d", i)).Result()
+		}
+	}
+	h.accts.SetAccount(ctx, operator)
+	h.accts.SetAccount(ctx, sender)
+	tags := NewTags(BatchSettlementType).AppendAccount(TagOperator, operator).AppendAccount(TagSender, sender)
+	for _, rcpt := range touched {
+		h.accts.SetAccount(ctx, rcpt)
+		tags = tags.AppendAccount(TagRecipient, rcpt)
+	}
+	h.ledger.AddTransfers(ctx, BatchSettlementType, sender.Address, bm.Legs)
+	for _, leg := range bm.Legs {
+		tags = tags.AppendCoin(leg.Amount)
+	}
+	return tags.Result()
+}
+
+// SubmitObligationMsgHandler returns the handler's method.
+func SubmitObligationMsgHandler(accts sdk.AccountMapper, netting NettingMapper) sdk.Handler {
+	return submitObligationMsgHandler{accts, netting}.Do
+}
+
+type submitObligationMsgHandler struct {
+	accts   sdk.AccountMapper
+	netting NettingMapper
+}
+
+// Submit obligation logic.
+// Clearing house operators record obligations between members.
+func (h submitObligationMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	om, ok := msg.(SubmitObligationMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected SubmitObligationMsg").Result()
+	}
+	// ensure proper types
+	operator, err := getCHActiveUserWithPermission(ctx, h.accts, om.Operator, PermSettle)
+	if err != nil {
+		return err.Result()
+	}
+	debtor, err := getActiveAssetWithEntityType(ctx, h.accts, om.Debtor, IsMember)
+	if err != nil {
+		return err.Result()
+	}
+	creditor, err := getActiveAssetWithEntityType(ctx, h.accts, om.Creditor, IsMember)
+	if err != nil {
+		return err.Result()
+	}
+	h.netting.AddObligation(ctx, om.Obligation)
+	return NewTags(SubmitObligationType).
+		AppendAccount(TagOperator, operator).
+		AppendAccount(TagDebtor, debtor).
+		AppendAccount(TagCreditor, creditor).
+		AppendCoin(om.Amount).
+		Append(TagCycle, strconv.FormatInt(h.netting.GetCurrentCycle(ctx), 10)).
+		Result()
+}
+
+// NetSettlementMsgHandler implements the net settlement functionality.
+//
+// Operator is CH
+// Sender is CH
+// Recipients are the members involved in the cycle's obligations
+func NetSettlementMsgHandler(accts sdk.AccountMapper, netting NettingMapper, dualControl DualControlMapper,
+	ledger Ledger) sdk.Handler {
+	return netSettlementMsgHandler{accts, netting, dualControl, ledger}.Do
+}
+
+type netSettlementMsgHandler struct {
+	accts       sdk.AccountMapper
+	netting     NettingMapper
+	dualControl DualControlMapper
+	ledger      Ledger
+}
+
+// Net settlement logic.
+// Net positions are settled all-or-nothing like a batch settlement,
+// then the result is stored and a new cycle begins. Positions above
+// the dual control thresholds are rejected like batch settlements'.
+func (h netSettlementMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	nm, ok := msg.(NetSettlementMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected NetSettlementMsg").Result()
+	}
+	// ensure proper types
+	operator, err := getCHActiveUserWithPermission(ctx, h.accts, nm.Operator, PermSettle)
+	if err != nil {
+		return err.Result()
+	}
+	sender, err := getActiveAssetWithEntityType(ctx, h.accts, nm.Sender, IsClearingHouse)
+	if err != nil {
+		return err.Result()
+	}
+	if !BelongToSameEntity(operator, sender) {
+		return ErrWrongSigner("operator and sender must belong to the same entity").Result()
+	}
+	cycle := h.netting.GetCurrentCycle(ctx)
+	obligations := h.netting.GetObligations(ctx, cycle)
+	if len(obligations) == 0 {
+		return ErrInvalidAmount(fmt.Sprintf("no obligations in cycle %d", cycle)).Result()
+	}
+	positions := ComputeNetPositions(obligations)
+	var amounts []sdk.Coin
+	for _, pos := range positions {
+		amounts = append(amounts, pos.Amount...)
+	}
+	if err := checkSingleControl(h.dualControl.GetParams(ctx), amounts); err != nil {
+		return err.Result()
+	}
+	for _, amount := range amounts {
+		if err := operator.OperatorLimits.Use(amount, ctx.BlockHeader().Time); err != nil {
+			return err.Result()
+		}
+	}
+	members := make([]*AppAccount, len(positions))
+	for i, pos := range positions {
+		member, err := getActiveAssetWithEntityType(ctx, h.accts, pos.Member, IsMember)
+		if err != nil {
+			return err.Result()
+		}
+		for _, coin := range pos.Amount {
+			if err := transferMoney(sender, member, coin, false, true); err != nil {
+				return err.Trace(fmt.Sprintf("member %v", pos.Member)).Result()
+			}
+		}
+		members[i] = member
+	}
+	h.accts.SetAccount(ctx, operator)
+	h.accts.SetAccount(ctx, sender)
+	tags := NewTags(NetSettlementType).
+		AppendAccount(TagOperator, operator).
+		AppendAccount(TagSender, sender).
+		Append(TagCycle, strconv.FormatInt(cycle, 10))
+	var legs []SettleLeg
+	for i, member := range members {
+		h.accts.SetAccount(ctx, member)
+		for _, coin := range positions[i].Amount {
+			legs = append(legs, SettleLeg{member.Address, coin})
+		}
+		tags = tags.AppendAccount(TagRecipient, member)
+	}
+	h.ledger.AddTransfers(ctx, NetSettlementType, sender.Address, legs)
+	h.netting.CloseCycle(ctx, NettingResult{
+		Cycle:     cycle,
+		Height:    ctx.BlockHeight(),
+		Sender:    sender.Address,
+		Positions: positions,
+	})
+	return tags.Result()
+}
+
+// CancelNettingCycleMsgHandler returns the handler's method.
+func CancelNettingCycleMsgHandler(accts sdk.AccountMapper, netting NettingMapper) sdk.Handler {
+	return cancelNettingCycleMsgHandler{accts, netting}.Do
+}
+
+type cancelNettingCycleMsgHandler struct {
+	accts   sdk.AccountMapper
+	netting NettingMapper
+}
+
+// Cancel netting cycle logic.
+// Clearing house operators discard the current cycle's
+// obligations, no money moves.
+func (h cancelNettingCycleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(CancelNettingCycleMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected CancelNettingCycleMsg").Result()
+	}
+	// ensure proper types
+	operator, err := getCHActiveUserWithPermission(ctx, h.accts, cm.Operator, PermSettle)
+	if err != nil {
+		return err.Result()
+	}
+	if cycle := h.netting.GetCurrentCycle(ctx); cm.Cycle != cycle {
+		return ErrInvalidCycle(fmt.Sprintf("%d is not the current cycle %d", cm.Cycle, cycle)).Result()
+	}
+	if len(h.netting.GetObligations(ctx, cm.Cycle)) == 0 {
+		return ErrInvalidCycle(fmt.Sprintf("no obligations in cycle %d", cm.Cycle)).Result()
+	}
+	h.netting.CancelCycle(ctx, cm.Cycle)
+	return NewTags(CancelNettingCycleType).
+		AppendAccount(TagOperator, operator).
+		Append(TagCycle, strconv.FormatInt(cm.Cycle, 10)).
+		Result()
 }
 
 // WithdrawMsgHandler implements the withdraw functionality.
@@ -104,11 +398,13 @@ func (sh settleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 // Sender is member
 // Reci is custodian
 // Operator is CH
-//
-func WithdrawMsgHandler(accts sdk.AccountMapper) sdk.Handler { return withdrawMsgHandler{accts}.Do }
+func WithdrawMsgHandler(accts sdk.AccountMapper, ledger Ledger) sdk.Handler {
+	return withdrawMsgHandler{accts, ledger}.Do
+}
 
 type withdrawMsgHandler struct {
-	accts sdk.AccountMapper
+	accts  sdk.AccountMapper
+	ledger Ledger
 }
 
 // Withdraw logic
@@ -119,7 +415,7 @@ func (wh withdrawMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		return ErrWrongMsgFormat("expected WithdrawMsg").Result()
 	}
 	// ensure proper types
-	_, err := getCHActiveOperator(ctx, wh.accts, wm.Operator)
+	operator, err := getCHActiveUserWithPermission(ctx, wh.accts, wm.Operator, PermWithdraw)
 	if err != nil {
 		return err.Result()
 	}
@@ -131,12 +427,16 @@ func (wh withdrawMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 	if err != nil {
 		return err.Result()
 	}
+	if err := operator.OperatorLimits.Use(wm.Amount, ctx.BlockHeader().Time); err != nil {
+		return err.Result()
+	}
 	err = moveMoney(wh.accts, ctx, sender, rcpt, wm.Amount, true, false)
 	if err != nil {
 		return err.Result()
 	}
-	return sdk.Result{}
-
+	wh.accts.SetAccount(ctx, operator)
+	wh.ledger.AddTransfer(ctx, WithdrawType, sender.Address, rcpt.Address, wm.Amount)
+	return transferTags(WithdrawType, operator, sender, rcpt, wm.Amount, wm.Reference).Result()
 }
 
 // CreateOperatorMsgHandler returns the handler's method.
@@ -157,28 +457,116 @@ func (h createOperatorMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		return err.Result()
 	}
 	h.accts.SetAccount(ctx, newAcct)
-	return sdk.Result{}
+	return targetTags(CreateOperatorType, cm.Creator, newAcct).Result()
+}
+
+// RegisterEntityMsgHandler returns the handler's method.
+func RegisterEntityMsgHandler(accts sdk.AccountMapper, entities EntityMapper) sdk.Handler {
+	return registerEntityMsgHandler{accts, entities}.Do
+}
+
+type registerEntityMsgHandler struct {
+	accts    sdk.AccountMapper
+	entities EntityMapper
+}
+
+// Register entity's message logic.
+// Clearing house admins can register legal entities,
+// neither their IDs nor their names can be reused.
+func (h registerEntityMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	rm, ok := msg.(RegisterEntityMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected RegisterEntityMsg").Result()
+	}
+	if _, err := getCHActiveUserWithPermission(ctx, h.accts, rm.Admin, PermManageUsers); err != nil {
+		return err.Result()
+	}
+	if _, found := h.entities.GetEntity(ctx, rm.EntityID); found {
+		return ErrDuplicateEntity(fmt.Sprintf("ID %q is already registered", rm.EntityID)).Result()
+	}
+	if _, found := h.entities.GetEntityByName(ctx, rm.EntityName); found {
+		return ErrDuplicateEntity(fmt.Sprintf("name %q is already registered", rm.EntityName)).Result()
+	}
+	entity := rm.Entity()
+	h.entities.SetEntity(ctx, entity)
+	return NewTags(RegisterEntityType).
+		AppendAddress(TagAdmin, rm.Admin).
+		Append(TagEntityID, entity.ID).
+		Append(TagEntityType, entity.Type).Result()
+}
+
+// UpdateEntityStatusMsgHandler returns the handler's method.
+func UpdateEntityStatusMsgHandler(accts sdk.AccountMapper, entities EntityMapper) sdk.Handler {
+	return updateEntityStatusMsgHandler{accts, entities}.Do
+}
+
+type updateEntityStatusMsgHandler struct {
+	accts    sdk.AccountMapper
+	entities EntityMapper
+}
+
+// Update entity status' message logic.
+// Clearing house admins can suspend, reactivate and close
+// registered legal entities, closed entities cannot be reopened.
+func (h updateEntityStatusMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	um, ok := msg.(UpdateEntityStatusMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected UpdateEntityStatusMsg").Result()
+	}
+	if _, err := getCHActiveUserWithPermission(ctx, h.accts, um.Admin, PermManageUsers); err != nil {
+		return err.Result()
+	}
+	entity, found := h.entities.GetEntity(ctx, um.EntityID)
+	if !found {
+		return ErrInvalidLegalEntity(fmt.Sprintf("%q is not registered", um.EntityID)).Result()
+	}
+	if entity.Status == EntityStatusClosed {
+		return ErrInvalidLegalEntity(fmt.Sprintf("%q is closed", um.EntityID)).Result()
+	}
+	if entity.Status == um.Status {
+		return ErrInvalidLegalEntity(fmt.Sprintf("%q is already %s", um.EntityID, um.Status)).Result()
+	}
+	entity.Status = um.Status
+	h.entities.SetEntity(ctx, entity)
+	return NewTags(UpdateEntityStatusType).
+		AppendAddress(TagAdmin, um.Admin).
+		Append(TagEntityID, entity.ID).
+		Append(TagEntityStatus, entity.Status).Result()
 }
 
 // CreateAdminMsgHandler returns the handler's method.
-func CreateAdminMsgHandler(accts sdk.AccountMapper) sdk.Handler {
-	return createAdminMsgHandler{accts}.Do
+func CreateAdminMsgHandler(accts sdk.AccountMapper, entities EntityMapper) sdk.Handler {
+	return createAdminMsgHandler{accts, entities}.Do
 }
 
-type createAdminMsgHandler struct{ accts sdk.AccountMapper }
+type createAdminMsgHandler struct {
+	accts    sdk.AccountMapper
+	entities EntityMapper
+}
 
+// Create admin's message logic.
+// Clearing house admins can create admins of registered, active legal entities.
 func (h createAdminMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 	// ensure proper message
 	cm, ok := msg.(CreateAdminMsg)
 	if !ok {
 		return ErrWrongMsgFormat("expected CreateAdminMsg").Result()
 	}
-	newAcct, err := validateCHAdminAndCreateXEntityAdmin(ctx, h.accts, cm.Creator, cm.PubKey, cm.BaseLegalEntity)
+	entity, found := h.entities.GetEntity(ctx, cm.EntityID)
+	if !found {
+		return ErrInvalidLegalEntity(fmt.Sprintf("%q is not registered", cm.EntityID)).Result()
+	}
+	if !entity.IsActive() {
+		return ErrInvalidLegalEntity(fmt.Sprintf("%q is %s", cm.EntityID, entity.Status)).Result()
+	}
+	newAcct, err := validateCHAdminAndCreateXEntityAdmin(ctx, h.accts, cm.Creator, cm.PubKey, entity)
 	if err != nil {
 		return err.Result()
 	}
 	h.accts.SetAccount(ctx, newAcct)
-	return sdk.Result{}
+	return targetTags(CreateAdminType, cm.Creator, newAcct).Result()
 }
 
 // CreateAssetAccountMsgHandler returns the handler's method.
@@ -200,18 +588,21 @@ func (h createAssetAccountMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Resul
 	// ensure creator exists
 	// no need for type checking, CreateAssetAccount
 	// validates types too.
-	creator, err := getActiveAdmin(ctx, h.accts, cm.Creator)
+	creator, err := getActiveUserWithPermission(ctx, h.accts, cm.Creator, PermManageUsers)
 	if err != nil {
 		return err.Result()
 	}
+	if err := checkEntityActive(ctx, h.accts, creator); err != nil {
+		return err.Result()
+	}
 	// ensure new account does not exist
-	if h.accts.GetAccount(ctx, cm.PubKey.Address()) != nil {
+	if isKeyBound(ctx, h.accts, cm.PubKey, nil) {
 		return ErrInvalidAccount("the account already exists").Result()
 	}
 	// Construct a new account
 	newAcct := NewAssetAccount(cm.PubKey, sdk.Coins{}, creator.Address, creator.LegalEntityName(), creator.LegalEntityType())
 	h.accts.SetAccount(ctx, newAcct)
-	return sdk.Result{}
+	return targetTags(CreateAssetAccountType, cm.Creator, newAcct).Result()
 }
 
 // FreezeOperatorMsgHandler returns the handler's method.
@@ -230,7 +621,7 @@ func (h freezeOperatorMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		return ErrWrongMsgFormat("expected FreezeOperatorMsg").Result()
 	}
 	// ensure admin exists
-	admin, err := getActiveAdmin(ctx, h.accts, cm.Admin)
+	admin, err := getActiveUserWithPermission(ctx, h.accts, cm.Admin, PermManageUsers)
 	if err != nil {
 		return err.Result()
 	}
@@ -245,7 +636,7 @@ func (h freezeOperatorMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 	// Construct a new account
 	operator.Active = false
 	h.accts.SetAccount(ctx, operator)
-	return sdk.Result{}
+	return targetTags(FreezeOperatorType, cm.Admin, operator).Result()
 }
 
 // FreezeAdminMsgHandler returns the handler's method.
@@ -264,7 +655,7 @@ func (h freezeAdminMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 		return ErrWrongMsgFormat("expected FreezeAdminMsg").Result()
 	}
 	// ensure clearing house admin exists
-	if _, err := getCHActiveAdmin(ctx, h.accts, cm.Admin); err != nil {
+	if _, err := getCHActiveUserWithPermission(ctx, h.accts, cm.Admin, PermManageUsers); err != nil {
 		return err.Result()
 	}
 	// ensure target admin exists
@@ -275,19 +666,546 @@ func (h freezeAdminMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
 	// Construct a new account
 	admin.Active = false
 	h.accts.SetAccount(ctx, admin)
-	return sdk.Result{}
+	return targetTags(FreezeAdminType, cm.Admin, admin).Result()
+}
+
+// UnfreezeOperatorMsgHandler returns the handler's method.
+func UnfreezeOperatorMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return unfreezeOperatorMsgHandler{accts}.Do
+}
+
+type unfreezeOperatorMsgHandler struct{ accts sdk.AccountMapper }
+
+// Unfreeze operator's message logic.
+// Admins can unfreeze their own entity's operator accounts.
+func (h unfreezeOperatorMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(UnfreezeOperatorMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected UnfreezeOperatorMsg").Result()
+	}
+	// ensure admin exists
+	admin, err := getActiveUserWithPermission(ctx, h.accts, cm.Admin, PermManageUsers)
+	if err != nil {
+		return err.Result()
+	}
+	// ensure frozen operator exists
+	operator, err := getInactiveOperator(ctx, h.accts, cm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	if !BelongToSameEntity(admin, operator) {
+		return ErrWrongSigner("admin and operator do not belong to the same entity").Result()
+	}
+	operator.Active = true
+	h.accts.SetAccount(ctx, operator)
+	return targetTags(UnfreezeOperatorType, cm.Admin, operator).Result()
+}
+
+// UnfreezeAdminMsgHandler returns the handler's method.
+func UnfreezeAdminMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return unfreezeAdminMsgHandler{accts}.Do
+}
+
+type unfreezeAdminMsgHandler struct{ accts sdk.AccountMapper }
+
+// Unfreeze admin's message logic.
+// Clearing house Admins can unfreeze any other admin.
+func (h unfreezeAdminMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(UnfreezeAdminMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected UnfreezeAdminMsg").Result()
+	}
+	// ensure clearing house admin exists
+	if _, err := getCHActiveUserWithPermission(ctx, h.accts, cm.Admin, PermManageUsers); err != nil {
+		return err.Result()
+	}
+	// ensure frozen target admin exists
+	admin, err := getInactiveAdmin(ctx, h.accts, cm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	admin.Active = true
+	h.accts.SetAccount(ctx, admin)
+	return targetTags(UnfreezeAdminType, cm.Admin, admin).Result()
+}
+
+// FreezeAssetAccountMsgHandler returns the handler's method.
+func FreezeAssetAccountMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return freezeAssetAccountMsgHandler{accts}.Do
+}
+
+type freezeAssetAccountMsgHandler struct{ accts sdk.AccountMapper }
+
+// Freeze asset account's message logic.
+// Admins can freeze their own entity's asset accounts,
+// clearing house admins can freeze any asset account.
+func (h freezeAssetAccountMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(FreezeAssetAccountMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected FreezeAssetAccountMsg").Result()
+	}
+	asset, err := validateAdminAndGetAsset(ctx, h.accts, cm.Admin, cm.Target, true)
+	if err != nil {
+		return err.Result()
+	}
+	asset.Active = false
+	h.accts.SetAccount(ctx, asset)
+	return targetTags(FreezeAssetAccountType, cm.Admin, asset).Result()
+}
+
+// UnfreezeAssetAccountMsgHandler returns the handler's method.
+func UnfreezeAssetAccountMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return unfreezeAssetAccountMsgHandler{accts}.Do
+}
+
+type unfreezeAssetAccountMsgHandler struct{ accts sdk.AccountMapper }
+
+// Unfreeze asset account's message logic.
+// Admins can unfreeze their own entity's asset accounts,
+// clearing house admins can unfreeze any asset account.
+func (h unfreezeAssetAccountMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(UnfreezeAssetAccountMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected UnfreezeAssetAccountMsg").Result()
+	}
+	asset, err := validateAdminAndGetAsset(ctx, h.accts, cm.Admin, cm.Target, false)
+	if err != nil {
+		return err.Result()
+	}
+	asset.Active = true
+	h.accts.SetAccount(ctx, asset)
+	return targetTags(UnfreezeAssetAccountType, cm.Admin, asset).Result()
+}
+
+// SetCreditLimitMsgHandler returns the handler's method.
+func SetCreditLimitMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return setCreditLimitMsgHandler{accts}.Do
+}
+
+type setCreditLimitMsgHandler struct{ accts sdk.AccountMapper }
+
+// Set credit limit's message logic.
+// Clearing house admins can set credit limits on members' asset accounts.
+func (h setCreditLimitMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(SetCreditLimitMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected SetCreditLimitMsg").Result()
+	}
+	if _, err := getCHActiveUserWithPermission(ctx, h.accts, cm.Admin, PermManageUsers); err != nil {
+		return err.Result()
+	}
+	asset, err := getActiveAsset(ctx, h.accts, cm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	if !IsMember(asset) {
+		return ErrInvalidAccount("only member asset accounts have a credit limit").Result()
+	}
+	asset.SetCreditLimit(cm.Limit)
+	h.accts.SetAccount(ctx, asset)
+	return targetTags(SetCreditLimitType, cm.Admin, asset).AppendCoin(cm.Limit).Result()
+}
+
+// SetOperatorLimitsMsgHandler returns the handler's method.
+func SetOperatorLimitsMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return setOperatorLimitsMsgHandler{accts}.Do
+}
+
+type setOperatorLimitsMsgHandler struct{ accts sdk.AccountMapper }
+
+// Set operator limits' message logic.
+// Admins can set limits on their own entity's users
+// whose roles allow moving money, admins included.
+func (h setOperatorLimitsMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	lm, ok := msg.(SetOperatorLimitsMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected SetOperatorLimitsMsg").Result()
+	}
+	admin, err := getActiveUserWithPermission(ctx, h.accts, lm.Admin, PermManageUsers)
+	if err != nil {
+		return err.Result()
+	}
+	operator, err := getActiveUser(ctx, h.accts, lm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	if !operator.CanTransfer() {
+		return ErrWrongSigner(fmt.Sprintf("%v cannot move money", lm.Target)).Result()
+	}
+	if !BelongToSameEntity(admin, operator) {
+		return ErrWrongSigner("admin and operator do not belong to the same entity").Result()
+	}
+	operator.OperatorLimits.SetLimits(lm.MaxAmount, lm.DailyLimit)
+	h.accts.SetAccount(ctx, operator)
+	return targetTags(SetOperatorLimitsType, lm.Admin, operator).Result()
+}
+
+// GrantRoleMsgHandler returns the handler's method.
+func GrantRoleMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return grantRoleMsgHandler{accts}.Do
+}
+
+type grantRoleMsgHandler struct{ accts sdk.AccountMapper }
+
+// Grant role's message logic.
+// Admins can grant roles to their own entity's users.
+func (h grantRoleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	rm, ok := msg.(GrantRoleMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected GrantRoleMsg").Result()
+	}
+	user, err := validateAdminAndGetUser(ctx, h.accts, rm.Admin, rm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	if !user.GrantRole(rm.Role) {
+		return ErrInvalidRole(fmt.Sprintf("%v already has role %s", rm.Target, rm.Role)).Result()
+	}
+	h.accts.SetAccount(ctx, user)
+	return targetTags(GrantRoleType, rm.Admin, user).Append(TagRole, rm.Role).Result()
+}
+
+// RevokeRoleMsgHandler returns the handler's method.
+func RevokeRoleMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return revokeRoleMsgHandler{accts}.Do
+}
+
+type revokeRoleMsgHandler struct{ accts sdk.AccountMapper }
+
+// Revoke role's message logic.
+// Admins can revoke roles from their own entity's users.
+func (h revokeRoleMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	rm, ok := msg.(RevokeRoleMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected RevokeRoleMsg").Result()
+	}
+	user, err := validateAdminAndGetUser(ctx, h.accts, rm.Admin, rm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	if !user.RevokeRole(rm.Role) {
+		return ErrInvalidRole(fmt.Sprintf("%v does not have role %s", rm.Target, rm.Role)).Result()
+	}
+	h.accts.SetAccount(ctx, user)
+	return targetTags(RevokeRoleType, rm.Admin, user).Append(TagRole, rm.Role).Result()
+}
+
+// RotateKeyMsgHandler returns the handler's method.
+func RotateKeyMsgHandler(accts sdk.AccountMapper) sdk.Handler {
+	return rotateKeyMsgHandler{accts}.Do
+}
+
+type rotateKeyMsgHandler struct{ accts sdk.AccountMapper }
+
+// Rotate key's message logic.
+// Admins can rotate the keys of their own entity's accounts, clearing
+// house admins the keys of any account, frozen accounts included.
+// The ante handler checks signatures against the account's key,
+// so the old key can no longer sign once it is replaced.
+func (h rotateKeyMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	rm, ok := msg.(RotateKeyMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected RotateKeyMsg").Result()
+	}
+	admin, err := getActiveUserWithPermission(ctx, h.accts, rm.Admin, PermManageUsers)
+	if err != nil {
+		return err.Result()
+	}
+	target, ok := h.accts.GetAccount(ctx, rm.Target).(*AppAccount)
+	if !ok {
+		return ErrInvalidAccount("account does not exist").Result()
+	}
+	if target.IsClosed() {
+		return ErrInvalidAccount(fmt.Sprintf("%v is closed", rm.Target)).Result()
+	}
+	if !IsClearingHouse(admin) && !BelongToSameEntity(admin, target) {
+		return ErrWrongSigner("admin and account do not belong to the same entity").Result()
+	}
+	if target.PubKey.Equals(rm.PubKey) {
+		return ErrInvalidPubKey("the account already uses this key").Result()
+	}
+	// the key must not be bound to another account
+	if isKeyBound(ctx, h.accts, rm.PubKey, target.Address) {
+		return ErrInvalidPubKey("the key belongs to an existing account").Result()
+	}
+	target.PubKey = rm.PubKey
+	h.accts.SetAccount(ctx, target)
+	return targetTags(RotateKeyType, rm.Admin, target).Result()
+}
+
+// CloseAssetAccountMsgHandler returns the handler's method.
+func CloseAssetAccountMsgHandler(accts sdk.AccountMapper, ledger Ledger) sdk.Handler {
+	return closeAssetAccountMsgHandler{accts, ledger}.Do
+}
+
+type closeAssetAccountMsgHandler struct {
+	accts  sdk.AccountMapper
+	ledger Ledger
+}
+
+// Close asset account's message logic.
+// Admins can close their own entity's asset accounts, clearing house
+// admins any asset account, frozen accounts included. Balances are
+// swept to an active asset account of the same entity. Like in the
+// other transfers only members are limited: a member's negative balance
+// can be swept only within the recipient's credit limits, clearing house
+// and custodian recipients take it whole. Accounts of suspended and
+// closed entities can be closed too, so that the entities can be wound down.
+// Closed accounts are kept so that their address cannot be reused.
+func (h closeAssetAccountMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	cm, ok := msg.(CloseAssetAccountMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected CloseAssetAccountMsg").Result()
+	}
+	admin, err := getActiveUserWithPermission(ctx, h.accts, cm.Admin, PermManageUsers)
+	if err != nil {
+		return err.Result()
+	}
+	asset, err := getOpenAsset(ctx, h.accts, cm.Target)
+	if err != nil {
+		return err.Result()
+	}
+	if !IsClearingHouse(admin) && !BelongToSameEntity(admin, asset) {
+		return ErrWrongSigner("admin and asset account do not belong to the same entity").Result()
+	}
+	tags := targetTags(CloseAssetAccountType, cm.Admin, asset)
+	if !asset.Coins.IsZero() {
+		if len(cm.SweepTo) == 0 {
+			return ErrInvalidAmount(fmt.Sprintf("%v has a non-zero balance", cm.Target)).Result()
+		}
+		rcpt, err := getActiveAsset(ctx, h.accts, cm.SweepTo)
+		if err != nil {
+			return err.Trace("sweep account").Result()
+		}
+		if !BelongToSameEntity(asset, rcpt) {
+			return ErrWrongSigner("sweep account belongs to another entity").Result()
+		}
+		swept := asset.Coins
+		for _, coin := range swept {
+			if err := transferMoney(asset, rcpt, coin, false, IsMember(rcpt)); err != nil {
+				return err.Result()
+			}
+		}
+		h.accts.SetAccount(ctx, rcpt)
+		legs := make([]SettleLeg, len(swept))
+		for i, coin := range swept {
+			legs[i] = SettleLeg{rcpt.Address, coin}
+		}
+		h.ledger.AddTransfers(ctx, CloseAssetAccountType, asset.Address, legs)
+		tags = tags.AppendAccount(TagRecipient, rcpt)
+	}
+	asset.Coins = nil
+	asset.CreditLimits = nil
+	asset.Active = false
+	asset.Closed = true
+	h.accts.SetAccount(ctx, asset)
+	return tags.Result()
+}
+
+// SingleControlHandler wraps a deposit, settlement or withdraw handler
+// so that a single operator can only move amounts up to the dual control
+// thresholds, larger transfers must be proposed and approved.
+func SingleControlHandler(dualControl DualControlMapper, h sdk.Handler) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		amount, ok := transferAmount(msg)
+		if ok && dualControl.GetParams(ctx).RequiresDualControl(amount) {
+			return ErrDualControlRequired(fmt.Sprintf("%d%s is above the threshold", amount.Amount, amount.Denom)).Result()
+		}
+		return h(ctx, msg)
+	}
+}
+
+// checkSingleControl ensures that a single operator can move the amounts of
+// a multi-leg transfer: per currency, their total in absolute value, hence
+// every amount, must not be above the dual control threshold. Such transfers
+// cannot be proposed, larger amounts are moved by proposed settlements.
+func checkSingleControl(params DualControlParams, amounts []sdk.Coin) sdk.Error {
+	var total sdk.Coins
+	for _, amount := range amounts {
+		if amount.Amount < 0 {
+			amount.Amount = -amount.Amount
+		}
+		total = total.Plus(sdk.Coins{amount})
+	}
+	for _, coin := range total {
+		if params.RequiresDualControl(coin) {
+			return ErrDualControlRequired(fmt.Sprintf("%d%s in total is above the threshold", coin.Amount, coin.Denom))
+		}
+	}
+	return nil
+}
+
+// ReferenceHandler wraps a deposit, settlement or withdraw handler.
+// It rejects the transfers whose reference the sender already used,
+// and indexes the transfers that succeed by sender and reference.
+// Transfers without a reference are not checked.
+func ReferenceHandler(refs ReferenceMapper, h sdk.Handler) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		rec, ok := NewTransferRecord(msg)
+		if !ok || len(rec.Reference) == 0 {
+			return h(ctx, msg)
+		}
+		if refs.HasReference(ctx, rec.Sender, rec.Reference) {
+			return ErrDuplicateReference(rec.Reference).Result()
+		}
+		res := h(ctx, msg)
+		if res.IsOK() {
+			rec.Height = ctx.BlockHeight()
+			refs.SetTransfer(ctx, rec)
+		}
+		return res
+	}
+}
+
+// ProposeTransferMsgHandler returns the handler's method.
+func ProposeTransferMsgHandler(accts sdk.AccountMapper, dualControl DualControlMapper, refs ReferenceMapper) sdk.Handler {
+	return proposeTransferMsgHandler{accts, dualControl, refs}.Do
+}
+
+type proposeTransferMsgHandler struct {
+	accts       sdk.AccountMapper
+	dualControl DualControlMapper
+	refs        ReferenceMapper
+}
+
+// Propose transfer logic.
+// Clearing house operators propose transfers, the transfer's
+// own rules are enforced when it is approved.
+// The result's data holds the new pending transfer's ID.
+func (h proposeTransferMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	pm, ok := msg.(ProposeTransferMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected ProposeTransferMsg").Result()
+	}
+	// ensure proper types
+	operator, err := getCHActiveUserWithPermission(ctx, h.accts, pm.Operator, transferPermissions[pm.TransferType])
+	if err != nil {
+		return err.Result()
+	}
+	sender, err := getActiveAsset(ctx, h.accts, pm.Sender)
+	if err != nil {
+		return err.Result()
+	}
+	rcpt, err := getActiveAsset(ctx, h.accts, pm.Recipient)
+	if err != nil {
+		return err.Result()
+	}
+	if len(pm.Reference) != 0 && h.refs.HasReference(ctx, pm.Sender, pm.Reference) {
+		return ErrDuplicateReference(pm.Reference).Result()
+	}
+	pt := h.dualControl.ProposeTransfer(ctx, pm)
+	id := strconv.FormatInt(pt.ID, 10)
+	res := transferTags(ProposeTransferType, operator, sender, rcpt, pm.Amount, pm.Reference).
+		Append(TagTransferType, pm.TransferType).
+		Append(TagTransferID, id).
+		Result()
+	res.Data = []byte(id)
+	return res
+}
+
+// ApproveTransferMsgHandler returns the handler's method.
+func ApproveTransferMsgHandler(accts sdk.AccountMapper, dualControl DualControlMapper,
+	refs ReferenceMapper, ledger Ledger) sdk.Handler {
+	return approveTransferMsgHandler{
+		accts:       accts,
+		dualControl: dualControl,
+		transfers: map[string]sdk.Handler{
+			DepositType:    ReferenceHandler(refs, DepositMsgHandler(accts, ledger)),
+			SettlementType: ReferenceHandler(refs, SettleMsgHandler(accts, ledger)),
+			WithdrawType:   ReferenceHandler(refs, WithdrawMsgHandler(accts, ledger)),
+		},
+	}.Do
+}
+
+type approveTransferMsgHandler struct {
+	accts       sdk.AccountMapper
+	dualControl DualControlMapper
+	transfers   map[string]sdk.Handler
+}
+
+// Approve transfer logic.
+// A second user of the proposer's entity holding the permission the
+// transfer requires approves it, it is then executed as if the proposer
+// had sent it:
+// the proposer's operator limits apply.
+// Transfers that fail remain pending.
+func (h approveTransferMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	am, ok := msg.(ApproveTransferMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected ApproveTransferMsg").Result()
+	}
+	pt, err := validateCheckerAndGetTransfer(ctx, h.accts, h.dualControl, am.BaseCheckTransferMsg, false)
+	if err != nil {
+		return err.Result()
+	}
+	transfer, err := pt.Proposal.TransferMsg()
+	if err != nil {
+		return err.Result()
+	}
+	res := h.transfers[pt.Proposal.TransferType](ctx, transfer)
+	if !res.IsOK() {
+		return res
+	}
+	pt.Status = TransferApproved
+	pt.Checker = am.Checker
+	h.dualControl.CloseTransfer(ctx, pt)
+	return append(checkTransferTags(ApproveTransferType, pt), res.Tags...).Result()
+}
+
+// RejectTransferMsgHandler returns the handler's method.
+func RejectTransferMsgHandler(accts sdk.AccountMapper, dualControl DualControlMapper) sdk.Handler {
+	return rejectTransferMsgHandler{accts, dualControl}.Do
+}
+
+type rejectTransferMsgHandler struct {
+	accts       sdk.AccountMapper
+	dualControl DualControlMapper
+}
+
+// Reject transfer logic.
+// Any user of the proposer's entity holding the permission the
+// transfer requires, the proposer included, can reject it.
+func (h rejectTransferMsgHandler) Do(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+	// ensure proper message
+	rm, ok := msg.(RejectTransferMsg)
+	if !ok {
+		return ErrWrongMsgFormat("expected RejectTransferMsg").Result()
+	}
+	pt, err := validateCheckerAndGetTransfer(ctx, h.accts, h.dualControl, rm.BaseCheckTransferMsg, true)
+	if err != nil {
+		return err.Result()
+	}
+	pt.Status = TransferRejected
+	pt.Checker = rm.Checker
+	h.dualControl.CloseTransfer(ctx, pt)
+	return checkTransferTags(RejectTransferType, pt).Result()
 }
 
 // Business logic
 
 func validateAdminAndCreateOperator(ctx sdk.Context, accts sdk.AccountMapper,
 	creatorAddr crypto.Address, pub crypto.PubKey) (*AppAccount, sdk.Error) {
-	creator, err := getActiveAdmin(ctx, accts, creatorAddr)
+	creator, err := getActiveUserWithPermission(ctx, accts, creatorAddr, PermManageUsers)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkEntityActive(ctx, accts, creator); err != nil {
+		return nil, err
+	}
 	// ensure new account does not exist
-	if accts.GetAccount(ctx, pub.Address()) != nil {
+	if isKeyBound(ctx, accts, pub, nil) {
 		return nil, ErrInvalidAccount("couldn't create the account, it already exists")
 	}
 	return NewOpUser(pub, creator.GetAddress(), creator.LegalEntityName(), creator.LegalEntityType()), nil
@@ -295,59 +1213,212 @@ func validateAdminAndCreateOperator(ctx sdk.Context, accts sdk.AccountMapper,
 
 func validateCHAdminAndCreateXEntityAdmin(ctx sdk.Context, accts sdk.AccountMapper,
 	creatorAddr crypto.Address, pub crypto.PubKey, ent LegalEntity) (*AppAccount, sdk.Error) {
-	if _, err := getCHActiveAdmin(ctx, accts, creatorAddr); err != nil {
+	if _, err := getCHActiveUserWithPermission(ctx, accts, creatorAddr, PermManageUsers); err != nil {
 		return nil, err
 	}
 	// ensure new account does not exist
-	if accts.GetAccount(ctx, pub.Address()) != nil {
+	if isKeyBound(ctx, accts, pub, nil) {
 		return nil, ErrInvalidAccount("couldn't create the account, it already exists")
 	}
 	return NewAdminUser(pub, creatorAddr, ent.LegalEntityName(), ent.LegalEntityType()), nil
 }
 
+// isKeyBound returns true if an account other than owner is at the key's
+// address or was bound to the key, by creation or by a key rotation.
+func isKeyBound(ctx sdk.Context, accts sdk.AccountMapper, pub crypto.PubKey, owner crypto.Address) bool {
+	if acc := accts.GetAccount(ctx, pub.Address()); acc != nil && !bytes.Equal(acc.GetAddress(), owner) {
+		return true
+	}
+	index, ok := accts.(IndexedAccountMapper)
+	if !ok {
+		return false
+	}
+	addr, found := index.GetKeyAccount(ctx, pub.Address())
+	return found && !bytes.Equal(addr, owner)
+}
+
+// validateAdminAndGetAsset ensures that the admin is entitled
+// to change the state of the target asset account.
+func validateAdminAndGetAsset(ctx sdk.Context, accts sdk.AccountMapper,
+	adminAddr, assetAddr crypto.Address, wantActive bool) (*AppAccount, sdk.Error) {
+	admin, err := getActiveUserWithPermission(ctx, accts, adminAddr, PermManageUsers)
+	if err != nil {
+		return nil, err
+	}
+	asset, err := getAsset(ctx, accts, assetAddr, wantActive)
+	if err != nil {
+		return nil, err
+	}
+	if !IsClearingHouse(admin) && !BelongToSameEntity(admin, asset) {
+		return nil, ErrWrongSigner("admin and asset account do not belong to the same entity")
+	}
+	return asset, nil
+}
+
+// validateAdminAndGetUser ensures that the admin is entitled
+// to change the roles of the target user.
+func validateAdminAndGetUser(ctx sdk.Context, accts sdk.AccountMapper,
+	adminAddr, userAddr crypto.Address) (*AppAccount, sdk.Error) {
+	admin, err := getActiveUserWithPermission(ctx, accts, adminAddr, PermManageUsers)
+	if err != nil {
+		return nil, err
+	}
+	user, err := getActiveUser(ctx, accts, userAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !BelongToSameEntity(admin, user) {
+		return nil, ErrWrongSigner("admin and user do not belong to the same entity")
+	}
+	return user, nil
+}
+
+// validateCheckerAndGetTransfer ensures that the checker is entitled
+// to approve or reject the pending transfer and returns the transfer.
+// Checkers hold the transfer's permission or are admins of the proposer's entity.
+func validateCheckerAndGetTransfer(ctx sdk.Context, accts sdk.AccountMapper, dualControl DualControlMapper,
+	cm BaseCheckTransferMsg, proposerMayCheck bool) (PendingTransfer, sdk.Error) {
+	pt, found := dualControl.GetTransfer(ctx, cm.ID)
+	if !found {
+		return pt, ErrInvalidTransfer(fmt.Sprintf("%d does not exist", cm.ID))
+	}
+	if pt.Status != TransferPending {
+		return pt, ErrInvalidTransfer(fmt.Sprintf("%d is %s", cm.ID, pt.Status))
+	}
+	if ctx.BlockHeight() > pt.ExpiresAt {
+		return pt, ErrInvalidTransfer(fmt.Sprintf("%d expired at height %d", cm.ID, pt.ExpiresAt))
+	}
+	checker, err := getActiveUser(ctx, accts, cm.Checker)
+	if err != nil {
+		return pt, err
+	}
+	perm := transferPermissions[pt.Proposal.TransferType]
+	if !checker.HasPermission(perm) && !checker.HasPermission(PermManageUsers) {
+		return pt, ErrWrongSigner(fmt.Sprintf("%v lacks the %s permission", cm.Checker, perm))
+	}
+	proposer, ok := accts.GetAccount(ctx, pt.Proposal.Operator).(*AppAccount)
+	if !ok || !BelongToSameEntity(proposer, checker) {
+		return pt, ErrWrongSigner("checker and proposer do not belong to the same entity")
+	}
+	if !proposerMayCheck && bytes.Equal(checker.Address, proposer.Address) {
+		return pt, ErrWrongSigner("the proposer cannot approve its own transfer")
+	}
+	return pt, nil
+}
+
+// transferTags returns the tags of a deposit, settlement or withdraw.
+func transferTags(action string, operator, sender, rcpt *AppAccount, amount sdk.Coin, reference string) Tags {
+	return NewTags(action).
+		AppendAccount(TagOperator, operator).
+		AppendAccount(TagSender, sender).
+		AppendAccount(TagRecipient, rcpt).
+		AppendCoin(amount).
+		AppendReference(reference)
+}
+
+// targetTags returns the tags of a message that creates or changes an account.
+func targetTags(action string, admin sdk.Address, target *AppAccount) Tags {
+	return NewTags(action).AppendAddress(TagAdmin, admin).AppendAccount(TagTarget, target)
+}
+
+// checkTransferTags returns the tags of a message that approves or rejects a transfer.
+func checkTransferTags(action string, pt PendingTransfer) Tags {
+	return NewTags(action).
+		AppendAddress(TagChecker, pt.Checker).
+		Append(TagTransferType, pt.Proposal.TransferType).
+		Append(TagTransferID, strconv.FormatInt(pt.ID, 10))
+}
+
+// transferPermissions maps the transfer types to the permission their signers need.
+var transferPermissions = map[string]string{
+	DepositType:    PermDeposit,
+	SettlementType: PermSettle,
+	WithdrawType:   PermWithdraw,
+}
+
+// transferAmount returns the amount of deposits, settlements and withdrawals.
+func transferAmount(msg sdk.Msg) (sdk.Coin, bool) {
+	switch m := msg.(type) {
+	case DepositMsg:
+		return m.Amount, true
+	case SettleMsg:
+		return m.Amount, true
+	case WithdrawMsg:
+		return m.Amount, true
+	}
+	return sdk.Coin{}, false
+}
+
 // Transfers money from the sender to the  recipient
 func moveMoney(accts sdk.AccountMapper, ctx sdk.Context, sender *AppAccount, recipient *AppAccount,
-	amount sdk.Coin, senderMustBePositive bool, recipientMustBePositive bool) sdk.Error {
+	amount sdk.Coin, senderIsLimited bool, recipientIsLimited bool) sdk.Error {
+	if err := transferMoney(sender, recipient, amount, senderIsLimited, recipientIsLimited); err != nil {
+		return err
+	}
+	// now make the transfer and save the result
+	accts.SetAccount(ctx, sender)
+	accts.SetAccount(ctx, recipient)
+	return nil
+}
+
+// Updates sender's and recipient's balances without saving them.
+// Limited accounts cannot be debited below their credit limits,
+// they can always be credited, e.g. repaid after a limit is lowered.
+func transferMoney(sender *AppAccount, recipient *AppAccount,
+	amount sdk.Coin, senderIsLimited bool, recipientIsLimited bool) sdk.Error {
 	transfer := sdk.Coins{amount}
 	// first verify funds
 	sender.Coins = sender.Coins.Minus(transfer)
-	if senderMustBePositive && !sender.Coins.IsNotNegative() {
-		return ErrInvalidAmount("sender  has insufficient funds")
+	if senderIsLimited && amount.Amount > 0 {
+		if err := checkCreditLimit(sender, amount.Denom); err != nil {
+			return err.Trace("sender")
+		}
 	}
-	// transfer may be negative
+	// transfer may be negative, debiting the recipient
 	recipient.Coins = recipient.Coins.Plus(transfer)
-	if recipientMustBePositive && !recipient.Coins.IsNotNegative() {
-		return ErrInvalidAmount("recipient has insufficient funds")
+	if recipientIsLimited && amount.Amount < 0 {
+		if err := checkCreditLimit(recipient, amount.Denom); err != nil {
+			return err.Trace("recipient")
+		}
 	}
-	// now make the transfer and save the result
-	accts.SetAccount(ctx, sender)
-	accts.SetAccount(ctx, recipient)
 	return nil
 }
 
-// Auxiliary functions
-
-func getCHActiveAdmin(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address) (*AppAccount, sdk.Error) {
-	return getUserAccountWithGetterAndEntityType(ctx, accts, addr, getActiveAdmin, IsClearingHouse)
+// Checks the account's balance against its credit limit.
+// Accounts without a credit limit cannot go below zero.
+func checkCreditLimit(account *AppAccount, denom string) sdk.Error {
+	balance := account.Coins.AmountOf(denom)
+	if balance >= 0 {
+		return nil
+	}
+	limit := account.GetCreditLimit(denom)
+	if limit == 0 {
+		return ErrInvalidAmount(fmt.Sprintf("%v has insufficient funds", account.Address))
+	}
+	if -balance > limit {
+		return ErrCreditLimitExceeded(fmt.Sprintf("%v exceeds %d%s", account.Address, limit, denom))
+	}
+	return nil
 }
 
-func getCHActiveOperator(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address) (*AppAccount, sdk.Error) {
-	return getUserAccountWithGetterAndEntityType(ctx, accts, addr, getActiveOperator, IsClearingHouse)
-}
+// Auxiliary functions
 
-func getUserAccountWithGetterAndEntityType(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address,
-	accGetter func(sdk.Context, sdk.AccountMapper, crypto.Address) (*AppAccount, sdk.Error),
-	entityTypeCheck func(LegalEntity) bool) (*AppAccount, sdk.Error) {
-	account, err := accGetter(ctx, accts, addr)
+// getCHActiveUserWithPermission returns an active clearing
+// house user whose roles grant the permission.
+func getCHActiveUserWithPermission(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address,
+	perm string) (*AppAccount, sdk.Error) {
+	account, err := getActiveUserWithPermission(ctx, accts, addr, perm)
 	if err != nil {
 		return nil, err
 	}
-	if !entityTypeCheck(account) {
+	if !IsClearingHouse(account) {
 		return nil, ErrWrongSigner(account.LegalEntityType())
 	}
 	return account, nil
 }
 
+// getActiveAssetWithEntityType returns an active asset account
+// of an entity of the given type that is not suspended or closed.
 func getActiveAssetWithEntityType(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address,
 	entityTypeCheck func(LegalEntity) bool) (*AppAccount, sdk.Error) {
 	account, err := getActiveAsset(ctx, accts, addr)
@@ -357,6 +1428,54 @@ func getActiveAssetWithEntityType(ctx sdk.Context, accts sdk.AccountMapper, addr
 	if !entityTypeCheck(account) {
 		return nil, ErrWrongSigner(account.LegalEntityType())
 	}
+	if err := checkEntityActive(ctx, accts, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// checkEntityActive ensures that the account's legal entity, if
+// registered, is active. Suspended and closed entities can neither
+// move money nor be given new accounts, entities that were never
+// registered are not restricted.
+func checkEntityActive(ctx sdk.Context, accts sdk.AccountMapper, account LegalEntity) sdk.Error {
+	index, ok := accts.(IndexedAccountMapper)
+	if !ok {
+		return nil
+	}
+	entity, found := index.entities.GetEntityByName(ctx, account.LegalEntityName())
+	if found && !entity.IsActive() {
+		return ErrInvalidLegalEntity(fmt.Sprintf("%q is %s", entity.ID, entity.Status))
+	}
+	return nil
+}
+
+// getActiveUser returns an active admin or operator.
+func getActiveUser(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address) (*AppAccount, sdk.Error) {
+	rawAccount := accts.GetAccount(ctx, addr)
+	if rawAccount == nil {
+		return nil, ErrInvalidAccount("account does not exist")
+	}
+	account := rawAccount.(*AppAccount)
+	if !account.IsUser() {
+		return nil, ErrWrongSigner("invalid account type")
+	}
+	if !account.Active {
+		return nil, ErrInactiveUser(fmt.Sprintf("%v", addr))
+	}
+	return account, nil
+}
+
+// getActiveUserWithPermission returns an active user whose roles grant the permission.
+func getActiveUserWithPermission(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address,
+	perm string) (*AppAccount, sdk.Error) {
+	account, err := getActiveUser(ctx, accts, addr)
+	if err != nil {
+		return nil, err
+	}
+	if !account.HasPermission(perm) {
+		return nil, ErrWrongSigner(fmt.Sprintf("%v lacks the %s permission", addr, perm))
+	}
 	return account, nil
 }
 
@@ -368,6 +1487,14 @@ func getActiveOperator(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Add
 	return getUser(ctx, accts, addr, true, false)
 }
 
+func getInactiveAdmin(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address) (*AppAccount, sdk.Error) {
+	return getUser(ctx, accts, addr, false, true)
+}
+
+func getInactiveOperator(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address) (*AppAccount, sdk.Error) {
+	return getUser(ctx, accts, addr, false, false)
+}
+
 func getUser(ctx sdk.Context, accts sdk.AccountMapper,
 	addr crypto.Address, wantActive, wantAdmin bool) (*AppAccount, sdk.Error) {
 	rawAccount := accts.GetAccount(ctx, addr)
@@ -382,7 +1509,7 @@ func getUser(ctx sdk.Context, accts sdk.AccountMapper,
 		return nil, ErrInactiveUser(fmt.Sprintf("%v", addr))
 	}
 	if !wantActive && account.Active {
-		return nil, ErrInactiveUser(fmt.Sprintf("%v", addr))
+		return nil, ErrInvalidAccount(fmt.Sprintf("%v is active", addr))
 	}
 	if wantAdmin && !account.IsAdmin() {
 		return nil, ErrWrongSigner("must be admin")
@@ -398,6 +1525,21 @@ func getActiveAsset(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Addres
 }
 
 func getAsset(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address, wantActive bool) (*AppAccount, sdk.Error) {
+	account, err := getOpenAsset(ctx, accts, addr)
+	if err != nil {
+		return nil, err
+	}
+	if wantActive && !account.Active {
+		return nil, ErrInactiveUser(fmt.Sprintf("%v", addr))
+	}
+	if !wantActive && account.Active {
+		return nil, ErrInvalidAccount(fmt.Sprintf("%v is active", addr))
+	}
+	return account, nil
+}
+
+// getOpenAsset returns an asset account that is not closed, whether active or not.
+func getOpenAsset(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address) (*AppAccount, sdk.Error) {
 	rawAccount := accts.GetAccount(ctx, addr)
 	if rawAccount == nil {
 		return nil, ErrInvalidAccount("account does not exist")
@@ -406,11 +1548,8 @@ func getAsset(ctx sdk.Context, accts sdk.AccountMapper, addr crypto.Address, wan
 	if !account.IsAsset() {
 		return nil, ErrWrongSigner("invalid account type")
 	}
-	if wantActive && !account.Active {
-		return nil, ErrInactiveUser(fmt.Sprintf("%v", addr))
-	}
-	if !wantActive && account.Active {
-		return nil, ErrInactiveUser(fmt.Sprintf("%v", addr))
+	if account.IsClosed() {
+		return nil, ErrInvalidAccount(fmt.Sprintf("%v is closed", addr))
 	}
 	return account, nil
 }
@@ -9,13 +9,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	abci "github.com/tendermint/abci/types"
 	crypto "github.com/tendermint/go-crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
+	cmn "github.com/tendermint/tmlibs/common"
 	dbm "github.com/tendermint/tmlibs/db"
 )
 
 // TestRegisterRoutes is an end-to-end test, making sure a normal workflow is
 // supported and passing all messages through the router to simulate production code path
 func TestRegisterRoutes(t *testing.T) {
-	accts, ctx := fakeAccountMapper()
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
 
 	_, chOpPriv := fakeUser(accts, ctx, EntityClearingHouse)
 	op := chOpPriv.PubKey().Address()
@@ -25,7 +28,8 @@ func TestRegisterRoutes(t *testing.T) {
 	_, member2 := fakeAsset(accts, ctx, nil, EntityGeneralClearingMember)
 
 	router := baseapp.NewRouter()
-	RegisterRoutes(router, accts)
+	RegisterRoutes(router, accts, NewEntityMapper(key), NewNettingMapper(key), NewDualControlMapper(key, NewParamsMapper(key)),
+		NewReferenceMapper(key), NewLedger(NewJournalMapper(key), NewHistoryMapper(key)))
 
 	type args struct {
 		ctx sdk.Context
@@ -103,7 +107,9 @@ func TestRegisterRoutes(t *testing.T) {
 	}
 }
 func Test_depositMsgHandler_Do(t *testing.T) {
-	accts, ctx := fakeAccountMapper()
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
 	cCoins := sdk.Coins{{"EUR", 5000}, {"USD", 1000}}
 	mCoins := sdk.Coins{}
 
@@ -156,7 +162,7 @@ func Test_depositMsgHandler_Do(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := DepositMsgHandler(accts)
+			handler := DepositMsgHandler(accts, ledger)
 			got := handler(tt.args.ctx, tt.args.msg)
 			assert.Equal(t, tt.expect, got.Code, got.Log)
 
@@ -167,10 +173,19 @@ func Test_depositMsgHandler_Do(t *testing.T) {
 			assert.Equal(t, tt.mBal, m.GetCoins())
 		})
 	}
+	hash := tmtypes.Tx(ctx.TxBytes()).Hash()
+	postings := ledger.History.GetPostings(ctx, member, 1, DefaultHistoryLimit).Postings
+	assert.Equal(t, 2, len(postings))
+	assert.Equal(t, Posting{member, cust, sdk.Coin{"EUR", 10000}, DepositType, hash, ctx.BlockHeight()}, postings[0])
+	assert.Equal(t, Posting{cust, member, sdk.Coin{"USD", -700}, DepositType, hash, ctx.BlockHeight()},
+		ledger.History.GetPostings(ctx, cust, 2, 1).Postings[0])
+	assert.Nil(t, ledger.Journal.VerifyBalance(ctx, accts.GetAccount(ctx, member).(*AppAccount)))
 }
 
 func Test_settleMsgHandler_Do(t *testing.T) {
-	accts, ctx := fakeAccountMapper()
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
 	clhCoins := sdk.Coins{{"EUR", 5000}, {"USD", 1000}}
 	mCoins := sdk.Coins{{"USD", 1000}}
 
@@ -222,7 +237,7 @@ func Test_settleMsgHandler_Do(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := SettleMsgHandler(accts)
+			handler := SettleMsgHandler(accts, ledger)
 			got := handler(tt.args.ctx, tt.args.msg)
 			assert.Equal(t, tt.expect, got.Code, got.Log)
 
@@ -235,8 +250,865 @@ func Test_settleMsgHandler_Do(t *testing.T) {
 	}
 }
 
-func Test_withdrawMsgHandler_Do(t *testing.T) {
+func Test_batchSettleMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	dualControl := NewDualControlMapper(key, NewParamsMapper(key))
+	dualControl.SetParams(ctx, DualControlParams{ExpiryBlocks: 10, Thresholds: sdk.Coins{{"USD", 1500}}})
+	clhCoins := sdk.Coins{{"EUR", 5000}, {"USD", 1000}}
+	m1Coins := sdk.Coins{{"USD", 1000}}
+
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, clhCoins, chOpAcc.LegalEntityType(), EntityClearingHouse)
+	_, member1 := fakeAssetWithEntityName(accts, ctx, m1Coins, "ICM", EntityIndividualClearingMember)
+	_, member2 := fakeAssetWithEntityName(accts, ctx, nil, "GCM", EntityGeneralClearingMember)
+	_, inactiveMember := fakeInactiveAssetWithEntityName(accts, ctx, nil, "ICM", EntityIndividualClearingMember)
+	_, cust := fakeAsset(accts, ctx, nil, EntityCustodian)
+
+	tests := []struct {
+		name   string
+		legs   []SettleLeg
+		expect sdk.CodeType
+		cBal   sdk.Coins
+		m1Bal  sdk.Coins
+		m2Bal  sdk.Coins
+	}{
+		{
+			"inactive recipient in the last leg",
+			[]SettleLeg{{member1, sdk.Coin{"USD", 500}}, {inactiveMember, sdk.Coin{"USD", 500}}},
+			CodeInactiveAccount, clhCoins, m1Coins, sdk.Coins{},
+		},
+		{
+			"custodian recipient",
+			[]SettleLeg{{member1, sdk.Coin{"USD", 500}}, {cust, sdk.Coin{"USD", 500}}},
+			CodeWrongSigner, clhCoins, m1Coins, sdk.Coins{},
+		},
+		{
+			"member would go negative",
+			[]SettleLeg{{member2, sdk.Coin{"EUR", 500}}, {member1, sdk.Coin{"USD", -1500}}},
+			CodeInvalidAmount, clhCoins, m1Coins, sdk.Coins{},
+		},
+		{
+			"single leg above the dual control threshold",
+			[]SettleLeg{{member1, sdk.Coin{"USD", -1600}}},
+			CodeDualControl, clhCoins, m1Coins, sdk.Coins{},
+		},
+		{
+			"total above the dual control threshold",
+			[]SettleLeg{{member1, sdk.Coin{"USD", -1000}}, {member2, sdk.Coin{"USD", 600}}},
+			CodeDualControl, clhCoins, m1Coins, sdk.Coins{},
+		},
+		{
+			"good batch",
+			[]SettleLeg{{member2, sdk.Coin{"EUR", 500}}, {member1, sdk.Coin{"USD", -1000}}, {member2, sdk.Coin{"USD", 300}}},
+			sdk.CodeOK, sdk.Coins{{"EUR", 4500}, {"USD", 1700}}, sdk.Coins{}, sdk.Coins{{"EUR", 500}, {"USD", 300}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := BatchSettleMsgHandler(accts, dualControl, ledger)
+			got := handler(ctx, NewBatchSettleMsg(chOp, clh, tt.legs))
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+
+			c := accts.GetAccount(ctx, clh)
+			assert.Equal(t, tt.cBal, c.GetCoins())
+
+			m1 := accts.GetAccount(ctx, member1)
+			assert.Equal(t, tt.m1Bal, m1.GetCoins())
+
+			m2 := accts.GetAccount(ctx, member2)
+			assert.Equal(t, tt.m2Bal, m2.GetCoins())
+		})
+	}
+	// the legs are journaled as one entry
+	entry, found := ledger.Journal.GetEntry(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, BatchSettlementType, entry.MsgType)
+	assert.Equal(t, 6, len(entry.Lines))
+	_, found = ledger.Journal.GetEntry(ctx, 2)
+	assert.False(t, found)
+}
+
+func Test_submitObligationMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	netting := NewNettingMapper(key)
+
+	chAdmAcc, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chAdm := chAdmAcc.Address
+	chOp := chOpAcc.Address
+	_, member1 := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	_, member2 := fakeAsset(accts, ctx, nil, EntityGeneralClearingMember)
+	_, cust := fakeAsset(accts, ctx, nil, EntityCustodian)
+	_, inactiveMember := fakeInactiveAssetWithEntityName(accts, ctx, nil, "ICM", EntityIndividualClearingMember)
+	usd := sdk.Coin{"USD", 500}
+
+	tests := []struct {
+		name   string
+		msg    SubmitObligationMsg
+		expect sdk.CodeType
+	}{
+		{"admins cannot submit", NewSubmitObligationMsg(chAdm, member1, member2, usd), CodeWrongSigner},
+		{"custodians aren't members", NewSubmitObligationMsg(chOp, cust, member2, usd), CodeWrongSigner},
+		{"inactive creditor", NewSubmitObligationMsg(chOp, member1, inactiveMember, usd), CodeInactiveAccount},
+		{"ok", NewSubmitObligationMsg(chOp, member1, member2, usd), sdk.CodeOK},
+		{"ok reverse", NewSubmitObligationMsg(chOp, member2, member1, usd), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SubmitObligationMsgHandler(accts, netting)
+			got := handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	obligations := netting.GetObligations(ctx, netting.GetCurrentCycle(ctx))
+	assert.Equal(t, 2, len(obligations))
+}
+
+func Test_netSettlementMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	netting := NewNettingMapper(key)
+	dualControl := NewDualControlMapper(key, NewParamsMapper(key))
+	dualControl.SetParams(ctx, DualControlParams{ExpiryBlocks: 10, Thresholds: sdk.Coins{{"USD", 1000}}})
+	clhCoins := sdk.Coins{{"USD", 1000}}
+
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, clhCoins, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	_, foreignClh := fakeAssetWithEntityName(accts, ctx, clhCoins, "another CH", EntityClearingHouse)
+	_, m1 := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 100}}, "m1", EntityIndividualClearingMember)
+	_, m2 := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"EUR", 100}, {"USD", 100}}, "m2", EntityGeneralClearingMember)
+	_, m3 := fakeAssetWithEntityName(accts, ctx, sdk.Coins{}, "m3", EntityGeneralClearingMember)
+
+	settle := NetSettlementMsgHandler(accts, netting, dualControl, ledger)
+	// nothing to net yet
+	got := settle(ctx, NewNetSettlementMsg(chOp, clh))
+	assert.Equal(t, CodeInvalidAmount, got.Code, got.Log)
+
+	// m1 owes m2 300 USD, m2 owes m3 100 USD and 50 EUR, m3 owes m1 50 EUR
+	netting.AddObligation(ctx, Obligation{m1, m2, sdk.Coin{"USD", 300}})
+	netting.AddObligation(ctx, Obligation{m2, m3, sdk.Coin{"USD", 100}})
+	netting.AddObligation(ctx, Obligation{m2, m3, sdk.Coin{"EUR", 50}})
+	netting.AddObligation(ctx, Obligation{m3, m1, sdk.Coin{"EUR", 50}})
+
+	// m1 can't cover its net position
+	got = settle(ctx, NewNetSettlementMsg(chOp, clh))
+	assert.Equal(t, CodeInvalidAmount, got.Code, got.Log)
+	assert.Equal(t, clhCoins, accts.GetAccount(ctx, clh).GetCoins())
+	assert.Equal(t, int64(1), netting.GetCurrentCycle(ctx))
+
+	// net positions above the dual control threshold
+	netting.AddObligation(ctx, Obligation{m2, m1, sdk.Coin{"USD", 1000}})
+	got = settle(ctx, NewNetSettlementMsg(chOp, clh))
+	assert.Equal(t, CodeDualControl, got.Code, got.Log)
+	assert.Equal(t, int64(1), netting.GetCurrentCycle(ctx))
+	netting.AddObligation(ctx, Obligation{m1, m2, sdk.Coin{"USD", 1000}})
+
+	// foreign clearing house account
+	got = settle(ctx, NewNetSettlementMsg(chOp, foreignClh))
+	assert.Equal(t, CodeWrongSigner, got.Code, got.Log)
+
+	netting.AddObligation(ctx, Obligation{m2, m1, sdk.Coin{"USD", 250}})
+	got = settle(ctx, NewNetSettlementMsg(chOp, clh))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, clhCoins, accts.GetAccount(ctx, clh).GetCoins())
+	assert.Equal(t, sdk.Coins{{"EUR", 50}, {"USD", 50}}, accts.GetAccount(ctx, m1).GetCoins())
+	assert.Equal(t, sdk.Coins{{"EUR", 50}, {"USD", 50}}, accts.GetAccount(ctx, m2).GetCoins())
+	assert.Equal(t, sdk.Coins{{"USD", 100}}, accts.GetAccount(ctx, m3).GetCoins())
+	entry, found := ledger.Journal.GetEntry(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, NetSettlementType, entry.MsgType)
+	_, found = ledger.Journal.GetEntry(ctx, 2)
+	assert.False(t, found)
+
+	// the result is stored and a new cycle is open
+	res, found := netting.GetNettingResult(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, int64(1), res.Cycle)
+	assert.Equal(t, 3, len(res.Positions))
+	assert.Equal(t, int64(2), netting.GetCurrentCycle(ctx))
+	assert.Equal(t, 0, len(netting.GetObligations(ctx, 2)))
+}
+
+func Test_cancelNettingCycleMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	netting := NewNettingMapper(key)
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	chAdmAcc, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	icmOpAcc, _ := fakeUser(accts, ctx, EntityIndividualClearingMember)
+	_, m1 := fakeAssetWithEntityName(accts, ctx, sdk.Coins{}, "m1", EntityIndividualClearingMember)
+	_, m2 := fakeAssetWithEntityName(accts, ctx, sdk.Coins{}, "m2", EntityGeneralClearingMember)
+	cancel := CancelNettingCycleMsgHandler(accts, netting)
+
+	// nothing to cancel yet
+	got := cancel(ctx, NewCancelNettingCycleMsg(chOp, 1))
+	assert.Equal(t, CodeInvalidCycle, got.Code, got.Log)
+
+	netting.AddObligation(ctx, Obligation{m1, m2, sdk.Coin{"USD", 300}})
+	tests := []struct {
+		name   string
+		msg    CancelNettingCycleMsg
+		expect sdk.CodeType
+	}{
+		{"member operators cannot cancel", NewCancelNettingCycleMsg(icmOpAcc.Address, 1), CodeWrongSigner},
+		{"admins cannot cancel", NewCancelNettingCycleMsg(chAdmAcc.Address, 1), CodeWrongSigner},
+		{"not the current cycle", NewCancelNettingCycleMsg(chOp, 2), CodeInvalidCycle},
+		{"ok", NewCancelNettingCycleMsg(chOp, 1), sdk.CodeOK},
+		{"already cancelled", NewCancelNettingCycleMsg(chOp, 1), CodeInvalidCycle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cancel(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	assert.Equal(t, int64(2), netting.GetCurrentCycle(ctx))
+	assert.Equal(t, 0, len(netting.GetObligations(ctx, 1)))
+	_, found := netting.GetNettingResult(ctx, 1)
+	assert.False(t, found)
+	assert.Equal(t, sdk.Coins{}, accts.GetAccount(ctx, m1).GetCoins())
+}
+
+func Test_setCreditLimitMsgHandler_Do(t *testing.T) {
 	accts, ctx := fakeAccountMapper()
+	chAdmAcc, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdm := chAdmAcc.Address
+	_, icmAdm := fakeAdmin(accts, ctx, EntityIndividualClearingMember)
+	_, clh := fakeAsset(accts, ctx, nil, EntityClearingHouse)
+	_, member := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	_, inactiveMember := fakeInactiveAssetWithEntityName(accts, ctx, nil, "ICM", EntityIndividualClearingMember)
+	_, cust := fakeAsset(accts, ctx, nil, EntityCustodian)
+	limit := sdk.Coin{"USD", 500}
+
+	tests := []struct {
+		name   string
+		msg    SetCreditLimitMsg
+		expect sdk.CodeType
+	}{
+		{"member admins cannot set limits", NewSetCreditLimitMsg(icmAdm.PubKey().Address(), member, limit), CodeWrongSigner},
+		{"clearing house account", NewSetCreditLimitMsg(chAdm, clh, limit), CodeInvalidAccount},
+		{"custodian account", NewSetCreditLimitMsg(chAdm, cust, limit), CodeInvalidAccount},
+		{"inactive account", NewSetCreditLimitMsg(chAdm, inactiveMember, limit), CodeInactiveAccount},
+		{"not an asset account", NewSetCreditLimitMsg(chAdm, chAdm, limit), CodeWrongSigner},
+		{"ok", NewSetCreditLimitMsg(chAdm, member, limit), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SetCreditLimitMsgHandler(accts)
+			got := handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	acct := accts.GetAccount(ctx, member).(*AppAccount)
+	assert.Equal(t, sdk.Coins{limit}, acct.CreditLimits)
+}
+
+func Test_creditLimitEnforcement(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 1000}}, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	memberAcc, _ := makeAssetAccount(sdk.Coins{{"USD", 100}}, "ICM", EntityIndividualClearingMember)
+	memberAcc.SetCreditLimit(sdk.Coin{"USD", 400})
+	accts.SetAccount(ctx, memberAcc)
+	member := memberAcc.Address
+	settle := SettleMsgHandler(accts, ledger)
+
+	// a settlement beyond the credit limit fails
+	got := settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", -600}))
+	assert.Equal(t, CodeCreditLimit, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", 100}}, accts.GetAccount(ctx, member).GetCoins())
+
+	// a settlement within the credit limit succeeds
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", -500}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", -400}}, accts.GetAccount(ctx, member).GetCoins())
+
+	// no credit limit in other currencies
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"EUR", -1}))
+	assert.Equal(t, CodeInvalidAmount, got.Code, got.Log)
+
+	// once the limit is lowered, the account can be repaid but not debited further
+	memberAcc = accts.GetAccount(ctx, member).(*AppAccount)
+	memberAcc.SetCreditLimit(sdk.Coin{"USD", 100})
+	accts.SetAccount(ctx, memberAcc)
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", -1}))
+	assert.Equal(t, CodeCreditLimit, got.Code, got.Log)
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 200}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", -200}}, accts.GetAccount(ctx, member).GetCoins())
+}
+
+func Test_entityStatusEnforcement(t *testing.T) {
+	key, ctx := fakeStore()
+	accts, entities := NewAccountMapper(key), NewEntityMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	icm := NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "", "")
+	entities.SetEntity(ctx, icm)
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 1000}}, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	_, cust := fakeAsset(accts, ctx, sdk.Coins{{"USD", 1000}}, EntityCustodian)
+	icmAdmin, _ := fakeAdminWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	_, member := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 100}}, "ICM", EntityIndividualClearingMember)
+	settle := SettleMsgHandler(accts, ledger)
+
+	// members of active entities can be settled
+	got := settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 100}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+
+	for _, status := range []string{EntityStatusSuspended, EntityStatusClosed} {
+		icm.Status = status
+		entities.SetEntity(ctx, icm)
+		tests := []struct {
+			name string
+			h    sdk.Handler
+			msg  sdk.Msg
+		}{
+			{"deposit", DepositMsgHandler(accts, ledger), NewDepositMsg(chOp, cust, member, sdk.Coin{"USD", 1})},
+			{"settlement", settle, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 1})},
+			{"batch settlement", BatchSettleMsgHandler(accts, NewDualControlMapper(key, NewParamsMapper(key)), ledger),
+				NewBatchSettleMsg(chOp, clh, []SettleLeg{{member, sdk.Coin{"USD", 1}}})},
+			{"withdrawal", WithdrawMsgHandler(accts, ledger), NewWithdrawMsg(chOp, member, cust, sdk.Coin{"USD", 1})},
+			{"operator creation", CreateOperatorMsgHandler(accts),
+				NewCreateOperatorMsg(icmAdmin.Address, crypto.GenPrivKeyEd25519().PubKey())},
+			{"asset creation", CreateAssetAccountMsgHandler(accts),
+				NewCreateAssetAccountMsg(icmAdmin.Address, crypto.GenPrivKeyEd25519().PubKey())},
+		}
+		for _, tt := range tests {
+			t.Run(status+" "+tt.name, func(t *testing.T) {
+				got := tt.h(ctx, tt.msg)
+				assert.Equal(t, CodeInvalidEntity, got.Code, got.Log)
+			})
+		}
+	}
+	assert.Equal(t, sdk.Coins{{"USD", 200}}, accts.GetAccount(ctx, member).GetCoins())
+}
+
+func Test_setOperatorLimitsMsgHandler_Do(t *testing.T) {
+	accts, ctx := fakeAccountMapper()
+	icmAdmAcc, _ := fakeAdminWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	icmAdm := icmAdmAcc.Address
+	opAcc, _ := fakeUserWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	op := opAcc.Address
+	otherOpAcc, _ := fakeUser(accts, ctx, EntityCustodian)
+	otherOp := otherOpAcc.Address
+	settlingAdmAcc, _ := fakeAdminWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	settlingAdmAcc.GrantRole(RoleSettlementOperator)
+	accts.SetAccount(ctx, settlingAdmAcc)
+	maxAmount, dailyLimit := sdk.Coin{"USD", 100}, sdk.Coin{"USD", 1000}
+
+	tests := []struct {
+		name   string
+		msg    SetOperatorLimitsMsg
+		expect sdk.CodeType
+	}{
+		{"another entity's operator", NewSetOperatorLimitsMsg(icmAdm, otherOp, maxAmount, dailyLimit), CodeWrongSigner},
+		{"cannot move money", NewSetOperatorLimitsMsg(icmAdm, icmAdm, maxAmount, dailyLimit), CodeWrongSigner},
+		{"admin with an operator role", NewSetOperatorLimitsMsg(icmAdm, settlingAdmAcc.Address, maxAmount, dailyLimit), sdk.CodeOK},
+		{"not an admin", NewSetOperatorLimitsMsg(op, op, maxAmount, dailyLimit), CodeWrongSigner},
+		{"ok", NewSetOperatorLimitsMsg(icmAdm, op, maxAmount, dailyLimit), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SetOperatorLimitsMsgHandler(accts)
+			got := handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	for _, addr := range []crypto.Address{op, settlingAdmAcc.Address} {
+		acct := accts.GetAccount(ctx, addr).(*AppAccount)
+		assert.Equal(t, sdk.Coins{maxAmount}, acct.OperatorLimits.MaxAmounts)
+		assert.Equal(t, sdk.Coins{dailyLimit}, acct.OperatorLimits.DailyLimits)
+	}
+}
+
+func Test_operatorLimitsEnforcement(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOpAcc.OperatorLimits.SetLimits(sdk.Coin{"USD", 500}, sdk.Coin{"USD", 800})
+	accts.SetAccount(ctx, chOpAcc)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 5000}}, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	_, member := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	_, member2 := fakeAsset(accts, ctx, nil, EntityGeneralClearingMember)
+	netting := NewNettingMapper(key)
+	settle := SettleMsgHandler(accts, ledger)
+	batch := BatchSettleMsgHandler(accts, NewDualControlMapper(key, NewParamsMapper(key)), ledger)
+	net := NetSettlementMsgHandler(accts, netting, NewDualControlMapper(key, NewParamsMapper(key)), ledger)
+
+	// a single amount above the maximum fails
+	got := settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 600}))
+	assert.Equal(t, CodeOperatorLimit, got.Code, got.Log)
+	// amounts add up to the daily limit
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 500}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 400}))
+	assert.Equal(t, CodeOperatorLimit, got.Code, got.Log)
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 300}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	// the daily total resets the next day
+	ctx = ctx.WithBlockHeader(abci.Header{Height: 101, Time: secondsPerDay})
+	got = settle(ctx, NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 200}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", 1000}}, accts.GetAccount(ctx, member).GetCoins())
+
+	// every leg of a batch is charged, a single failing leg rejects the batch
+	got = batch(ctx, NewBatchSettleMsg(chOp, clh, []SettleLeg{{member, sdk.Coin{"USD", 100}}, {member2, sdk.Coin{"USD", 600}}}))
+	assert.Equal(t, CodeOperatorLimit, got.Code, got.Log)
+	got = batch(ctx, NewBatchSettleMsg(chOp, clh, []SettleLeg{{member, sdk.Coin{"USD", 300}}, {member2, sdk.Coin{"USD", 400}}}))
+	assert.Equal(t, CodeOperatorLimit, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", 1000}}, accts.GetAccount(ctx, member).GetCoins())
+	got = batch(ctx, NewBatchSettleMsg(chOp, clh, []SettleLeg{{member, sdk.Coin{"USD", 300}}, {member2, sdk.Coin{"USD", 300}}}))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, int64(800), accts.GetAccount(ctx, chOp).(*AppAccount).OperatorLimits.DailyUsage.AmountOf("USD"))
+
+	// so is every net position
+	ctx = ctx.WithBlockHeader(abci.Header{Height: 201, Time: 2 * secondsPerDay})
+	netting.AddObligation(ctx, Obligation{member, member2, sdk.Coin{"USD", 450}})
+	got = net(ctx, NewNetSettlementMsg(chOp, clh))
+	assert.Equal(t, CodeOperatorLimit, got.Code, got.Log)
+	assert.Equal(t, int64(1), netting.GetCurrentCycle(ctx))
+	netting.AddObligation(ctx, Obligation{member2, member, sdk.Coin{"USD", 100}})
+	got = net(ctx, NewNetSettlementMsg(chOp, clh))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", 950}}, accts.GetAccount(ctx, member).GetCoins())
+	assert.Equal(t, sdk.Coins{{"USD", 650}}, accts.GetAccount(ctx, member2).GetCoins())
+}
+
+func Test_roleMsgHandlers(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	chAdmAcc, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdm := chAdmAcc.Address
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	icmAdmAcc, _ := fakeAdmin(accts, ctx, EntityIndividualClearingMember)
+	_, cust := fakeAsset(accts, ctx, sdk.Coins{{"USD", 1000}}, EntityCustodian)
+	_, member := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	grant := GrantRoleMsgHandler(accts)
+	revoke := RevokeRoleMsgHandler(accts)
+	deposit := DepositMsgHandler(accts, ledger)
+
+	tests := []struct {
+		name    string
+		handler sdk.Handler
+		msg     sdk.Msg
+		expect  sdk.CodeType
+	}{
+		{"another entity's admin cannot revoke", revoke, NewRevokeRoleMsg(icmAdmAcc.Address, chOp, RoleDepositOperator), CodeWrongSigner},
+		{"operators cannot revoke", revoke, NewRevokeRoleMsg(chOp, chAdm, RoleUserAdmin), CodeWrongSigner},
+		{"asset accounts have no roles", grant, NewGrantRoleMsg(chAdm, member, RoleAuditor), CodeWrongSigner},
+		{"role not held", revoke, NewRevokeRoleMsg(chAdm, chOp, RoleAuditor), CodeInvalidRole},
+		{"revoke", revoke, NewRevokeRoleMsg(chAdm, chOp, RoleDepositOperator), sdk.CodeOK},
+		{"deposits need the deposit permission", deposit, NewDepositMsg(chOp, cust, member, sdk.Coin{"USD", 100}), CodeWrongSigner},
+		{"grant", grant, NewGrantRoleMsg(chAdm, chOp, RoleDepositOperator), sdk.CodeOK},
+		{"role already held", grant, NewGrantRoleMsg(chAdm, chOp, RoleDepositOperator), CodeInvalidRole},
+		{"deposit", deposit, NewDepositMsg(chOp, cust, member, sdk.Coin{"USD", 100}), sdk.CodeOK},
+		{"user-admin role", grant, NewGrantRoleMsg(chAdm, chOp, RoleUserAdmin), sdk.CodeOK},
+		{"operators with the user-admin role manage users", revoke, NewRevokeRoleMsg(chOp, chAdm, RoleUserAdmin), sdk.CodeOK},
+		{"admins without the user-admin role cannot", grant, NewGrantRoleMsg(chAdm, chOp, RoleAuditor), CodeWrongSigner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	acct := accts.GetAccount(ctx, chOp).(*AppAccount)
+	assert.Equal(t, []string{RoleDepositOperator, RoleSettlementOperator, RoleUserAdmin, RoleWithdrawalOperator}, acct.Roles)
+	assert.Equal(t, 0, len(accts.GetAccount(ctx, chAdm).(*AppAccount).Roles))
+}
+
+func Test_rotateKeyMsgHandler_Do(t *testing.T) {
+	accts, ctx := fakeAccountMapper()
+	chAdmAcc, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdm := chAdmAcc.Address
+	icmAdmAcc, _ := fakeAdminWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	icmAdm := icmAdmAcc.Address
+	icmOpAcc, _ := fakeUserWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	icmOp := icmOpAcc.Address
+	_, member := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 100}}, "ICM", EntityIndividualClearingMember)
+	_, frozen := fakeInactiveAssetWithEntityName(accts, ctx, nil, "ICM", EntityIndividualClearingMember)
+	_, cust := fakeAsset(accts, ctx, nil, EntityCustodian)
+	newKey := func() crypto.PubKey { return crypto.GenPrivKeyEd25519().PubKey() }
+	memberKey := newKey()
+
+	tests := []struct {
+		name   string
+		msg    RotateKeyMsg
+		expect sdk.CodeType
+	}{
+		{"operators cannot rotate keys", NewRotateKeyMsg(icmOp, member, newKey()), CodeWrongSigner},
+		{"another entity's account", NewRotateKeyMsg(icmAdm, cust, newKey()), CodeWrongSigner},
+		{"unknown account", NewRotateKeyMsg(icmAdm, newKey().Address(), newKey()), CodeInvalidAccount},
+		{"same key", NewRotateKeyMsg(icmAdm, icmOp, icmOpAcc.PubKey), CodeInvalidPubKey},
+		{"key of another account", NewRotateKeyMsg(icmAdm, icmOp, chAdmAcc.PubKey), CodeInvalidPubKey},
+		{"admin rotates an operator's key", NewRotateKeyMsg(icmAdm, icmOp, newKey()), sdk.CodeOK},
+		{"frozen account", NewRotateKeyMsg(icmAdm, frozen, newKey()), sdk.CodeOK},
+		{"clearing house admins rotate any key", NewRotateKeyMsg(chAdm, member, memberKey), sdk.CodeOK},
+		{"key rotated to another account", NewRotateKeyMsg(icmAdm, frozen, memberKey), CodeInvalidPubKey},
+		{"back to the account's former key", NewRotateKeyMsg(icmAdm, icmOp, icmOpAcc.PubKey), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := RotateKeyMsgHandler(accts)
+			got := handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	// the account keeps everything but its key
+	acct := accts.GetAccount(ctx, member).(*AppAccount)
+	assert.Equal(t, memberKey, acct.PubKey)
+	assert.Equal(t, sdk.Coins{{"USD", 100}}, acct.Coins)
+	assert.Equal(t, "ICM", acct.LegalEntityName())
+	// rotated keys cannot open new accounts
+	got := CreateOperatorMsgHandler(accts)(ctx, NewCreateOperatorMsg(icmAdm, memberKey))
+	assert.Equal(t, CodeInvalidAccount, got.Code, got.Log)
+	got = CreateAssetAccountMsgHandler(accts)(ctx, NewCreateAssetAccountMsg(icmAdm, memberKey))
+	assert.Equal(t, CodeInvalidAccount, got.Code, got.Log)
+}
+
+func Test_closeAssetAccountMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	chAdmAcc, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdm := chAdmAcc.Address
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	icmAdmAcc, _ := fakeAdminWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	icmAdm := icmAdmAcc.Address
+	_, empty := fakeAssetWithEntityName(accts, ctx, nil, "ICM", EntityIndividualClearingMember)
+	_, funded := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"EUR", 50}, {"USD", 100}}, "ICM", EntityIndividualClearingMember)
+	_, sweep := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 10}}, "ICM", EntityIndividualClearingMember)
+	indebtedAcc, _ := makeAssetAccount(sdk.Coins{{"USD", -100}}, "ICM", EntityIndividualClearingMember)
+	indebtedAcc.SetCreditLimit(sdk.Coin{"USD", 100})
+	accts.SetAccount(ctx, indebtedAcc)
+	indebted := indebtedAcc.Address
+	_, frozen := fakeInactiveAssetWithEntityName(accts, ctx, nil, "ICM", EntityIndividualClearingMember)
+	_, cust := fakeAsset(accts, ctx, sdk.Coins{{"USD", 10}}, EntityCustodian)
+	_, custEmpty := fakeAsset(accts, ctx, nil, EntityCustodian)
+	_, chIndebted := fakeAsset(accts, ctx, sdk.Coins{{"USD", -100}}, EntityClearingHouse)
+	_, chSweep := fakeAsset(accts, ctx, nil, EntityClearingHouse)
+	closeAsset := CloseAssetAccountMsgHandler(accts, ledger)
+
+	tests := []struct {
+		name    string
+		handler sdk.Handler
+		msg     sdk.Msg
+		expect  sdk.CodeType
+	}{
+		{"another entity's account", closeAsset, NewCloseAssetAccountMsg(icmAdm, custEmpty, nil), CodeWrongSigner},
+		{"non-zero balance", closeAsset, NewCloseAssetAccountMsg(icmAdm, funded, nil), CodeInvalidAmount},
+		{"sweep to another entity", closeAsset, NewCloseAssetAccountMsg(chAdm, funded, cust), CodeWrongSigner},
+		{"sweep beyond the credit limit", closeAsset, NewCloseAssetAccountMsg(icmAdm, indebted, empty), CodeInvalidAmount},
+		{"clearing house accounts are not limited", closeAsset, NewCloseAssetAccountMsg(chAdm, chIndebted, chSweep), sdk.CodeOK},
+		{"sweep", closeAsset, NewCloseAssetAccountMsg(icmAdm, funded, sweep), sdk.CodeOK},
+		{"zero balance", closeAsset, NewCloseAssetAccountMsg(icmAdm, empty, nil), sdk.CodeOK},
+		{"frozen account", closeAsset, NewCloseAssetAccountMsg(icmAdm, frozen, nil), sdk.CodeOK},
+		{"clearing house admins close any account", closeAsset, NewCloseAssetAccountMsg(chAdm, custEmpty, nil), sdk.CodeOK},
+		{"already closed", closeAsset, NewCloseAssetAccountMsg(icmAdm, empty, nil), CodeInvalidAccount},
+		{"sweep to a closed account", closeAsset, NewCloseAssetAccountMsg(icmAdm, indebted, empty), CodeInvalidAccount},
+		{"closed accounts cannot be credited", DepositMsgHandler(accts, ledger), NewDepositMsg(chOp, cust, empty, sdk.Coin{"USD", 1}), CodeInvalidAccount},
+		{"closed accounts cannot be unfrozen", UnfreezeAssetAccountMsgHandler(accts), NewUnfreezeAssetAccountMsg(icmAdm, empty), CodeInvalidAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	assert.Equal(t, sdk.Coins{{"EUR", 50}, {"USD", 110}}, accts.GetAccount(ctx, sweep).GetCoins())
+	closed := accts.GetAccount(ctx, funded).(*AppAccount)
+	assert.True(t, closed.IsClosed())
+	assert.False(t, closed.IsActive())
+	assert.True(t, closed.Coins.IsZero())
+	assert.Equal(t, sdk.Coins{{"USD", -100}}, accts.GetAccount(ctx, indebted).GetCoins())
+	assert.Equal(t, sdk.Coins{{"USD", -100}}, accts.GetAccount(ctx, chSweep).GetCoins())
+}
+
+func Test_singleControlHandler(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	dualControl := NewDualControlMapper(key, NewParamsMapper(key))
+	dualControl.SetParams(ctx, DualControlParams{ExpiryBlocks: 10, Thresholds: sdk.Coins{{"USD", 1000}}})
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"EUR", 5000}, {"USD", 5000}}, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	_, member := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	settle := SingleControlHandler(dualControl, SettleMsgHandler(accts, ledger))
+
+	tests := []struct {
+		name   string
+		amount sdk.Coin
+		expect sdk.CodeType
+	}{
+		{"up to the threshold", sdk.Coin{"USD", 1000}, sdk.CodeOK},
+		{"above the threshold", sdk.Coin{"USD", 1001}, CodeDualControl},
+		{"negative above the threshold", sdk.Coin{"USD", -1001}, CodeDualControl},
+		{"no threshold", sdk.Coin{"EUR", 5000}, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := settle(ctx, NewSettleMsg(chOp, clh, member, tt.amount))
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+}
+
+func Test_resultTags(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	dualControl := NewDualControlMapper(key, NewParamsMapper(key))
+	refs := NewReferenceMapper(key)
+	chAdm, _ := fakeAdminWithEntityName(accts, ctx, "CH", EntityClearingHouse)
+	maker, _ := fakeUserWithEntityName(accts, ctx, "CH", EntityClearingHouse)
+	checker, _ := fakeUserWithEntityName(accts, ctx, "CH", EntityClearingHouse)
+	icmAdm, _ := fakeAdminWithEntityName(accts, ctx, "ICM1", EntityIndividualClearingMember)
+	operator, _ := fakeUserWithEntityName(accts, ctx, "ICM1", EntityIndividualClearingMember)
+	_, cust := fakeAssetWithEntityName(accts, ctx, nil, "CUST1", EntityCustodian)
+	_, member := fakeAssetWithEntityName(accts, ctx, nil, "ICM1", EntityIndividualClearingMember)
+	deposit := DepositMsg{maker.Address, cust, member, sdk.Coin{"USD", 700}, "wire-1", "memo"}
+
+	tests := []struct {
+		name    string
+		handler sdk.Handler
+		msg     sdk.Msg
+		want    Tags
+	}{
+		{"deposit", DepositMsgHandler(accts, ledger), deposit, Tags{
+			{Key: []byte("action"), Value: []byte(DepositType)},
+			{Key: []byte("operator"), Value: []byte(maker.Address.String())},
+			{Key: []byte("operator.entity"), Value: []byte("CH")},
+			{Key: []byte("sender"), Value: []byte(cust.String())},
+			{Key: []byte("sender.entity"), Value: []byte("CUST1")},
+			{Key: []byte("recipient"), Value: []byte(member.String())},
+			{Key: []byte("recipient.entity"), Value: []byte("ICM1")},
+			{Key: []byte("denom"), Value: []byte("USD")},
+			{Key: []byte("amount"), Value: []byte("700")},
+			{Key: []byte("reference"), Value: []byte("wire-1")},
+		}},
+		{"freeze operator", FreezeOperatorMsgHandler(accts), NewFreezeOperatorMsg(icmAdm.Address, operator.Address), Tags{
+			{Key: []byte("action"), Value: []byte(FreezeOperatorType)},
+			{Key: []byte("admin"), Value: []byte(icmAdm.Address.String())},
+			{Key: []byte("target"), Value: []byte(operator.Address.String())},
+			{Key: []byte("target.entity"), Value: []byte("ICM1")},
+		}},
+		{"grant role", GrantRoleMsgHandler(accts), NewGrantRoleMsg(chAdm.Address, maker.Address, RoleAuditor), Tags{
+			{Key: []byte("action"), Value: []byte(GrantRoleType)},
+			{Key: []byte("admin"), Value: []byte(chAdm.Address.String())},
+			{Key: []byte("target"), Value: []byte(maker.Address.String())},
+			{Key: []byte("target.entity"), Value: []byte("CH")},
+			{Key: []byte("role"), Value: []byte(RoleAuditor)},
+		}},
+		{"propose", ProposeTransferMsgHandler(accts, dualControl, refs), NewProposeTransferMsg(maker.Address, WithdrawType, member, cust, sdk.Coin{"USD", 100}), Tags{
+			{Key: []byte("action"), Value: []byte(ProposeTransferType)},
+			{Key: []byte("operator"), Value: []byte(maker.Address.String())},
+			{Key: []byte("operator.entity"), Value: []byte("CH")},
+			{Key: []byte("sender"), Value: []byte(member.String())},
+			{Key: []byte("sender.entity"), Value: []byte("ICM1")},
+			{Key: []byte("recipient"), Value: []byte(cust.String())},
+			{Key: []byte("recipient.entity"), Value: []byte("CUST1")},
+			{Key: []byte("denom"), Value: []byte("USD")},
+			{Key: []byte("amount"), Value: []byte("100")},
+			{Key: []byte("transfer.type"), Value: []byte(WithdrawType)},
+			{Key: []byte("transfer.id"), Value: []byte("1")},
+		}},
+		{"approve", ApproveTransferMsgHandler(accts, dualControl, refs, ledger), NewApproveTransferMsg(checker.Address, 1), Tags{
+			{Key: []byte("action"), Value: []byte(ApproveTransferType)},
+			{Key: []byte("checker"), Value: []byte(checker.Address.String())},
+			{Key: []byte("transfer.type"), Value: []byte(WithdrawType)},
+			{Key: []byte("transfer.id"), Value: []byte("1")},
+			{Key: []byte("action"), Value: []byte(WithdrawType)},
+			{Key: []byte("operator"), Value: []byte(maker.Address.String())},
+			{Key: []byte("operator.entity"), Value: []byte("CH")},
+			{Key: []byte("sender"), Value: []byte(member.String())},
+			{Key: []byte("sender.entity"), Value: []byte("ICM1")},
+			{Key: []byte("recipient"), Value: []byte(cust.String())},
+			{Key: []byte("recipient.entity"), Value: []byte("CUST1")},
+			{Key: []byte("denom"), Value: []byte("USD")},
+			{Key: []byte("amount"), Value: []byte("100")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.handler(ctx, tt.msg)
+			assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+			assert.Equal(t, []cmn.KVPair(tt.want), got.Tags)
+		})
+	}
+
+	// failures carry no tags
+	got := FreezeOperatorMsgHandler(accts)(ctx, NewFreezeOperatorMsg(icmAdm.Address, operator.Address))
+	assert.Equal(t, CodeInactiveAccount, got.Code, got.Log)
+	assert.Empty(t, got.Tags)
+}
+
+func Test_referenceHandler(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	refs := NewReferenceMapper(key)
+	chOpAcc, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	chOp := chOpAcc.Address
+	_, clh := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 5000}}, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	_, clh2 := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 5000}}, chOpAcc.LegalEntityName(), EntityClearingHouse)
+	_, member := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	settle := ReferenceHandler(refs, SettleMsgHandler(accts, ledger))
+	withRef := func(msg SettleMsg, ref string) SettleMsg {
+		msg.Reference, msg.Memo = ref, "memo"
+		return msg
+	}
+
+	tests := []struct {
+		name   string
+		msg    SettleMsg
+		expect sdk.CodeType
+	}{
+		{"failed transfers do not use references", withRef(NewSettleMsg(chOp, clh2, member, sdk.Coin{"USD", -100}), "ref-2"), CodeInvalidAmount},
+		{"no reference", NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 100}), sdk.CodeOK},
+		{"no reference again", NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 100}), sdk.CodeOK},
+		{"reference", withRef(NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 100}), "ref-1"), sdk.CodeOK},
+		{"duplicate reference", withRef(NewSettleMsg(chOp, clh, member, sdk.Coin{"USD", 100}), "ref-1"), CodeDuplicateReference},
+		{"references are unique per sender", withRef(NewSettleMsg(chOp, clh2, member, sdk.Coin{"USD", 100}), "ref-1"), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := settle(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	assert.Equal(t, sdk.Coins{{"USD", 4700}}, accts.GetAccount(ctx, clh).GetCoins())
+	rec, found := refs.GetTransfer(ctx, clh, "ref-1")
+	assert.True(t, found)
+	assert.Equal(t, TransferRecord{SettlementType, chOp, clh, member, sdk.Coin{"USD", 100}, "ref-1", "memo", ctx.BlockHeight()}, rec)
+	_, found = refs.GetTransfer(ctx, clh2, "ref-2")
+	assert.False(t, found)
+}
+
+func Test_dualControlMsgHandlers(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	dualControl := NewDualControlMapper(key, NewParamsMapper(key))
+	maker, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	checker, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	admin, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	foreignOp, _ := fakeUserWithEntityName(accts, ctx, "another CH", EntityClearingHouse)
+	_, clh := fakeAssetWithEntityName(accts, ctx, sdk.Coins{{"USD", 1000}}, maker.LegalEntityName(), EntityClearingHouse)
+	_, member := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+	refs := NewReferenceMapper(key)
+	propose := ProposeTransferMsgHandler(accts, dualControl, refs)
+	approve := ApproveTransferMsgHandler(accts, dualControl, refs, ledger)
+	reject := RejectTransferMsgHandler(accts, dualControl)
+	proposal := NewProposeTransferMsg(maker.Address, SettlementType, clh, member, sdk.Coin{"USD", 700})
+
+	// only clearing house operators propose
+	got := propose(ctx, NewProposeTransferMsg(admin.Address, SettlementType, clh, member, sdk.Coin{"USD", 700}))
+	assert.Equal(t, CodeWrongSigner, got.Code, got.Log)
+	got = propose(ctx, proposal)
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, "1", string(got.Data))
+	got = propose(ctx, proposal)
+	assert.Equal(t, "2", string(got.Data))
+	assert.Equal(t, 2, len(dualControl.GetPendingTransfers(ctx)))
+	// nothing moves before approval
+	assert.Equal(t, sdk.Coins{{"USD", 1000}}, accts.GetAccount(ctx, clh).GetCoins())
+
+	tests := []struct {
+		name    string
+		handler sdk.Handler
+		msg     sdk.Msg
+		expect  sdk.CodeType
+	}{
+		{"unknown transfer", approve, NewApproveTransferMsg(checker.Address, 3), CodeInvalidTransfer},
+		{"proposer cannot approve", approve, NewApproveTransferMsg(maker.Address, 1), CodeWrongSigner},
+		{"another entity's operator cannot approve", approve, NewApproveTransferMsg(foreignOp.Address, 1), CodeWrongSigner},
+		{"asset accounts cannot approve", approve, NewApproveTransferMsg(clh, 1), CodeWrongSigner},
+		{"operator approves", approve, NewApproveTransferMsg(checker.Address, 1), sdk.CodeOK},
+		{"already approved", approve, NewApproveTransferMsg(checker.Address, 1), CodeInvalidTransfer},
+		{"another entity's operator cannot reject", reject, NewRejectTransferMsg(foreignOp.Address, 2), CodeWrongSigner},
+		{"proposer rejects", reject, NewRejectTransferMsg(maker.Address, 2), sdk.CodeOK},
+		{"rejected transfers cannot be approved", approve, NewApproveTransferMsg(admin.Address, 2), CodeInvalidTransfer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.handler(ctx, tt.msg)
+			assert.Equal(t, tt.expect, got.Code, got.Log)
+		})
+	}
+	assert.Equal(t, sdk.Coins{{"USD", 300}}, accts.GetAccount(ctx, clh).GetCoins())
+	assert.Equal(t, sdk.Coins{{"USD", 700}}, accts.GetAccount(ctx, member).GetCoins())
+	approved, _ := dualControl.GetTransfer(ctx, 1)
+	assert.Equal(t, TransferApproved, approved.Status)
+	assert.Equal(t, checker.Address, approved.Checker)
+	rejected, _ := dualControl.GetTransfer(ctx, 2)
+	assert.Equal(t, TransferRejected, rejected.Status)
+	assert.Equal(t, 0, len(dualControl.GetPendingTransfers(ctx)))
+
+	// checkers need the permission the transfer requires or be admins of the proposer's entity
+	propose(ctx, NewProposeTransferMsg(maker.Address, SettlementType, clh, member, sdk.Coin{"USD", -800}))
+	auditor, _ := fakeUserWithEntityName(accts, ctx, maker.LegalEntityName(), EntityClearingHouse)
+	auditor.Roles = []string{RoleAuditor}
+	accts.SetAccount(ctx, auditor)
+	got = approve(ctx, NewApproveTransferMsg(auditor.Address, 3))
+	assert.Equal(t, CodeWrongSigner, got.Code, got.Log)
+	foreignAdm, _ := fakeAdminWithEntityName(accts, ctx, "another CH", EntityClearingHouse)
+	got = approve(ctx, NewApproveTransferMsg(foreignAdm.Address, 3))
+	assert.Equal(t, CodeWrongSigner, got.Code, got.Log)
+
+	// failed transfers remain pending
+	got = approve(ctx, NewApproveTransferMsg(admin.Address, 3))
+	assert.Equal(t, CodeInvalidAmount, got.Code, got.Log)
+	assert.Equal(t, 1, len(dualControl.GetPendingTransfers(ctx)))
+
+	// expired transfers cannot be approved
+	later := ctx.WithBlockHeight(ctx.BlockHeight() + DefaultExpiryBlocks + 1)
+	got = reject(later, NewRejectTransferMsg(admin.Address, 3))
+	assert.Equal(t, CodeInvalidTransfer, got.Code, got.Log)
+
+	// references are recorded once approved transfers execute
+	referenced := proposal
+	referenced.Reference = "ref-1"
+	referenced.Amount = sdk.Coin{"USD", 100}
+	got = propose(ctx, referenced)
+	assert.Equal(t, "4", string(got.Data))
+	got = propose(ctx, referenced)
+	assert.Equal(t, "5", string(got.Data))
+	got = approve(ctx, NewApproveTransferMsg(checker.Address, 4))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	got = propose(ctx, referenced)
+	assert.Equal(t, CodeDuplicateReference, got.Code, got.Log)
+	got = approve(ctx, NewApproveTransferMsg(checker.Address, 5))
+	assert.Equal(t, CodeDuplicateReference, got.Code, got.Log)
+
+	// admins approve their entity's transfers
+	got = propose(ctx, NewProposeTransferMsg(maker.Address, SettlementType, clh, member, sdk.Coin{"USD", 50}))
+	assert.Equal(t, "6", string(got.Data))
+	got = approve(ctx, NewApproveTransferMsg(admin.Address, 6))
+	assert.Equal(t, sdk.CodeOK, got.Code, got.Log)
+	assert.Equal(t, sdk.Coins{{"USD", 850}}, accts.GetAccount(ctx, member).GetCoins())
+}
+
+func Test_withdrawMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts := NewAccountMapper(key)
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
 	mCoins := sdk.Coins{{"EUR", 5000}, {"USD", 1000}}
 	custCoins := sdk.Coins{}
 
@@ -272,7 +1144,7 @@ func Test_withdrawMsgHandler_Do(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := WithdrawMsgHandler(accts)
+			handler := WithdrawMsgHandler(accts, ledger)
 			got := handler(ctx, tt.msg)
 			assert.Equal(t, tt.expect, got.Code, got.Log)
 
@@ -424,6 +1296,11 @@ func Test_validateCHAdminAndCreateXEntityAdmin(t *testing.T) {
 //---------------- helpers --------------------
 
 func fakeAccountMapper() (sdk.AccountMapper, sdk.Context) {
+	key, ctx := fakeStore()
+	return NewAccountMapper(key), ctx
+}
+
+func fakeStore() (sdk.StoreKey, sdk.Context) {
 	db := dbm.NewMemDB()
 	ms := store.NewCommitMultiStore(db)
 
@@ -434,14 +1311,13 @@ func fakeAccountMapper() (sdk.AccountMapper, sdk.Context) {
 		panic(err)
 	}
 
-	accts := NewAccountMapper(key)
 	h := abci.Header{
 		Height:  100,
 		ChainID: "clear-chain",
 	}
 	ctx := sdk.NewContext(ms, h, false, []byte{1, 2, 3, 4}) // DeliverTx
 
-	return accts, ctx
+	return key, ctx
 }
 
 func fakeUser(accts sdk.AccountMapper, ctx sdk.Context, typ string) (*AppAccount, crypto.PrivKey) {
@@ -526,8 +1402,73 @@ func Test_createOperatorMsgHandler_Do(t *testing.T) {
 	}
 }
 
+func Test_registerEntityMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts, entities := NewAccountMapper(key), NewEntityMapper(key)
+	chAdmin, _ := fakeAdminWithEntityName(accts, ctx, "clearing house", EntityClearingHouse)
+	nonchAdmin, _ := fakeAdminWithEntityName(accts, ctx, "member", EntityIndividualClearingMember)
+	chOperator, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	tests := []struct {
+		name string
+		msg  RegisterEntityMsg
+		want sdk.CodeType
+	}{
+		{"non CH admin cannot register", NewRegisterEntityMsg(nonchAdmin.Address, "icm-1", "ICM", EntityIndividualClearingMember, "", ""), CodeWrongSigner},
+		{"operator cannot register", NewRegisterEntityMsg(chOperator.Address, "icm-1", "ICM", EntityIndividualClearingMember, "", ""), CodeWrongSigner},
+		{"CH admin can register", NewRegisterEntityMsg(chAdmin.Address, "icm-1", "ICM", EntityIndividualClearingMember, "5493001KJTIIGC8Y1R12", "GB"), sdk.CodeOK},
+		{"IDs are unique", NewRegisterEntityMsg(chAdmin.Address, "icm-1", "ICM2", EntityIndividualClearingMember, "", ""), CodeDuplicateEntity},
+		{"names are unique", NewRegisterEntityMsg(chAdmin.Address, "icm-2", "ICM", EntityCustodian, "", ""), CodeDuplicateEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RegisterEntityMsgHandler(accts, entities)(ctx, tt.msg)
+			assert.Equal(t, tt.want, got.Code, got.Log)
+		})
+	}
+	e, found := entities.GetEntity(ctx, "icm-1")
+	assert.True(t, found)
+	assert.Equal(t, NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "5493001KJTIIGC8Y1R12", "GB"), e)
+}
+
+func Test_updateEntityStatusMsgHandler_Do(t *testing.T) {
+	key, ctx := fakeStore()
+	accts, entities := NewAccountMapper(key), NewEntityMapper(key)
+	entities.SetEntity(ctx, NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "", ""))
+	chAdmin, _ := fakeAdminWithEntityName(accts, ctx, "clearing house", EntityClearingHouse)
+	nonchAdmin, _ := fakeAdminWithEntityName(accts, ctx, "member", EntityIndividualClearingMember)
+	chOperator, _ := fakeUser(accts, ctx, EntityClearingHouse)
+	tests := []struct {
+		name   string
+		msg    UpdateEntityStatusMsg
+		want   sdk.CodeType
+		status string
+	}{
+		{"non CH admin cannot update", NewUpdateEntityStatusMsg(nonchAdmin.Address, "icm-1", EntityStatusSuspended), CodeWrongSigner, EntityStatusActive},
+		{"operator cannot update", NewUpdateEntityStatusMsg(chOperator.Address, "icm-1", EntityStatusSuspended), CodeWrongSigner, EntityStatusActive},
+		{"unregistered entity", NewUpdateEntityStatusMsg(chAdmin.Address, "icm-2", EntityStatusSuspended), CodeInvalidEntity, EntityStatusActive},
+		{"unchanged status", NewUpdateEntityStatusMsg(chAdmin.Address, "icm-1", EntityStatusActive), CodeInvalidEntity, EntityStatusActive},
+		{"suspend", NewUpdateEntityStatusMsg(chAdmin.Address, "icm-1", EntityStatusSuspended), sdk.CodeOK, EntityStatusSuspended},
+		{"reactivate", NewUpdateEntityStatusMsg(chAdmin.Address, "icm-1", EntityStatusActive), sdk.CodeOK, EntityStatusActive},
+		{"close", NewUpdateEntityStatusMsg(chAdmin.Address, "icm-1", EntityStatusClosed), sdk.CodeOK, EntityStatusClosed},
+		{"closed entities cannot be reopened", NewUpdateEntityStatusMsg(chAdmin.Address, "icm-1", EntityStatusActive), CodeInvalidEntity, EntityStatusClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpdateEntityStatusMsgHandler(accts, entities)(ctx, tt.msg)
+			assert.Equal(t, tt.want, got.Code, got.Log)
+			e, _ := entities.GetEntityByName(ctx, "ICM")
+			assert.Equal(t, tt.status, e.Status)
+		})
+	}
+}
+
 func Test_createAdminMsgHandler_Do(t *testing.T) {
-	accts, ctx := fakeAccountMapper()
+	key, ctx := fakeStore()
+	accts, entities := NewAccountMapper(key), NewEntityMapper(key)
+	entities.SetEntity(ctx, NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "", ""))
+	suspended := NewEntity("gcm-1", "GCM", EntityGeneralClearingMember, "", "")
+	suspended.Status = EntityStatusSuspended
+	entities.SetEntity(ctx, suspended)
 	newPub := crypto.GenPrivKeyEd25519().PubKey()
 	chAdmin, _ := fakeAdminWithEntityName(accts, ctx, "clearing house", EntityClearingHouse)
 	nonchAdmin, _ := fakeAdminWithEntityName(accts, ctx, "member", EntityIndividualClearingMember)
@@ -542,24 +1483,26 @@ func Test_createAdminMsgHandler_Do(t *testing.T) {
 		msg  CreateAdminMsg
 		want sdk.CodeType
 	}{
-		{"non CH admin cannot create", CreateAdminMsg{BaseCreateUserMsg{
-			Creator: nonchAdmAddr, PubKey: newPub}, BaseLegalEntity{}}, CodeWrongSigner},
-		{"CH admin can create", CreateAdminMsg{BaseCreateUserMsg{
-			Creator: chAdmAddr, PubKey: newPub}, BaseLegalEntity{}}, sdk.CodeOK},
-		{"operator cannot create", CreateAdminMsg{BaseCreateUserMsg{
-			Creator: opAddr, PubKey: newPub}, BaseLegalEntity{}}, CodeWrongSigner},
-		{"asset cannot create", CreateAdminMsg{BaseCreateUserMsg{
-			Creator: assetAddr, PubKey: newPub}, BaseLegalEntity{}}, CodeWrongSigner},
+		{"non CH admin cannot create", NewCreateAdminMsg(nonchAdmAddr, newPub, "icm-1"), CodeWrongSigner},
+		{"unregistered entity", NewCreateAdminMsg(chAdmAddr, newPub, "icm"), CodeInvalidEntity},
+		{"suspended entity", NewCreateAdminMsg(chAdmAddr, newPub, "gcm-1"), CodeInvalidEntity},
+		{"CH admin can create", NewCreateAdminMsg(chAdmAddr, newPub, "icm-1"), sdk.CodeOK},
+		{"operator cannot create", NewCreateAdminMsg(opAddr, newPub, "icm-1"), CodeWrongSigner},
+		{"asset cannot create", NewCreateAdminMsg(assetAddr, newPub, "icm-1"), CodeWrongSigner},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			h := createAdminMsgHandler{
-				accts: accts,
+				accts:    accts,
+				entities: entities,
 			}
 			got := h.Do(ctx, tt.msg)
 			assert.Equal(t, tt.want, got.Code, got.Log)
 		})
 	}
+	// admins inherit their registered entity's name and type
+	admin := accts.GetAccount(ctx, newPub.Address()).(*AppAccount)
+	assert.Equal(t, BaseLegalEntity{EntityName: "ICM", EntityType: EntityIndividualClearingMember}, admin.BaseLegalEntity)
 }
 
 func Test_freezeOperatorMsgHandler_Do(t *testing.T) {
@@ -650,3 +1593,162 @@ func Test_freezeAdminMsgHandler_Do(t *testing.T) {
 		})
 	}
 }
+
+func Test_unfreezeOperatorMsgHandler_Do(t *testing.T) {
+	accts, ctx := fakeAccountMapper()
+	admin, _ := fakeAdminWithEntityName(accts, ctx, "qweasdzxc", EntityIndividualClearingMember)
+	admAddr := admin.Address
+	inactiveAdmin, _ := fakeAdminWithEntityName(accts, ctx, "qweasdzxc", EntityIndividualClearingMember)
+	inactiveAdmin.Active = false
+	accts.SetAccount(ctx, inactiveAdmin)
+	inadmAddr := inactiveAdmin.Address
+	operator, _ := fakeUserWithEntityName(accts, ctx, admin.LegalEntityName(), EntityIndividualClearingMember)
+	opAddr := operator.Address
+	inactiveOperator, _ := fakeUserWithEntityName(accts, ctx, admin.LegalEntityName(), EntityIndividualClearingMember)
+	inactiveOperator.Active = false
+	accts.SetAccount(ctx, inactiveOperator)
+	inopAddr := inactiveOperator.Address
+	foreignOperator, _ := fakeInactiveUser(accts, ctx, EntityIndividualClearingMember)
+	fopAddr := foreignOperator.Address
+	asset, _ := fakeInactiveAssetWithEntityName(accts, ctx, nil, admin.LegalEntityName(), EntityIndividualClearingMember)
+	assetAddr := asset.Address
+	tests := []struct {
+		name string
+		msg  BaseFreezeAccountMsg
+		want sdk.CodeType
+	}{
+		{"inactive admin", BaseFreezeAccountMsg{Admin: inadmAddr, Target: inopAddr}, CodeInactiveAccount},
+		{"active op", BaseFreezeAccountMsg{Admin: admAddr, Target: opAddr}, CodeInvalidAccount},
+		{"op can't unfreeze", BaseFreezeAccountMsg{Admin: opAddr, Target: inopAddr}, CodeWrongSigner},
+		{"foreign operator", BaseFreezeAccountMsg{Admin: admAddr, Target: fopAddr}, CodeWrongSigner},
+		{"invalid account", BaseFreezeAccountMsg{Admin: admAddr, Target: assetAddr}, CodeWrongSigner},
+		{"ok", BaseFreezeAccountMsg{Admin: admAddr, Target: inopAddr}, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := unfreezeOperatorMsgHandler{accts: accts}
+			got := h.Do(ctx, UnfreezeOperatorMsg{tt.msg})
+			assert.Equal(t, tt.want, got.Code, got.Log)
+			if got.Code == sdk.CodeOK {
+				acct := accts.GetAccount(ctx, tt.msg.Target)
+				ca := acct.(*AppAccount)
+				assert.True(t, ca.IsActive())
+			}
+		})
+	}
+}
+
+func Test_unfreezeAdminMsgHandler_Do(t *testing.T) {
+	accts, ctx := fakeAccountMapper()
+	chAdmin, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdmAddr := chAdmin.Address
+	inactiveChAdmin, _ := fakeInactiveAdmin(accts, ctx, EntityClearingHouse)
+	inChAdmAddr := inactiveChAdmin.Address
+	operator, _ := fakeInactiveUser(accts, ctx, EntityIndividualClearingMember)
+	opAddr := operator.Address
+	foreignAdmin, _ := fakeAdmin(accts, ctx, EntityIndividualClearingMember)
+	foaAddr := foreignAdmin.Address
+	foreignInactiveAdmin, _ := fakeInactiveAdmin(accts, ctx, EntityIndividualClearingMember)
+	foaInAddr := foreignInactiveAdmin.Address
+	tests := []struct {
+		name string
+		msg  BaseFreezeAccountMsg
+		want sdk.CodeType
+	}{
+		{"inactive admin", BaseFreezeAccountMsg{Admin: inChAdmAddr, Target: foaInAddr}, CodeInactiveAccount},
+		{"active target", BaseFreezeAccountMsg{Admin: chAdmAddr, Target: foaAddr}, CodeInvalidAccount},
+		{"foreign admin can't unfreeze", BaseFreezeAccountMsg{Admin: foaAddr, Target: foaInAddr}, CodeWrongSigner},
+		{"can't unfreeze op", BaseFreezeAccountMsg{Admin: chAdmAddr, Target: opAddr}, CodeWrongSigner},
+		{"ok", BaseFreezeAccountMsg{Admin: chAdmAddr, Target: foaInAddr}, sdk.CodeOK},
+		{"ok CH admin", BaseFreezeAccountMsg{Admin: chAdmAddr, Target: inChAdmAddr}, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := unfreezeAdminMsgHandler{accts: accts}
+			got := h.Do(ctx, UnfreezeAdminMsg{tt.msg})
+			assert.Equal(t, tt.want, got.Code, got.Log)
+			if got.Code == sdk.CodeOK {
+				acct := accts.GetAccount(ctx, tt.msg.Target)
+				ca := acct.(*AppAccount)
+				assert.True(t, ca.IsActive())
+			}
+		})
+	}
+}
+
+func Test_freezeAssetAccountMsgHandler_Do(t *testing.T) {
+	accts, ctx := fakeAccountMapper()
+	chAdmin, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdmAddr := chAdmin.Address
+	admin, _ := fakeAdminWithEntityName(accts, ctx, "member", EntityGeneralClearingMember)
+	admAddr := admin.Address
+	inactiveAdmin, _ := fakeAdminWithEntityName(accts, ctx, "member", EntityGeneralClearingMember)
+	inactiveAdmin.Active = false
+	accts.SetAccount(ctx, inactiveAdmin)
+	inadmAddr := inactiveAdmin.Address
+	operator, _ := fakeUserWithEntityName(accts, ctx, "member", EntityGeneralClearingMember)
+	opAddr := operator.Address
+	_, asset := fakeAssetWithEntityName(accts, ctx, nil, "member", EntityGeneralClearingMember)
+	_, asset2 := fakeAssetWithEntityName(accts, ctx, nil, "member", EntityGeneralClearingMember)
+	_, foreignAsset := fakeAsset(accts, ctx, nil, EntityCustodian)
+	_, inactiveAsset := fakeInactiveAssetWithEntityName(accts, ctx, nil, "member", EntityGeneralClearingMember)
+	tests := []struct {
+		name string
+		msg  BaseFreezeAccountMsg
+		want sdk.CodeType
+	}{
+		{"inactive admin", BaseFreezeAccountMsg{Admin: inadmAddr, Target: asset}, CodeInactiveAccount},
+		{"op can't freeze", BaseFreezeAccountMsg{Admin: opAddr, Target: asset}, CodeWrongSigner},
+		{"foreign asset", BaseFreezeAccountMsg{Admin: admAddr, Target: foreignAsset}, CodeWrongSigner},
+		{"not an asset", BaseFreezeAccountMsg{Admin: admAddr, Target: opAddr}, CodeWrongSigner},
+		{"already frozen", BaseFreezeAccountMsg{Admin: admAddr, Target: inactiveAsset}, CodeInactiveAccount},
+		{"ok", BaseFreezeAccountMsg{Admin: admAddr, Target: asset}, sdk.CodeOK},
+		{"CH admin can freeze any asset", BaseFreezeAccountMsg{Admin: chAdmAddr, Target: asset2}, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := freezeAssetAccountMsgHandler{accts: accts}
+			got := h.Do(ctx, FreezeAssetAccountMsg{tt.msg})
+			assert.Equal(t, tt.want, got.Code, got.Log)
+			if got.Code == sdk.CodeOK {
+				acct := accts.GetAccount(ctx, tt.msg.Target)
+				ca := acct.(*AppAccount)
+				assert.False(t, ca.IsActive())
+			}
+		})
+	}
+}
+
+func Test_unfreezeAssetAccountMsgHandler_Do(t *testing.T) {
+	accts, ctx := fakeAccountMapper()
+	chAdmin, _ := fakeAdmin(accts, ctx, EntityClearingHouse)
+	chAdmAddr := chAdmin.Address
+	admin, _ := fakeAdminWithEntityName(accts, ctx, "member", EntityGeneralClearingMember)
+	admAddr := admin.Address
+	_, asset := fakeAssetWithEntityName(accts, ctx, nil, "member", EntityGeneralClearingMember)
+	_, inactiveAsset := fakeInactiveAssetWithEntityName(accts, ctx, nil, "member", EntityGeneralClearingMember)
+	_, inactiveAsset2 := fakeInactiveAssetWithEntityName(accts, ctx, nil, "member", EntityGeneralClearingMember)
+	_, foreignAsset := fakeInactiveAssetWithEntityName(accts, ctx, nil, EntityCustodian, EntityCustodian)
+	tests := []struct {
+		name string
+		msg  BaseFreezeAccountMsg
+		want sdk.CodeType
+	}{
+		{"active asset", BaseFreezeAccountMsg{Admin: admAddr, Target: asset}, CodeInvalidAccount},
+		{"foreign asset", BaseFreezeAccountMsg{Admin: admAddr, Target: foreignAsset}, CodeWrongSigner},
+		{"ok", BaseFreezeAccountMsg{Admin: admAddr, Target: inactiveAsset}, sdk.CodeOK},
+		{"CH admin can unfreeze any asset", BaseFreezeAccountMsg{Admin: chAdmAddr, Target: inactiveAsset2}, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := unfreezeAssetAccountMsgHandler{accts: accts}
+			got := h.Do(ctx, UnfreezeAssetAccountMsg{tt.msg})
+			assert.Equal(t, tt.want, got.Code, got.Log)
+			if got.Code == sdk.CodeOK {
+				acct := accts.GetAccount(ctx, tt.msg.Target)
+				ca := acct.(*AppAccount)
+				assert.True(t, ca.IsActive())
+			}
+		})
+	}
+}
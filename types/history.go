@@ -0,0 +1,138 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	tmtypes "github.com/tendermint/tendermint/types"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+// History pagination defaults.
+const (
+	// DefaultHistoryLimit is the page size used when none is given.
+	DefaultHistoryLimit = 30
+	// MaxHistoryLimit caps the page size.
+	MaxHistoryLimit = 100
+)
+
+// Posting records a change of an asset account's balance.
+// Positive amounts credit the account, negative amounts debit it.
+type Posting struct {
+	Account      sdk.Address
+	Counterparty sdk.Address
+	Amount       sdk.Coin
+	MsgType      string
+	TxHash       cmn.HexBytes
+	Height       int64
+}
+
+// HistoryPage is a page of an account's postings, newest first.
+type HistoryPage struct {
+	Total    int64
+	Page     int64
+	Limit    int64
+	Postings []Posting
+}
+
+// HistoryMapper journals the postings of each asset account.
+type HistoryMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+// NewHistoryMapper creates a history mapper given a storekey.
+func NewHistoryMapper(key sdk.StoreKey) HistoryMapper {
+	return HistoryMapper{
+		key: key,
+		cdc: wire.NewCodec(),
+	}
+}
+
+// AddTransfer records the postings of a transfer on
+// both the sender's and the recipient's history.
+func (hm HistoryMapper) AddTransfer(ctx sdk.Context, msgType string, sender, recipient sdk.Address, amount sdk.Coin) {
+	var txHash cmn.HexBytes
+	if txBytes := ctx.TxBytes(); len(txBytes) != 0 {
+		txHash = tmtypes.Tx(txBytes).Hash()
+	}
+	debit := sdk.Coin{Denom: amount.Denom, Amount: -amount.Amount}
+	hm.addPosting(ctx, Posting{sender, recipient, debit, msgType, txHash, ctx.BlockHeight()})
+	hm.addPosting(ctx, Posting{recipient, sender, amount, msgType, txHash, ctx.BlockHeight()})
+}
+
+func (hm HistoryMapper) addPosting(ctx sdk.Context, p Posting) {
+	store := ctx.KVStore(hm.key)
+	count := hm.GetPostingCount(ctx, p.Account)
+	store.Set(PostingKey(p.Account, p.Height, count), hm.mustMarshal(p))
+	store.Set(PostingCountKey(p.Account), hm.mustMarshal(count+1))
+}
+
+// GetPostingCount returns the number of postings of an account.
+func (hm HistoryMapper) GetPostingCount(ctx sdk.Context, addr sdk.Address) int64 {
+	bz := ctx.KVStore(hm.key).Get(PostingCountKey(addr))
+	if bz == nil {
+		return 0
+	}
+	var count int64
+	hm.mustUnmarshal(bz, &count)
+	return count
+}
+
+// GetPostings returns a page of an account's postings, newest first.
+// Pages start at 1, limit must be between 1 and MaxHistoryLimit.
+func (hm HistoryMapper) GetPostings(ctx sdk.Context, addr sdk.Address, page, limit int64) HistoryPage {
+	res := HistoryPage{
+		Total:    hm.GetPostingCount(ctx, addr),
+		Page:     page,
+		Limit:    limit,
+		Postings: []Posting{},
+	}
+	prefix := postingPrefix(addr)
+	iter := ctx.KVStore(hm.key).ReverseIterator(prefix, prefixEndBytes(prefix))
+	defer iter.Close()
+	skip := (page - 1) * limit
+	for ; iter.Valid() && int64(len(res.Postings)) < limit; iter.Next() {
+		if skip > 0 {
+			skip--
+			continue
+		}
+		var p Posting
+		hm.mustUnmarshal(iter.Value(), &p)
+		res.Postings = append(res.Postings, p)
+	}
+	return res
+}
+
+func (hm HistoryMapper) mustMarshal(v interface{}) []byte {
+	bz, err := hm.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (hm HistoryMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := hm.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+func postingPrefix(addr sdk.Address) []byte {
+	return []byte(fmt.Sprintf("history/postings/%s/", hex.EncodeToString(addr)))
+}
+
+// PostingKey stores a posting under "history/postings/address/height/index",
+// the address being hex encoded. Height and index, the account's posting
+// count, are zero-padded so that postings are iterated in order.
+func PostingKey(addr sdk.Address, height, index int64) []byte {
+	return append(postingPrefix(addr), []byte(fmt.Sprintf("%020d/%020d", height, index))...)
+}
+
+// PostingCountKey stores an account's number of postings
+// under "history/count/address", the address being hex encoded.
+func PostingCountKey(addr sdk.Address) []byte {
+	return []byte(fmt.Sprintf("history/count/%s", hex.EncodeToString(addr)))
+}
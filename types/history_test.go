@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestHistoryMapper(t *testing.T) {
+	key, ctx := fakeStore()
+	hm := NewHistoryMapper(key)
+	cust := crypto.GenPrivKeyEd25519().PubKey().Address()
+	member := crypto.GenPrivKeyEd25519().PubKey().Address()
+	other := crypto.GenPrivKeyEd25519().PubKey().Address()
+	txBytes := []byte("tx")
+	hash := tmtypes.Tx(ctx.TxBytes()).Hash()
+
+	hm.AddTransfer(ctx.WithTxBytes(txBytes), DepositType, cust, member, sdk.Coin{"USD", 700})
+	hm.AddTransfer(ctx.WithBlockHeight(101), WithdrawType, member, cust, sdk.Coin{"USD", 200})
+	hm.AddTransfer(ctx.WithBlockHeight(102), SettlementType, other, member, sdk.Coin{"EUR", -50})
+	assert.Equal(t, int64(3), hm.GetPostingCount(ctx, member))
+	assert.Equal(t, int64(2), hm.GetPostingCount(ctx, cust))
+	assert.Equal(t, int64(0), hm.GetPostingCount(ctx, crypto.GenPrivKeyEd25519().PubKey().Address()))
+
+	// newest first
+	got := hm.GetPostings(ctx, member, 1, 2)
+	assert.Equal(t, HistoryPage{Total: 3, Page: 1, Limit: 2, Postings: []Posting{
+		{member, other, sdk.Coin{"EUR", -50}, SettlementType, hash, 102},
+		{member, cust, sdk.Coin{"USD", -200}, WithdrawType, hash, 101},
+	}}, got)
+	got = hm.GetPostings(ctx, member, 2, 2)
+	assert.Equal(t, []Posting{
+		{member, cust, sdk.Coin{"USD", 700}, DepositType, tmtypes.Tx(txBytes).Hash(), 100},
+	}, got.Postings)
+	got = hm.GetPostings(ctx, member, 3, 2)
+	assert.Equal(t, []Posting{}, got.Postings)
+
+	// the counterparty's history mirrors the posting
+	got = hm.GetPostings(ctx, cust, 1, DefaultHistoryLimit)
+	assert.Equal(t, []Posting{
+		{cust, member, sdk.Coin{"USD", 200}, WithdrawType, hash, 101},
+		{cust, member, sdk.Coin{"USD", -700}, DepositType, tmtypes.Tx(txBytes).Hash(), 100},
+	}, got.Postings)
+}
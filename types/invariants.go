@@ -0,0 +1,121 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Ledger invariants, the application checks them at the end of
+// every InvariantParams.CheckInterval blocks. Only conservation breaks
+// halt the chain: the handlers let custodians overdraw on deposits and
+// admins lower a credit limit below a member's debt, so the member-limit
+// and custodian-balance violations flag accounts to follow up on.
+const (
+	// InvariantConservation: per currency, the clearing house's and members'
+	// balances sum to the external funds, deposits net of withdrawals, and
+	// all asset accounts, custodians included, to the genesis balances.
+	InvariantConservation = "conservation"
+	// InvariantMemberLimit: members' balances are within their credit limits.
+	InvariantMemberLimit = "member-limit"
+	// InvariantCustodianBalance: custodians' balances are not negative.
+	InvariantCustodianBalance = "custodian-balance"
+	// InvariantCreatorExists: the creator of every account exists,
+	// genesis accounts have none.
+	InvariantCreatorExists = "creator-exists"
+	// InvariantJournal: asset accounts' balances equal the sum of their journal lines.
+	InvariantJournal = "journal"
+	// InvariantEntityRegistered: every account's legal entity is registered with its type.
+	InvariantEntityRegistered = "entity-registered"
+)
+
+// DefaultInvariantCheckInterval is the number of blocks between
+// two checks of the ledger invariants unless configured otherwise.
+const DefaultInvariantCheckInterval int64 = 100
+
+// InvariantParams configures how often the invariants are checked,
+// checking them costs a walk through every account.
+type InvariantParams struct {
+	CheckInterval int64 `json:"check_interval"`
+}
+
+// DefaultInvariantParams returns the parameters used when
+// the genesis state does not configure the invariants.
+func DefaultInvariantParams() InvariantParams {
+	return InvariantParams{CheckInterval: DefaultInvariantCheckInterval}
+}
+
+// Validate ensures that the parameters are consistent.
+func (p InvariantParams) Validate() error {
+	if p.CheckInterval <= 0 {
+		return fmt.Errorf("check_interval must be positive")
+	}
+	return nil
+}
+
+// IsCheckDue returns true if the invariants are checked at the given
+// height, blocks start at height 1.
+func (p InvariantParams) IsCheckDue(height int64) bool {
+	return height > 0 && height%p.CheckInterval == 0
+}
+
+// InvariantViolation reports a broken ledger invariant.
+type InvariantViolation struct {
+	Invariant   string      `json:"invariant"`
+	Account     sdk.Address `json:"account,omitempty"`
+	Description string      `json:"description"`
+}
+
+func (v InvariantViolation) String() string {
+	if len(v.Account) == 0 {
+		return fmt.Sprintf("%s: %s", v.Invariant, v.Description)
+	}
+	return fmt.Sprintf("%s: %v %s", v.Invariant, v.Account, v.Description)
+}
+
+// CheckInvariants checks the ledger invariants against every
+// account and returns the violations, if any.
+func CheckInvariants(ctx sdk.Context, accts IndexedAccountMapper, journal JournalMapper) []InvariantViolation {
+	violations := []InvariantViolation{}
+	report := func(invariant string, addr sdk.Address, format string, args ...interface{}) {
+		violations = append(violations, InvariantViolation{invariant, addr, fmt.Sprintf(format, args...)})
+	}
+	var total, internal sdk.Coins
+	accts.IterateAccounts(ctx, func(acc *AppAccount) bool {
+		if len(acc.Creator) != 0 && accts.GetAccount(ctx, acc.Creator) == nil {
+			report(InvariantCreatorExists, acc.Address, "was created by unknown account %v", acc.Creator)
+		}
+		if entity, found := accts.entities.GetEntityByName(ctx, acc.EntityName); !found {
+			report(InvariantEntityRegistered, acc.Address, "belongs to unregistered entity %q", acc.EntityName)
+		} else if entity.Type != acc.EntityType {
+			report(InvariantEntityRegistered, acc.Address, "is a %s account of %s entity %q", acc.EntityType, entity.Type, entity.Name)
+		}
+		if !acc.IsAsset() {
+			return false
+		}
+		total = total.Plus(acc.Coins)
+		if !IsCustodian(acc) {
+			internal = internal.Plus(acc.Coins)
+		}
+		for _, coin := range acc.Coins {
+			if IsMember(acc) && checkCreditLimit(acc, coin.Denom) != nil {
+				report(InvariantMemberLimit, acc.Address, "holds %v, its credit limit is %d%s",
+					coin, acc.GetCreditLimit(coin.Denom), coin.Denom)
+			}
+			if IsCustodian(acc) && coin.Amount < 0 {
+				report(InvariantCustodianBalance, acc.Address, "holds %v", coin)
+			}
+		}
+		if err := journal.VerifyBalance(ctx, acc); err != nil {
+			report(InvariantJournal, acc.Address, "%v", err)
+		}
+		return false
+	})
+	if external := journal.GetExternalFunds(ctx); !internal.Minus(external).IsZero() {
+		report(InvariantConservation, nil, "the clearing house and members hold %v, external funds amount to %v", internal, external)
+	}
+	if opening := journal.GetBalance(ctx, OpeningBalancesAddress).Negative(); !total.Minus(opening).IsZero() {
+		report(InvariantConservation, nil, "asset accounts hold %v, genesis balances amount to %v", total, opening)
+	}
+	return violations
+}
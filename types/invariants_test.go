@@ -0,0 +1,110 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestCheckInvariants(t *testing.T) {
+	type transferFunc func(msgType string, from, to *AppAccount, coin sdk.Coin)
+	tests := []struct {
+		name   string
+		change func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc)
+		want   []string
+	}{
+		{"consistent ledger", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			transfer(DepositType, getEntityAsset(ctx, accts, "CUST"), getEntityAsset(ctx, accts, "ICM"), sdk.Coin{"USD", 300})
+			transfer(SettlementType, getEntityAsset(ctx, accts, "ICM"), getEntityAsset(ctx, accts, "CH"), sdk.Coin{"USD", 100})
+			transfer(WithdrawType, getEntityAsset(ctx, accts, "ICM"), getEntityAsset(ctx, accts, "CUST"), sdk.Coin{"USD", 50})
+		}, []string{}},
+		{"member beyond its credit limit", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			transfer(SettlementType, getEntityAsset(ctx, accts, "ICM"), getEntityAsset(ctx, accts, "CH"), sdk.Coin{"USD", 100})
+		}, []string{InvariantMemberLimit}},
+		{"negative custodian", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			transfer(DepositType, getEntityAsset(ctx, accts, "CUST"), getEntityAsset(ctx, accts, "ICM"), sdk.Coin{"USD", 600})
+		}, []string{InvariantCustodianBalance}},
+		{"unknown creator", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			op, _ := makeUser("ICM", EntityIndividualClearingMember)
+			op.Creator = crypto.GenPrivKeyEd25519().PubKey().Address()
+			accts.SetAccount(ctx, op)
+		}, []string{InvariantCreatorExists}},
+		{"deposit not counted as external funds", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			transfer(SettlementType, getEntityAsset(ctx, accts, "CUST"), getEntityAsset(ctx, accts, "ICM"), sdk.Coin{"USD", 300})
+		}, []string{InvariantConservation}},
+		{"unjournaled balance", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			ch := getEntityAsset(ctx, accts, "CH")
+			ch.Coins = ch.Coins.Plus(sdk.Coins{{"USD", 1}})
+			accts.SetAccount(ctx, ch)
+		}, []string{InvariantJournal, InvariantConservation, InvariantConservation}},
+		{"unregistered entity", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			op, _ := makeUser("GCM", EntityGeneralClearingMember)
+			accts.SetAccount(ctx, op)
+		}, []string{InvariantEntityRegistered}},
+		{"account of another entity type", func(ctx sdk.Context, accts IndexedAccountMapper, transfer transferFunc) {
+			op, _ := makeUser("CUST", EntityGeneralClearingMember)
+			accts.SetAccount(ctx, op)
+		}, []string{InvariantEntityRegistered}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ctx := fakeStore()
+			entities := NewEntityMapper(key)
+			accts := NewIndexedAccountMapper(key, entities)
+			ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+			admin, _ := makeAdminUser("CH", EntityClearingHouse)
+			op, _ := makeUser("CH", EntityClearingHouse)
+			op.Creator = admin.Address
+			ch, _ := makeAssetAccount(sdk.Coins{{"EUR", 1000}, {"USD", 50}}, "CH", EntityClearingHouse)
+			member, _ := makeAssetAccount(sdk.Coins{{"USD", -50}}, "ICM", EntityIndividualClearingMember)
+			member.SetCreditLimit(sdk.Coin{"USD", 100})
+			cust, _ := makeAssetAccount(sdk.Coins{{"USD", 500}}, "CUST", EntityCustodian)
+			for _, e := range []Entity{
+				NewEntity("ch", "CH", EntityClearingHouse, "", ""),
+				NewEntity("icm", "ICM", EntityIndividualClearingMember, "", ""),
+				NewEntity("cust", "CUST", EntityCustodian, "", ""),
+			} {
+				entities.SetEntity(ctx, e)
+			}
+			genesis := []*AppAccount{admin, op, ch, member, cust}
+			for _, acc := range genesis {
+				accts.SetAccount(ctx, acc)
+			}
+			ledger.Journal.AddOpeningBalances(ctx, genesis)
+
+			tt.change(ctx, accts, func(msgType string, from, to *AppAccount, coin sdk.Coin) {
+				assert.Nil(t, transferMoney(from, to, coin, false, false))
+				accts.SetAccount(ctx, from)
+				accts.SetAccount(ctx, to)
+				ledger.AddTransfer(ctx, msgType, from.Address, to.Address, coin)
+			})
+			got := []string{}
+			for _, v := range CheckInvariants(ctx, accts, ledger.Journal) {
+				got = append(got, v.Invariant)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func getEntityAsset(ctx sdk.Context, accts IndexedAccountMapper, entityName string) *AppAccount {
+	for _, acc := range accts.GetEntityAccounts(ctx, entityName) {
+		if acc.IsAsset() {
+			return acc
+		}
+	}
+	return nil
+}
+
+func TestInvariantParams_IsCheckDue(t *testing.T) {
+	params := InvariantParams{CheckInterval: 10}
+	assert.Nil(t, params.Validate())
+	assert.False(t, params.IsCheckDue(0))
+	assert.False(t, params.IsCheckDue(9))
+	assert.True(t, params.IsCheckDue(10))
+	assert.True(t, params.IsCheckDue(20))
+	assert.NotNil(t, InvariantParams{}.Validate())
+	assert.True(t, DefaultInvariantParams().IsCheckDue(DefaultInvariantCheckInterval))
+}
@@ -0,0 +1,294 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+	tmtypes "github.com/tendermint/tendermint/types"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+// GenesisEntryType is the type of the entry that journals the
+// asset accounts' balances when the chain is initialized.
+const GenesisEntryType = "genesis"
+
+// OpeningBalancesAddress is the journal's counterpart of the genesis
+// balances, it is not an account: no key hashes to 20 zero bytes.
+var OpeningBalancesAddress = sdk.Address(make([]byte, AddressLength))
+
+// JournalLine changes an account's balance: negative amounts
+// debit the account, positive amounts credit it.
+type JournalLine struct {
+	Account sdk.Address
+	Amount  sdk.Coin
+}
+
+// JournalEntry records the balance changes of a transaction.
+// Its lines sum to zero in every currency.
+type JournalEntry struct {
+	ID      int64
+	Height  int64
+	TxHash  cmn.HexBytes
+	MsgType string
+	Lines   []JournalLine
+}
+
+// TransferLines returns the lines that move the amount from the sender to the recipient.
+func TransferLines(sender, recipient sdk.Address, amount sdk.Coin) []JournalLine {
+	return []JournalLine{
+		{sender, sdk.Coin{Denom: amount.Denom, Amount: -amount.Amount}},
+		{recipient, amount},
+	}
+}
+
+// ValidateLines ensures that the lines balance in every currency.
+func ValidateLines(lines []JournalLine) error {
+	if len(lines) < 2 {
+		return fmt.Errorf("entries need at least two lines, got %d", len(lines))
+	}
+	var sum sdk.Coins
+	for _, line := range lines {
+		if len(line.Account) != AddressLength {
+			return fmt.Errorf("invalid account %v", line.Account)
+		}
+		if line.Amount.IsZero() || !ValidateDenom(line.Amount.Denom) {
+			return fmt.Errorf("invalid amount %v", line.Amount)
+		}
+		sum = sum.Plus(sdk.Coins{line.Amount})
+	}
+	if !sum.IsZero() {
+		return fmt.Errorf("lines do not balance, they sum to %v", sum)
+	}
+	return nil
+}
+
+// JournalMapper stores the journal entries
+// and the balances they sum to per account.
+type JournalMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+// NewJournalMapper creates a journal mapper given a storekey.
+func NewJournalMapper(key sdk.StoreKey) JournalMapper {
+	return JournalMapper{
+		key: key,
+		cdc: wire.NewCodec(),
+	}
+}
+
+// AddEntry journals the lines of the context's transaction and returns the new entry.
+// It panics if the lines do not balance: handlers must never create or destroy money.
+func (jm JournalMapper) AddEntry(ctx sdk.Context, msgType string, lines []JournalLine) JournalEntry {
+	if err := ValidateLines(lines); err != nil {
+		panic(fmt.Sprintf("unbalanced %s entry: %v", msgType, err))
+	}
+	store := ctx.KVStore(jm.key)
+	var id int64 = 1
+	if bz := store.Get(NextJournalEntryIDKey()); bz != nil {
+		jm.mustUnmarshal(bz, &id)
+	}
+	entry := JournalEntry{
+		ID:      id,
+		Height:  ctx.BlockHeight(),
+		MsgType: msgType,
+		Lines:   lines,
+	}
+	if txBytes := ctx.TxBytes(); len(txBytes) != 0 {
+		entry.TxHash = tmtypes.Tx(txBytes).Hash()
+	}
+	for _, line := range lines {
+		balance := jm.GetBalance(ctx, line.Account).Plus(sdk.Coins{line.Amount})
+		store.Set(JournalBalanceKey(line.Account), jm.mustMarshal(balance))
+	}
+	store.Set(NextJournalEntryIDKey(), jm.mustMarshal(id+1))
+	store.Set(JournalEntryKey(id), jm.mustMarshal(entry))
+	return entry
+}
+
+// AddOpeningBalances journals the accounts' balances against OpeningBalancesAddress,
+// if any is not zero. The clearing house's and members' balances open the external funds.
+func (jm JournalMapper) AddOpeningBalances(ctx sdk.Context, accounts []*AppAccount) {
+	var lines []JournalLine
+	var total, external sdk.Coins
+	for _, acc := range accounts {
+		for _, coin := range acc.Coins {
+			if coin.IsZero() {
+				continue
+			}
+			lines = append(lines, JournalLine{acc.Address, coin})
+			total = total.Plus(sdk.Coins{coin})
+			if !IsCustodian(acc) {
+				external = external.Plus(sdk.Coins{coin})
+			}
+		}
+	}
+	jm.AddExternalFunds(ctx, external)
+	for _, coin := range total.Negative() {
+		lines = append(lines, JournalLine{OpeningBalancesAddress, coin})
+	}
+	if len(lines) != 0 {
+		jm.AddEntry(ctx, GenesisEntryType, lines)
+	}
+}
+
+// GetEntry returns a journal entry.
+func (jm JournalMapper) GetEntry(ctx sdk.Context, id int64) (entry JournalEntry, found bool) {
+	bz := ctx.KVStore(jm.key).Get(JournalEntryKey(id))
+	if bz == nil {
+		return entry, false
+	}
+	jm.mustUnmarshal(bz, &entry)
+	return entry, true
+}
+
+// IterateEntries calls process on the journal entries, oldest first,
+// until it returns true.
+func (jm JournalMapper) IterateEntries(ctx sdk.Context, process func(JournalEntry) (stop bool)) {
+	prefix := []byte("journal/entries/")
+	iter := ctx.KVStore(jm.key).Iterator(prefix, prefixEndBytes(prefix))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var entry JournalEntry
+		jm.mustUnmarshal(iter.Value(), &entry)
+		if process(entry) {
+			return
+		}
+	}
+}
+
+// GetBalance returns the sum of the account's journal lines.
+func (jm JournalMapper) GetBalance(ctx sdk.Context, addr sdk.Address) sdk.Coins {
+	bz := ctx.KVStore(jm.key).Get(JournalBalanceKey(addr))
+	if bz == nil {
+		return nil
+	}
+	var balance sdk.Coins
+	jm.mustUnmarshal(bz, &balance)
+	return balance
+}
+
+// AddExternalFunds adds the coins, which may be negative, to the external funds.
+func (jm JournalMapper) AddExternalFunds(ctx sdk.Context, coins sdk.Coins) {
+	external := jm.GetExternalFunds(ctx).Plus(coins)
+	ctx.KVStore(jm.key).Set(ExternalFundsKey(), jm.mustMarshal(external))
+}
+
+// GetExternalFunds returns the funds deposited into the clearing house's and
+// members' accounts, net of withdrawals, including their genesis balances.
+func (jm JournalMapper) GetExternalFunds(ctx sdk.Context) sdk.Coins {
+	bz := ctx.KVStore(jm.key).Get(ExternalFundsKey())
+	if bz == nil {
+		return nil
+	}
+	var external sdk.Coins
+	jm.mustUnmarshal(bz, &external)
+	return external
+}
+
+// JournalBalance compares an account's coins with the sum of its journal lines.
+type JournalBalance struct {
+	Account    sdk.Address
+	Coins      sdk.Coins
+	Journaled  sdk.Coins
+	Reconciled bool
+}
+
+// NewJournalBalance returns the account's journal balance given the sum of its lines.
+func NewJournalBalance(acc *AppAccount, journaled sdk.Coins) JournalBalance {
+	return JournalBalance{
+		Account:    acc.Address,
+		Coins:      acc.Coins,
+		Journaled:  journaled,
+		Reconciled: acc.Coins.Minus(journaled).IsZero(),
+	}
+}
+
+// VerifyBalance ensures that the account's coins equal the sum of its journal lines.
+func (jm JournalMapper) VerifyBalance(ctx sdk.Context, acc *AppAccount) error {
+	if b := NewJournalBalance(acc, jm.GetBalance(ctx, acc.Address)); !b.Reconciled {
+		return fmt.Errorf("account %v holds %v but its journal lines sum to %v", b.Account, b.Coins, b.Journaled)
+	}
+	return nil
+}
+
+func (jm JournalMapper) mustMarshal(v interface{}) []byte {
+	bz, err := jm.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (jm JournalMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := jm.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+// NextJournalEntryIDKey stores the next journal entry's ID under "journal/next".
+func NextJournalEntryIDKey() []byte {
+	return []byte("journal/next")
+}
+
+// JournalEntryKey stores a journal entry under "journal/entries/id",
+// the ID is zero-padded so that entries are iterated in order.
+func JournalEntryKey(id int64) []byte {
+	return []byte(fmt.Sprintf("journal/entries/%020d", id))
+}
+
+// JournalBalanceKey stores the sum of an account's journal lines
+// under "journal/balances/address", the address being hex encoded.
+func JournalBalanceKey(addr sdk.Address) []byte {
+	return []byte(fmt.Sprintf("journal/balances/%s", hex.EncodeToString(addr)))
+}
+
+// ExternalFundsKey stores the external funds under "journal/external".
+func ExternalFundsKey() []byte {
+	return []byte("journal/external")
+}
+
+// Ledger records the balance changes of asset accounts: every
+// transfer is journaled and posted to both accounts' history.
+type Ledger struct {
+	Journal JournalMapper
+	History HistoryMapper
+}
+
+// NewLedger creates a ledger given its journal and history mappers.
+func NewLedger(journal JournalMapper, history HistoryMapper) Ledger {
+	return Ledger{journal, history}
+}
+
+// AddTransfer records the transfer of the amount from the sender to the recipient.
+// Deposits add to the external funds, withdrawals subtract from them.
+func (l Ledger) AddTransfer(ctx sdk.Context, msgType string, sender, recipient sdk.Address, amount sdk.Coin) {
+	l.AddTransfers(ctx, msgType, sender, []SettleLeg{{recipient, amount}})
+}
+
+// AddTransfers records the transfers of the legs' amounts from the sender to their
+// recipients as a single journal entry, each leg is posted to both accounts' history.
+// Nothing is recorded without legs, e.g. when a cycle's obligations cancel out.
+func (l Ledger) AddTransfers(ctx sdk.Context, msgType string, sender sdk.Address, legs []SettleLeg) {
+	if len(legs) == 0 {
+		return
+	}
+	var lines []JournalLine
+	var total sdk.Coins
+	for _, leg := range legs {
+		lines = append(lines, TransferLines(sender, leg.Recipient, leg.Amount)...)
+		total = total.Plus(sdk.Coins{leg.Amount})
+	}
+	l.Journal.AddEntry(ctx, msgType, lines)
+	switch msgType {
+	case DepositType:
+		l.Journal.AddExternalFunds(ctx, total)
+	case WithdrawType:
+		l.Journal.AddExternalFunds(ctx, total.Negative())
+	}
+	for _, leg := range legs {
+		l.History.AddTransfer(ctx, msgType, sender, leg.Recipient, leg.Amount)
+	}
+}
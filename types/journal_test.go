@@ -0,0 +1,136 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestValidateLines(t *testing.T) {
+	a := crypto.GenPrivKeyEd25519().PubKey().Address()
+	b := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name    string
+		lines   []JournalLine
+		wantErr bool
+	}{
+		{"transfer", TransferLines(a, b, sdk.Coin{"USD", 100}), false},
+		{"negative transfer", TransferLines(a, b, sdk.Coin{"USD", -100}), false},
+		{"currencies balance separately", []JournalLine{
+			{a, sdk.Coin{"USD", -100}}, {b, sdk.Coin{"EUR", 50}}, {a, sdk.Coin{"EUR", -50}}, {b, sdk.Coin{"USD", 100}},
+		}, false},
+		{"single line", []JournalLine{{a, sdk.Coin{"USD", 100}}}, true},
+		{"unbalanced", []JournalLine{{a, sdk.Coin{"USD", -100}}, {b, sdk.Coin{"USD", 99}}}, true},
+		{"unbalanced currencies", []JournalLine{{a, sdk.Coin{"USD", -100}}, {b, sdk.Coin{"EUR", 100}}}, true},
+		{"zero amount", []JournalLine{{a, sdk.Coin{"USD", 0}}, {b, sdk.Coin{"USD", 0}}}, true},
+		{"unknown currency", TransferLines(a, b, sdk.Coin{"XXX", 100}), true},
+		{"invalid account", TransferLines(a, sdk.Address{1, 2}, sdk.Coin{"USD", 100}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, ValidateLines(tt.lines) != nil)
+		})
+	}
+}
+
+func TestJournalMapper(t *testing.T) {
+	key, ctx := fakeStore()
+	jm := NewJournalMapper(key)
+	accts := NewAccountMapper(key)
+	cust, _ := fakeAsset(accts, ctx, sdk.Coins{{"USD", 1000}}, EntityCustodian)
+	ch, _ := fakeAsset(accts, ctx, sdk.Coins{{"EUR", -20}, {"USD", -300}}, EntityClearingHouse)
+	member, _ := fakeAsset(accts, ctx, nil, EntityIndividualClearingMember)
+
+	jm.AddOpeningBalances(ctx, []*AppAccount{cust, ch, member})
+	genesis, found := jm.GetEntry(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, GenesisEntryType, genesis.MsgType)
+	assert.Equal(t, []JournalLine{
+		{cust.Address, sdk.Coin{"USD", 1000}},
+		{ch.Address, sdk.Coin{"EUR", -20}},
+		{ch.Address, sdk.Coin{"USD", -300}},
+		{OpeningBalancesAddress, sdk.Coin{"EUR", 20}},
+		{OpeningBalancesAddress, sdk.Coin{"USD", -700}},
+	}, genesis.Lines)
+	for _, acc := range []*AppAccount{cust, ch, member} {
+		assert.Nil(t, jm.VerifyBalance(ctx, acc))
+	}
+	assert.Equal(t, sdk.Coins{{"EUR", -20}, {"USD", -300}}, jm.GetExternalFunds(ctx))
+
+	entry := jm.AddEntry(ctx, DepositType, TransferLines(cust.Address, member.Address, sdk.Coin{"USD", 700}))
+	assert.Equal(t, JournalEntry{
+		ID:      2,
+		Height:  ctx.BlockHeight(),
+		TxHash:  tmtypes.Tx(ctx.TxBytes()).Hash(),
+		MsgType: DepositType,
+		Lines:   TransferLines(cust.Address, member.Address, sdk.Coin{"USD", 700}),
+	}, entry)
+	got, found := jm.GetEntry(ctx, 2)
+	assert.True(t, found)
+	assert.Equal(t, entry, got)
+	_, found = jm.GetEntry(ctx, 3)
+	assert.False(t, found)
+	assert.Equal(t, sdk.Coins{{"USD", 300}}, jm.GetBalance(ctx, cust.Address))
+	assert.Equal(t, sdk.Coins{{"USD", 700}}, jm.GetBalance(ctx, member.Address))
+
+	// the accounts were not updated
+	assert.NotNil(t, jm.VerifyBalance(ctx, cust))
+	assert.NotNil(t, jm.VerifyBalance(ctx, member))
+	member.Coins = sdk.Coins{{"USD", 700}}
+	assert.Nil(t, jm.VerifyBalance(ctx, member))
+
+	assert.Panics(t, func() {
+		jm.AddEntry(ctx, DepositType, []JournalLine{{cust.Address, sdk.Coin{"USD", -1}}, {member.Address, sdk.Coin{"USD", 2}}})
+	})
+	var ids []int64
+	jm.IterateEntries(ctx, func(e JournalEntry) bool {
+		ids = append(ids, e.ID)
+		return false
+	})
+	assert.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestLedger_AddTransfer(t *testing.T) {
+	key, ctx := fakeStore()
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	a := crypto.GenPrivKeyEd25519().PubKey().Address()
+	b := crypto.GenPrivKeyEd25519().PubKey().Address()
+
+	ledger.AddTransfer(ctx, SettlementType, a, b, sdk.Coin{"EUR", 40})
+	entry, found := ledger.Journal.GetEntry(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, TransferLines(a, b, sdk.Coin{"EUR", 40}), entry.Lines)
+	assert.Equal(t, int64(1), ledger.History.GetPostingCount(ctx, a))
+	assert.Equal(t, int64(1), ledger.History.GetPostingCount(ctx, b))
+	assert.Nil(t, ledger.Journal.GetExternalFunds(ctx))
+
+	ledger.AddTransfer(ctx, DepositType, a, b, sdk.Coin{"EUR", 100})
+	ledger.AddTransfer(ctx, WithdrawType, b, a, sdk.Coin{"EUR", 30})
+	assert.Equal(t, sdk.Coins{{"EUR", 70}}, ledger.Journal.GetExternalFunds(ctx))
+}
+
+func TestLedger_AddTransfers(t *testing.T) {
+	key, ctx := fakeStore()
+	ledger := NewLedger(NewJournalMapper(key), NewHistoryMapper(key))
+	a := crypto.GenPrivKeyEd25519().PubKey().Address()
+	b := crypto.GenPrivKeyEd25519().PubKey().Address()
+	c := crypto.GenPrivKeyEd25519().PubKey().Address()
+
+	ledger.AddTransfers(ctx, BatchSettlementType, a, nil)
+	_, found := ledger.Journal.GetEntry(ctx, 1)
+	assert.False(t, found)
+
+	ledger.AddTransfers(ctx, BatchSettlementType, a, []SettleLeg{{b, sdk.Coin{"EUR", 40}}, {c, sdk.Coin{"USD", -10}}})
+	entry, found := ledger.Journal.GetEntry(ctx, 1)
+	assert.True(t, found)
+	lines := append(TransferLines(a, b, sdk.Coin{"EUR", 40}), TransferLines(a, c, sdk.Coin{"USD", -10})...)
+	assert.Equal(t, lines, entry.Lines)
+	_, found = ledger.Journal.GetEntry(ctx, 2)
+	assert.False(t, found)
+	assert.Equal(t, int64(2), ledger.History.GetPostingCount(ctx, a))
+	assert.Equal(t, int64(1), ledger.History.GetPostingCount(ctx, b))
+	assert.Equal(t, int64(1), ledger.History.GetPostingCount(ctx, c))
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// secondsPerDay splits block times into the days daily limits apply to.
+const secondsPerDay = 24 * 60 * 60
+
+// OperatorLimits restricts the amounts an operator can move.
+// Amounts are compared in absolute value, as settlements may be negative.
+type OperatorLimits struct {
+	// MaxAmounts caps, per currency, the amount of a single transfer.
+	MaxAmounts sdk.Coins
+	// DailyLimits caps, per currency, the total amount moved in a day.
+	DailyLimits sdk.Coins
+	// DailyUsage holds the amounts moved on Day,
+	// days are counted in block time since the Unix epoch.
+	Day        int64
+	DailyUsage sdk.Coins
+}
+
+// SetLimits replaces the limits for the amounts' denom,
+// zero amounts remove the corresponding limit.
+func (l *OperatorLimits) SetLimits(maxAmount, dailyLimit sdk.Coin) {
+	l.MaxAmounts = replaceAmountOf(l.MaxAmounts, maxAmount)
+	l.DailyLimits = replaceAmountOf(l.DailyLimits, dailyLimit)
+}
+
+// Use adds the amount to the usage of the day the block time falls in.
+// It fails if the amount is above the maximum or if the daily total would
+// go above the daily limit, in which case the usage is left untouched.
+func (l *OperatorLimits) Use(amount sdk.Coin, blockTime int64) sdk.Error {
+	abs := amount
+	if abs.Amount < 0 {
+		abs.Amount = -abs.Amount
+	}
+	if max := l.MaxAmounts.AmountOf(abs.Denom); max != 0 && abs.Amount > max {
+		return ErrOperatorLimitExceeded(fmt.Sprintf("%d%s is above the maximum amount %d%s",
+			abs.Amount, abs.Denom, max, abs.Denom))
+	}
+	day := blockTime / secondsPerDay
+	usage := l.DailyUsage
+	if day != l.Day {
+		usage = nil
+	}
+	usage = usage.Plus(sdk.Coins{abs})
+	if limit := l.DailyLimits.AmountOf(abs.Denom); limit != 0 && usage.AmountOf(abs.Denom) > limit {
+		return ErrOperatorLimitExceeded(fmt.Sprintf("%d%s would take the daily total above %d%s",
+			abs.Amount, abs.Denom, limit, abs.Denom))
+	}
+	l.Day = day
+	l.DailyUsage = usage
+	return nil
+}
+
+// replaceAmountOf replaces the amount of the coin's denom.
+func replaceAmountOf(coins sdk.Coins, coin sdk.Coin) sdk.Coins {
+	delta := sdk.Coin{Denom: coin.Denom, Amount: coin.Amount - coins.AmountOf(coin.Denom)}
+	return coins.Plus(sdk.Coins{delta})
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperatorLimits_Use(t *testing.T) {
+	var limits OperatorLimits
+	limits.SetLimits(sdk.Coin{"USD", 100}, sdk.Coin{"USD", 150})
+	tests := []struct {
+		name      string
+		amount    sdk.Coin
+		blockTime int64
+		wantErr   bool
+		wantUsage sdk.Coins
+	}{
+		{"within limits", sdk.Coin{"USD", 100}, 10, false, sdk.Coins{{"USD", 100}}},
+		{"above the maximum", sdk.Coin{"USD", 101}, 10, true, sdk.Coins{{"USD", 100}}},
+		{"negative amounts count too", sdk.Coin{"USD", -60}, 10, true, sdk.Coins{{"USD", 100}}},
+		{"up to the daily limit", sdk.Coin{"USD", -50}, 10, false, sdk.Coins{{"USD", 150}}},
+		{"no limit in other currencies", sdk.Coin{"EUR", 1000}, 10, false, sdk.Coins{{"EUR", 1000}, {"USD", 150}}},
+		{"next day", sdk.Coin{"USD", 100}, secondsPerDay, false, sdk.Coins{{"USD", 100}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.Use(tt.amount, tt.blockTime)
+			assert.Equal(t, tt.wantErr, err != nil, "%v", err)
+			assert.Equal(t, tt.wantUsage, limits.DailyUsage)
+		})
+	}
+
+	// zero amounts remove limits
+	limits.SetLimits(sdk.Coin{"USD", 0}, sdk.Coin{"USD", 0})
+	assert.Empty(t, limits.MaxAmounts)
+	assert.Empty(t, limits.DailyLimits)
+	assert.Nil(t, limits.Use(sdk.Coin{"USD", 1000}, secondsPerDay))
+}
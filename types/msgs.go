@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	crypto "github.com/tendermint/go-crypto"
@@ -13,27 +15,54 @@ import (
 
 // message types definitions
 const (
-	DepositType            = "deposit"
-	SettlementType         = "settlement"
-	WithdrawType           = "withdraw"
-	CreateOperatorType     = "createOperator"
-	CreateAdminType        = "createAdmin"
-	CreateAssetAccountType = "createAsset"
-	FreezeOperatorType     = "freezeOperator"
-	FreezeAdminType        = "freezeAdmin"
+	DepositType              = "deposit"
+	SettlementType           = "settlement"
+	WithdrawType             = "withdraw"
+	CreateOperatorType       = "createOperator"
+	CreateAdminType          = "createAdmin"
+	CreateAssetAccountType   = "createAsset"
+	FreezeOperatorType       = "freezeOperator"
+	FreezeAdminType          = "freezeAdmin"
+	UnfreezeOperatorType     = "unfreezeOperator"
+	UnfreezeAdminType        = "unfreezeAdmin"
+	FreezeAssetAccountType   = "freezeAsset"
+	UnfreezeAssetAccountType = "unfreezeAsset"
+	BatchSettlementType      = "batchSettlement"
+	SubmitObligationType     = "submitObligation"
+	NetSettlementType        = "netSettlement"
+	CancelNettingCycleType   = "cancelNettingCycle"
+	SetCreditLimitType       = "setCreditLimit"
+	ProposeTransferType      = "proposeTransfer"
+	ApproveTransferType      = "approveTransfer"
+	RejectTransferType       = "rejectTransfer"
+	SetOperatorLimitsType    = "setOperatorLimits"
+	GrantRoleType            = "grantRole"
+	RevokeRoleType           = "revokeRole"
+	RotateKeyType            = "rotateKey"
+	CloseAssetAccountType    = "closeAsset"
+	RegisterEntityType       = "registerEntity"
+	UpdateEntityStatusType   = "updateEntityStatus"
 )
 
 const (
 	// AddressLength represents the number of bytes that compose addresses.
 	AddressLength = 20
+	// MaxReferenceLength caps the length of transfers' references.
+	MaxReferenceLength = 64
+	// MaxMemoLength caps the length of transfers' memos.
+	MaxMemoLength = 256
 )
 
-// DepositMsg defines the properties of an asset transfer
+// DepositMsg defines the properties of an asset transfer.
+// Deposits, settlements and withdrawals may carry an external reference,
+// unique per sender, and a free-text memo.
 type DepositMsg struct {
 	Operator  sdk.Address
 	Sender    sdk.Address
 	Recipient sdk.Address
 	Amount    sdk.Coin
+	Reference string
+	Memo      string
 }
 
 // ensure DepositMsg implements the sdk.Msg interface
@@ -45,8 +74,8 @@ func (msg DepositMsg) ValidateBasic() sdk.Error {
 	if msg.Amount.Amount <= 0 {
 		return ErrInvalidAmount("negative amount")
 	}
-	if msg.Amount.Denom == "" {
-		return ErrInvalidAmount("empty denom")
+	if err := ValidateCoin(msg.Amount); err != nil {
+		return err
 	}
 	if err := validateAddress(msg.Operator); err != nil {
 		return err
@@ -60,7 +89,7 @@ func (msg DepositMsg) ValidateBasic() sdk.Error {
 	if bytes.Equal(msg.Sender, msg.Recipient) {
 		return ErrInvalidAddress("sender and recipient have the same address")
 	}
-	return nil
+	return validateReference(msg.Reference, msg.Memo)
 }
 
 // Type returns the message type.
@@ -90,6 +119,8 @@ type SettleMsg struct {
 	Sender    sdk.Address
 	Recipient sdk.Address
 	Amount    sdk.Coin
+	Reference string
+	Memo      string
 }
 
 var _ sdk.Msg = SettleMsg{}
@@ -100,8 +131,8 @@ func (msg SettleMsg) ValidateBasic() sdk.Error {
 	if msg.Amount.Amount == 0 {
 		return ErrInvalidAmount("empty or 0 amount not allowed")
 	}
-	if msg.Amount.Denom == "" {
-		return ErrInvalidAmount("empty denom")
+	if err := ValidateCoin(msg.Amount); err != nil {
+		return err
 	}
 	if err := validateAddress(msg.Operator); err != nil {
 		return err
@@ -115,7 +146,7 @@ func (msg SettleMsg) ValidateBasic() sdk.Error {
 	if bytes.Equal(msg.Sender, msg.Recipient) {
 		return ErrInvalidAddress("sender and recipient have the same address")
 	}
-	return nil
+	return validateReference(msg.Reference, msg.Memo)
 }
 
 // Type returns the message type.
@@ -139,12 +170,218 @@ func (msg SettleMsg) GetSignBytes() []byte {
 // CONTRACT: Returns addrs in some deterministic order.
 func (msg SettleMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Operator} }
 
+// SettleLeg defines a single leg of a batch settlement.
+type SettleLeg struct {
+	Recipient sdk.Address
+	Amount    sdk.Coin
+}
+
+// ValidateBasic performs basic validation checks on the leg.
+func (leg SettleLeg) ValidateBasic() sdk.Error {
+	// amount may be negative
+	if leg.Amount.Amount == 0 {
+		return ErrInvalidAmount("empty or 0 amount not allowed")
+	}
+	if err := ValidateCoin(leg.Amount); err != nil {
+		return err
+	}
+	return validateAddress(leg.Recipient)
+}
+
+// BatchSettleMsg defines the properties of a multi-leg settle
+// transaction. Legs are applied all-or-nothing.
+type BatchSettleMsg struct {
+	Operator sdk.Address
+	Sender   sdk.Address
+	Legs     []SettleLeg
+}
+
+var _ sdk.Msg = BatchSettleMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg BatchSettleMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Operator); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Sender); err != nil {
+		return err
+	}
+	if len(msg.Legs) == 0 {
+		return ErrInvalidAmount("no legs")
+	}
+	for i, leg := range msg.Legs {
+		if err := leg.ValidateBasic(); err != nil {
+			return err.Trace(fmt.Sprintf("leg %d", i))
+		}
+		if bytes.Equal(msg.Sender, leg.Recipient) {
+			return ErrInvalidAddress(fmt.Sprintf("leg %d: sender and recipient have the same address", i))
+		}
+	}
+	return nil
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg BatchSettleMsg) Type() string { return BatchSettlementType }
+
+// Get some property of the Msg.
+func (msg BatchSettleMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg BatchSettleMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg BatchSettleMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Operator} }
+
+// SubmitObligationMsg defines the properties of a transaction
+// that records a trade obligation between two members in the
+// current netting cycle.
+type SubmitObligationMsg struct {
+	Operator sdk.Address
+	Obligation
+}
+
+var _ sdk.Msg = SubmitObligationMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg SubmitObligationMsg) ValidateBasic() sdk.Error {
+	if msg.Amount.Amount <= 0 {
+		return ErrInvalidAmount("negative or 0 amount not allowed")
+	}
+	if err := ValidateCoin(msg.Amount); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Operator); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Debtor); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Creditor); err != nil {
+		return err
+	}
+	if bytes.Equal(msg.Debtor, msg.Creditor) {
+		return ErrInvalidAddress("debtor and creditor have the same address")
+	}
+	return nil
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg SubmitObligationMsg) Type() string { return SubmitObligationType }
+
+// Get some property of the Msg.
+func (msg SubmitObligationMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg SubmitObligationMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg SubmitObligationMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Operator} }
+
+// NetSettlementMsg defines the properties of a transaction that
+// closes the current netting cycle and settles each member's net
+// position against the clearing house asset account.
+type NetSettlementMsg struct {
+	Operator sdk.Address
+	Sender   sdk.Address
+}
+
+var _ sdk.Msg = NetSettlementMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg NetSettlementMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Operator); err != nil {
+		return err
+	}
+	return validateAddress(msg.Sender)
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg NetSettlementMsg) Type() string { return NetSettlementType }
+
+// Get some property of the Msg.
+func (msg NetSettlementMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg NetSettlementMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg NetSettlementMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Operator} }
+
+// CancelNettingCycleMsg defines the properties of a transaction that
+// discards the obligations of the current netting cycle, e.g. when its
+// net positions cannot be settled, and opens the next cycle.
+// The cycle must be the current one.
+type CancelNettingCycleMsg struct {
+	Operator sdk.Address
+	Cycle    int64
+}
+
+var _ sdk.Msg = CancelNettingCycleMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg CancelNettingCycleMsg) ValidateBasic() sdk.Error {
+	if msg.Cycle <= 0 {
+		return ErrInvalidCycle(fmt.Sprintf("%d", msg.Cycle))
+	}
+	return validateAddress(msg.Operator)
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg CancelNettingCycleMsg) Type() string { return CancelNettingCycleType }
+
+// Get some property of the Msg.
+func (msg CancelNettingCycleMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg CancelNettingCycleMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg CancelNettingCycleMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Operator} }
+
 // WithdrawMsg defines the properties of a withdraw transaction.
 type WithdrawMsg struct {
 	Operator  sdk.Address
 	Sender    sdk.Address
 	Recipient sdk.Address
 	Amount    sdk.Coin
+	Reference string
+	Memo      string
 }
 
 var _ sdk.Msg = WithdrawMsg{}
@@ -154,8 +391,8 @@ func (msg WithdrawMsg) ValidateBasic() sdk.Error {
 	if msg.Amount.Amount <= 0 {
 		return ErrInvalidAmount("negative or 0 amount not allowed")
 	}
-	if msg.Amount.Denom == "" {
-		return ErrInvalidAmount("empty denom")
+	if err := ValidateCoin(msg.Amount); err != nil {
+		return err
 	}
 	if err := validateAddress(msg.Operator); err != nil {
 		return err
@@ -169,7 +406,7 @@ func (msg WithdrawMsg) ValidateBasic() sdk.Error {
 	if bytes.Equal(msg.Sender, msg.Recipient) {
 		return ErrInvalidAddress("sender and recipient have the same address")
 	}
-	return nil
+	return validateReference(msg.Reference, msg.Memo)
 }
 
 // Type returns the message type.
@@ -293,11 +530,11 @@ func (msg CreateOperatorMsg) Type() string { return CreateOperatorType }
 
 // CreateAdminMsg defines the properties of a transaction
 // that triggers the cross-entity creation of privileged users
-// The message must carry the legal entity.
+// The message must carry the ID of a registered, active legal entity.
 // Only a clearing house can utilise this endpoint.
 type CreateAdminMsg struct {
 	BaseCreateUserMsg
-	BaseLegalEntity
+	EntityID string
 }
 
 var _ sdk.Msg = (*CreateAdminMsg)(nil)
@@ -307,7 +544,7 @@ func (msg CreateAdminMsg) ValidateBasic() sdk.Error {
 	if err := msg.BaseCreateUserMsg.ValidateBasic(); err != nil {
 		return err
 	}
-	if err := ValidateLegalEntity(msg.BaseLegalEntity); err != nil {
+	if err := ValidateEntityID(msg.EntityID); err != nil {
 		return ErrInvalidLegalEntity(err.Error())
 	}
 	return nil
@@ -344,9 +581,14 @@ func (msg BaseFreezeAccountMsg) ValidateBasic() sdk.Error {
 // Get returns some property of the Msg.
 func (msg BaseFreezeAccountMsg) Get(key interface{}) (value interface{}) { return nil }
 
-// GetSignBytes returns the canonical byte representation of the Msg.
-func (msg BaseFreezeAccountMsg) GetSignBytes() []byte {
-	bz, err := json.Marshal(msg)
+// freezeSignBytes returns the canonical byte representation of a freeze
+// or unfreeze Msg. Their fields are the same, so the type is included
+// to keep the signature of one from being valid for the other.
+func freezeSignBytes(typ string, msg BaseFreezeAccountMsg) []byte {
+	bz, err := json.Marshal(struct {
+		Type string
+		BaseFreezeAccountMsg
+	}{typ, msg})
 	if err != nil {
 		panic(err)
 	}
@@ -369,6 +611,11 @@ var _ sdk.Msg = (*FreezeOperatorMsg)(nil)
 // Must be alphanumeric or empty.
 func (msg FreezeOperatorMsg) Type() string { return FreezeOperatorType }
 
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg FreezeOperatorMsg) GetSignBytes() []byte {
+	return freezeSignBytes(FreezeOperatorType, msg.BaseFreezeAccountMsg)
+}
+
 // FreezeAdminMsg defines the properties of a transaction
 // that freezes an admin. Only clearing house Admin accounts
 // can freeze other Admin accounts, regardless of the entity
@@ -381,33 +628,776 @@ var _ sdk.Msg = (*FreezeAdminMsg)(nil)
 // Must be alphanumeric or empty.
 func (msg FreezeAdminMsg) Type() string { return FreezeAdminType }
 
-/* Constructors */
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg FreezeAdminMsg) GetSignBytes() []byte {
+	return freezeSignBytes(FreezeAdminType, msg.BaseFreezeAccountMsg)
+}
 
-// NewCreateAdminMsg creates a new CreateAdminMsg.
-func NewCreateAdminMsg(creator sdk.Address, pubkey crypto.PubKey,
-	entityName, entityType string) (msg CreateAdminMsg) {
-	msg.BaseCreateUserMsg.Creator = creator
-	msg.BaseCreateUserMsg.PubKey = pubkey
-	msg.BaseLegalEntity.EntityName = entityName
-	msg.BaseLegalEntity.EntityType = entityType
-	return
+// UnfreezeOperatorMsg defines the properties of a transaction
+// that reactivates a frozen operator. Admin accounts can unfreeze
+// their own legal entity's operators.
+type UnfreezeOperatorMsg struct{ BaseFreezeAccountMsg }
+
+var _ sdk.Msg = (*UnfreezeOperatorMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg UnfreezeOperatorMsg) Type() string { return UnfreezeOperatorType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg UnfreezeOperatorMsg) GetSignBytes() []byte {
+	return freezeSignBytes(UnfreezeOperatorType, msg.BaseFreezeAccountMsg)
 }
 
-// NewCreateOperatorMsg creates a new CreateOperatorMsg.
-func NewCreateOperatorMsg(creator sdk.Address, pubkey crypto.PubKey) (msg CreateOperatorMsg) {
-	msg.BaseCreateUserMsg.Creator = creator
-	msg.BaseCreateUserMsg.PubKey = pubkey
-	return
+// UnfreezeAdminMsg defines the properties of a transaction
+// that reactivates a frozen admin. Only clearing house Admin
+// accounts can unfreeze other Admin accounts, regardless of
+// the entity that own them.
+type UnfreezeAdminMsg struct{ BaseFreezeAccountMsg }
+
+var _ sdk.Msg = (*UnfreezeAdminMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg UnfreezeAdminMsg) Type() string { return UnfreezeAdminType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg UnfreezeAdminMsg) GetSignBytes() []byte {
+	return freezeSignBytes(UnfreezeAdminType, msg.BaseFreezeAccountMsg)
 }
 
-// NewCreateAssetAccountMsg creates a new CreateAssetAccountMsg.
-func NewCreateAssetAccountMsg(creator sdk.Address, pubkey crypto.PubKey) (msg CreateAssetAccountMsg) {
-	msg.Creator = creator
-	msg.PubKey = pubkey
-	return
+// FreezeAssetAccountMsg defines the properties of a transaction
+// that freezes an asset account. Admin accounts can freeze their
+// own legal entity's asset accounts, clearing house Admin accounts
+// can freeze any asset account.
+type FreezeAssetAccountMsg struct{ BaseFreezeAccountMsg }
+
+var _ sdk.Msg = (*FreezeAssetAccountMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg FreezeAssetAccountMsg) Type() string { return FreezeAssetAccountType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg FreezeAssetAccountMsg) GetSignBytes() []byte {
+	return freezeSignBytes(FreezeAssetAccountType, msg.BaseFreezeAccountMsg)
 }
 
-/* Auxiliary functions, could be undocumented */
+// UnfreezeAssetAccountMsg defines the properties of a transaction
+// that reactivates a frozen asset account. The same authority rules
+// of FreezeAssetAccountMsg apply.
+type UnfreezeAssetAccountMsg struct{ BaseFreezeAccountMsg }
+
+var _ sdk.Msg = (*UnfreezeAssetAccountMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg UnfreezeAssetAccountMsg) Type() string { return UnfreezeAssetAccountType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg UnfreezeAssetAccountMsg) GetSignBytes() []byte {
+	return freezeSignBytes(UnfreezeAssetAccountType, msg.BaseFreezeAccountMsg)
+}
+
+// SetCreditLimitMsg defines the properties of a transaction that
+// sets how far below zero a member asset account's balance may go
+// in the given currency. A zero amount removes the credit limit.
+// Only clearing house Admin accounts can set credit limits.
+type SetCreditLimitMsg struct {
+	Admin  sdk.Address
+	Target sdk.Address
+	Limit  sdk.Coin
+}
+
+var _ sdk.Msg = SetCreditLimitMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg SetCreditLimitMsg) ValidateBasic() sdk.Error {
+	if msg.Limit.Amount < 0 {
+		return ErrInvalidAmount("negative credit limit not allowed")
+	}
+	if err := ValidateCoin(msg.Limit); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	return validateAddress(msg.Target)
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg SetCreditLimitMsg) Type() string { return SetCreditLimitType }
+
+// Get some property of the Msg.
+func (msg SetCreditLimitMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg SetCreditLimitMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg SetCreditLimitMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+// ProposeTransferMsg defines the properties of a transaction that
+// submits a deposit, settlement or withdraw for approval. The transfer
+// is executed once another user of the operator's entity approves it.
+type ProposeTransferMsg struct {
+	Operator     sdk.Address
+	TransferType string
+	Sender       sdk.Address
+	Recipient    sdk.Address
+	Amount       sdk.Coin
+	Reference    string
+	Memo         string
+}
+
+var _ sdk.Msg = ProposeTransferMsg{}
+
+// TransferMsg returns the DepositMsg, SettleMsg or WithdrawMsg
+// that is executed once the proposal is approved.
+func (msg ProposeTransferMsg) TransferMsg() (sdk.Msg, sdk.Error) {
+	switch msg.TransferType {
+	case DepositType:
+		return DepositMsg{msg.Operator, msg.Sender, msg.Recipient, msg.Amount, msg.Reference, msg.Memo}, nil
+	case SettlementType:
+		return SettleMsg{msg.Operator, msg.Sender, msg.Recipient, msg.Amount, msg.Reference, msg.Memo}, nil
+	case WithdrawType:
+		return WithdrawMsg{msg.Operator, msg.Sender, msg.Recipient, msg.Amount, msg.Reference, msg.Memo}, nil
+	}
+	return nil, ErrWrongMsgFormat(fmt.Sprintf("%q transfers cannot be proposed", msg.TransferType))
+}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg ProposeTransferMsg) ValidateBasic() sdk.Error {
+	transfer, err := msg.TransferMsg()
+	if err != nil {
+		return err
+	}
+	return transfer.ValidateBasic()
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg ProposeTransferMsg) Type() string { return ProposeTransferType }
+
+// Get some property of the Msg.
+func (msg ProposeTransferMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg ProposeTransferMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg ProposeTransferMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Operator} }
+
+// BaseCheckTransferMsg defines the properties of a transaction
+// that approves or rejects a pending transfer.
+type BaseCheckTransferMsg struct {
+	Checker sdk.Address
+	ID      int64
+}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg BaseCheckTransferMsg) ValidateBasic() sdk.Error {
+	if msg.ID <= 0 {
+		return ErrInvalidTransfer(fmt.Sprintf("invalid id %d", msg.ID))
+	}
+	return validateAddress(msg.Checker)
+}
+
+// Get returns some property of the Msg.
+func (msg BaseCheckTransferMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// checkTransferSignBytes returns the canonical byte representation of an
+// approve or reject Msg. Their fields are the same, so the type is included
+// to keep a checker's rejection from being relayed as an approval.
+func checkTransferSignBytes(typ string, msg BaseCheckTransferMsg) []byte {
+	bz, err := json.Marshal(struct {
+		Type string
+		BaseCheckTransferMsg
+	}{typ, msg})
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg BaseCheckTransferMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Checker} }
+
+// ApproveTransferMsg defines the properties of a transaction that
+// approves and executes a pending transfer. The checker must be a user
+// of the proposer's entity other than the proposer, holding the permission
+// the transfer requires.
+type ApproveTransferMsg struct{ BaseCheckTransferMsg }
+
+var _ sdk.Msg = (*ApproveTransferMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg ApproveTransferMsg) Type() string { return ApproveTransferType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg ApproveTransferMsg) GetSignBytes() []byte {
+	return checkTransferSignBytes(ApproveTransferType, msg.BaseCheckTransferMsg)
+}
+
+// RejectTransferMsg defines the properties of a transaction that
+// rejects a pending transfer. Any user of the proposer's entity holding
+// the permission the transfer requires, the proposer included, can reject it.
+type RejectTransferMsg struct{ BaseCheckTransferMsg }
+
+var _ sdk.Msg = (*RejectTransferMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg RejectTransferMsg) Type() string { return RejectTransferType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg RejectTransferMsg) GetSignBytes() []byte {
+	return checkTransferSignBytes(RejectTransferType, msg.BaseCheckTransferMsg)
+}
+
+// SetOperatorLimitsMsg defines the properties of a transaction that
+// caps, in a currency, the amount of a single transfer an operator can
+// sign and the total it can move in a day. A zero amount removes the
+// corresponding limit. Admin accounts can set limits on their own legal
+// entity's operators.
+type SetOperatorLimitsMsg struct {
+	Admin      sdk.Address
+	Target     sdk.Address
+	MaxAmount  sdk.Coin
+	DailyLimit sdk.Coin
+}
+
+var _ sdk.Msg = SetOperatorLimitsMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg SetOperatorLimitsMsg) ValidateBasic() sdk.Error {
+	if msg.MaxAmount.Denom != msg.DailyLimit.Denom {
+		return ErrInvalidAmount("limits must have the same denom")
+	}
+	if msg.MaxAmount.Amount < 0 || msg.DailyLimit.Amount < 0 {
+		return ErrInvalidAmount("negative limit not allowed")
+	}
+	if err := ValidateCoin(msg.MaxAmount); err != nil {
+		return err
+	}
+	if err := ValidateCoin(msg.DailyLimit); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	return validateAddress(msg.Target)
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg SetOperatorLimitsMsg) Type() string { return SetOperatorLimitsType }
+
+// Get some property of the Msg.
+func (msg SetOperatorLimitsMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg SetOperatorLimitsMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg SetOperatorLimitsMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+// BaseRoleMsg defines the properties of a transaction
+// that grants or revokes a user's role.
+type BaseRoleMsg struct {
+	Admin  sdk.Address
+	Target sdk.Address
+	Role   string
+}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg BaseRoleMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Target); err != nil {
+		return err
+	}
+	if bytes.Equal(msg.Admin, msg.Target) {
+		return ErrInvalidRole("admins cannot change their own roles")
+	}
+	if err := ValidateRole(msg.Role); err != nil {
+		return ErrInvalidRole(err.Error())
+	}
+	return nil
+}
+
+// Get returns some property of the Msg.
+func (msg BaseRoleMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// roleSignBytes returns the canonical byte representation of a grant
+// or revoke Msg. Their fields are the same, so the type is included
+// to keep a signed revocation from being replayed as a grant.
+func roleSignBytes(typ string, msg BaseRoleMsg) []byte {
+	bz, err := json.Marshal(struct {
+		Type string
+		BaseRoleMsg
+	}{typ, msg})
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg BaseRoleMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+// GrantRoleMsg defines the properties of a transaction that
+// assigns a role to a user. Admin accounts can grant roles
+// to their own legal entity's users, except themselves.
+type GrantRoleMsg struct{ BaseRoleMsg }
+
+var _ sdk.Msg = (*GrantRoleMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg GrantRoleMsg) Type() string { return GrantRoleType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg GrantRoleMsg) GetSignBytes() []byte {
+	return roleSignBytes(GrantRoleType, msg.BaseRoleMsg)
+}
+
+// RevokeRoleMsg defines the properties of a transaction that
+// removes a role from a user. Admin accounts can revoke roles
+// from their own legal entity's users, except themselves.
+type RevokeRoleMsg struct{ BaseRoleMsg }
+
+var _ sdk.Msg = (*RevokeRoleMsg)(nil)
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg RevokeRoleMsg) Type() string { return RevokeRoleType }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg RevokeRoleMsg) GetSignBytes() []byte {
+	return roleSignBytes(RevokeRoleType, msg.BaseRoleMsg)
+}
+
+// RotateKeyMsg defines the properties of a transaction that binds
+// an existing user or asset account to a new public key. The account
+// keeps its address, balances, entity and creator, and only the new
+// key can sign for it afterwards. Admin accounts can rotate the keys
+// of their own legal entity's accounts, clearing house Admin accounts
+// can rotate any account's key.
+type RotateKeyMsg struct {
+	Admin  sdk.Address
+	Target sdk.Address
+	PubKey crypto.PubKey
+}
+
+var _ sdk.Msg = RotateKeyMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg RotateKeyMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Target); err != nil {
+		return err
+	}
+	if msg.PubKey.Empty() {
+		return ErrInvalidPubKey("pub key is nil")
+	}
+	if bytes.Equal(msg.Admin, msg.Target) {
+		return ErrInvalidPubKey("admins cannot rotate their own key")
+	}
+	return nil
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg RotateKeyMsg) Type() string { return RotateKeyType }
+
+// Get returns some property of the Msg.
+func (msg RotateKeyMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg RotateKeyMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg RotateKeyMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+// CloseAssetAccountMsg defines the properties of a transaction that
+// permanently closes an asset account. Remaining balances are swept to
+// SweepTo, an asset account of the same legal entity; accounts with
+// non-zero balances cannot be closed without one. Admin accounts can
+// close their own legal entity's asset accounts, clearing house Admin
+// accounts can close any asset account.
+type CloseAssetAccountMsg struct {
+	Admin   sdk.Address
+	Target  sdk.Address
+	SweepTo sdk.Address
+}
+
+var _ sdk.Msg = CloseAssetAccountMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg CloseAssetAccountMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	if err := validateAddress(msg.Target); err != nil {
+		return err
+	}
+	if len(msg.SweepTo) == 0 {
+		return nil
+	}
+	if err := validateAddress(msg.SweepTo); err != nil {
+		return err
+	}
+	if bytes.Equal(msg.Target, msg.SweepTo) {
+		return ErrInvalidAddress("cannot sweep balances to the closed account")
+	}
+	return nil
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg CloseAssetAccountMsg) Type() string { return CloseAssetAccountType }
+
+// Get returns some property of the Msg.
+func (msg CloseAssetAccountMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg CloseAssetAccountMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg CloseAssetAccountMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+// RegisterEntityMsg defines the properties of a transaction that
+// registers a new, active legal entity. IDs and names are unique,
+// the LEI and the country are optional.
+// Only clearing house Admin accounts can register legal entities.
+type RegisterEntityMsg struct {
+	Admin      sdk.Address
+	EntityID   string
+	EntityName string
+	EntityType string
+	LEI        string
+	Country    string
+}
+
+var _ sdk.Msg = RegisterEntityMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg RegisterEntityMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	if err := msg.Entity().Validate(); err != nil {
+		return ErrInvalidLegalEntity(err.Error())
+	}
+	return nil
+}
+
+// Entity returns the legal entity the message registers.
+func (msg RegisterEntityMsg) Entity() Entity {
+	return NewEntity(msg.EntityID, msg.EntityName, msg.EntityType, msg.LEI, msg.Country)
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg RegisterEntityMsg) Type() string { return RegisterEntityType }
+
+// Get returns some property of the Msg.
+func (msg RegisterEntityMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg RegisterEntityMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg RegisterEntityMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+// UpdateEntityStatusMsg defines the properties of a transaction
+// that changes a registered legal entity's status.
+// Only clearing house Admin accounts can update legal entities' status.
+type UpdateEntityStatusMsg struct {
+	Admin    sdk.Address
+	EntityID string
+	Status   string
+}
+
+var _ sdk.Msg = UpdateEntityStatusMsg{}
+
+// ValidateBasic is called by the SDK automatically.
+func (msg UpdateEntityStatusMsg) ValidateBasic() sdk.Error {
+	if err := validateAddress(msg.Admin); err != nil {
+		return err
+	}
+	if err := ValidateEntityID(msg.EntityID); err != nil {
+		return ErrInvalidLegalEntity(err.Error())
+	}
+	if err := ValidateEntityStatus(msg.Status); err != nil {
+		return ErrInvalidLegalEntity(err.Error())
+	}
+	return nil
+}
+
+// Type returns the message type.
+// Must be alphanumeric or empty.
+func (msg UpdateEntityStatusMsg) Type() string { return UpdateEntityStatusType }
+
+// Get returns some property of the Msg.
+func (msg UpdateEntityStatusMsg) Get(key interface{}) (value interface{}) { return nil }
+
+// GetSignBytes returns the canonical byte representation of the Msg.
+func (msg UpdateEntityStatusMsg) GetSignBytes() []byte {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// GetSigners returns the addrs of signers that must sign.
+// CONTRACT: All signatures must be present to be valid.
+// CONTRACT: Returns addrs in some deterministic order.
+func (msg UpdateEntityStatusMsg) GetSigners() []sdk.Address { return []sdk.Address{msg.Admin} }
+
+/* Constructors */
+
+// NewDepositMsg creates a new DepositMsg.
+func NewDepositMsg(operator, sender, recipient sdk.Address, amount sdk.Coin) DepositMsg {
+	return DepositMsg{Operator: operator, Sender: sender, Recipient: recipient, Amount: amount}
+}
+
+// NewSettleMsg creates a new SettleMsg.
+func NewSettleMsg(operator, sender, recipient sdk.Address, amount sdk.Coin) SettleMsg {
+	return SettleMsg{Operator: operator, Sender: sender, Recipient: recipient, Amount: amount}
+}
+
+// NewBatchSettleMsg creates a new BatchSettleMsg.
+func NewBatchSettleMsg(operator, sender sdk.Address, legs []SettleLeg) BatchSettleMsg {
+	return BatchSettleMsg{Operator: operator, Sender: sender, Legs: legs}
+}
+
+// NewSubmitObligationMsg creates a new SubmitObligationMsg.
+func NewSubmitObligationMsg(operator, debtor, creditor sdk.Address, amount sdk.Coin) SubmitObligationMsg {
+	return SubmitObligationMsg{Operator: operator, Obligation: Obligation{Debtor: debtor, Creditor: creditor, Amount: amount}}
+}
+
+// NewNetSettlementMsg creates a new NetSettlementMsg.
+func NewNetSettlementMsg(operator, sender sdk.Address) NetSettlementMsg {
+	return NetSettlementMsg{Operator: operator, Sender: sender}
+}
+
+// NewCancelNettingCycleMsg creates a new CancelNettingCycleMsg.
+func NewCancelNettingCycleMsg(operator sdk.Address, cycle int64) CancelNettingCycleMsg {
+	return CancelNettingCycleMsg{Operator: operator, Cycle: cycle}
+}
+
+// NewWithdrawMsg creates a new WithdrawMsg.
+func NewWithdrawMsg(operator, sender, recipient sdk.Address, amount sdk.Coin) WithdrawMsg {
+	return WithdrawMsg{Operator: operator, Sender: sender, Recipient: recipient, Amount: amount}
+}
+
+// NewCreateAdminMsg creates a new CreateAdminMsg.
+func NewCreateAdminMsg(creator sdk.Address, pubkey crypto.PubKey, entityID string) (msg CreateAdminMsg) {
+	msg.BaseCreateUserMsg.Creator = creator
+	msg.BaseCreateUserMsg.PubKey = pubkey
+	msg.EntityID = entityID
+	return
+}
+
+// NewCreateOperatorMsg creates a new CreateOperatorMsg.
+func NewCreateOperatorMsg(creator sdk.Address, pubkey crypto.PubKey) (msg CreateOperatorMsg) {
+	msg.BaseCreateUserMsg.Creator = creator
+	msg.BaseCreateUserMsg.PubKey = pubkey
+	return
+}
+
+// NewCreateAssetAccountMsg creates a new CreateAssetAccountMsg.
+func NewCreateAssetAccountMsg(creator sdk.Address, pubkey crypto.PubKey) (msg CreateAssetAccountMsg) {
+	msg.Creator = creator
+	msg.PubKey = pubkey
+	return
+}
+
+// NewFreezeOperatorMsg creates a new FreezeOperatorMsg.
+func NewFreezeOperatorMsg(admin, target sdk.Address) (msg FreezeOperatorMsg) {
+	msg.Admin = admin
+	msg.Target = target
+	return
+}
+
+// NewFreezeAdminMsg creates a new FreezeAdminMsg.
+func NewFreezeAdminMsg(admin, target sdk.Address) (msg FreezeAdminMsg) {
+	msg.Admin = admin
+	msg.Target = target
+	return
+}
+
+// NewUnfreezeOperatorMsg creates a new UnfreezeOperatorMsg.
+func NewUnfreezeOperatorMsg(admin, target sdk.Address) (msg UnfreezeOperatorMsg) {
+	msg.Admin = admin
+	msg.Target = target
+	return
+}
+
+// NewUnfreezeAdminMsg creates a new UnfreezeAdminMsg.
+func NewUnfreezeAdminMsg(admin, target sdk.Address) (msg UnfreezeAdminMsg) {
+	msg.Admin = admin
+	msg.Target = target
+	return
+}
+
+// NewFreezeAssetAccountMsg creates a new FreezeAssetAccountMsg.
+func NewFreezeAssetAccountMsg(admin, target sdk.Address) (msg FreezeAssetAccountMsg) {
+	msg.Admin = admin
+	msg.Target = target
+	return
+}
+
+// NewUnfreezeAssetAccountMsg creates a new UnfreezeAssetAccountMsg.
+func NewUnfreezeAssetAccountMsg(admin, target sdk.Address) (msg UnfreezeAssetAccountMsg) {
+	msg.Admin = admin
+	msg.Target = target
+	return
+}
+
+// NewSetCreditLimitMsg creates a new SetCreditLimitMsg.
+func NewSetCreditLimitMsg(admin, target sdk.Address, limit sdk.Coin) SetCreditLimitMsg {
+	return SetCreditLimitMsg{Admin: admin, Target: target, Limit: limit}
+}
+
+// NewProposeTransferMsg creates a new ProposeTransferMsg.
+func NewProposeTransferMsg(operator sdk.Address, transferType string,
+	sender, recipient sdk.Address, amount sdk.Coin) ProposeTransferMsg {
+	return ProposeTransferMsg{Operator: operator, TransferType: transferType,
+		Sender: sender, Recipient: recipient, Amount: amount}
+}
+
+// NewApproveTransferMsg creates a new ApproveTransferMsg.
+func NewApproveTransferMsg(checker sdk.Address, id int64) (msg ApproveTransferMsg) {
+	msg.Checker = checker
+	msg.ID = id
+	return
+}
+
+// NewRejectTransferMsg creates a new RejectTransferMsg.
+func NewRejectTransferMsg(checker sdk.Address, id int64) (msg RejectTransferMsg) {
+	msg.Checker = checker
+	msg.ID = id
+	return
+}
+
+// NewSetOperatorLimitsMsg creates a new SetOperatorLimitsMsg.
+func NewSetOperatorLimitsMsg(admin, target sdk.Address, maxAmount, dailyLimit sdk.Coin) SetOperatorLimitsMsg {
+	return SetOperatorLimitsMsg{Admin: admin, Target: target, MaxAmount: maxAmount, DailyLimit: dailyLimit}
+}
+
+// NewGrantRoleMsg creates a new GrantRoleMsg.
+func NewGrantRoleMsg(admin, target sdk.Address, role string) GrantRoleMsg {
+	return GrantRoleMsg{BaseRoleMsg{Admin: admin, Target: target, Role: role}}
+}
+
+// NewRotateKeyMsg creates a new RotateKeyMsg.
+func NewRotateKeyMsg(admin, target sdk.Address, pubkey crypto.PubKey) RotateKeyMsg {
+	return RotateKeyMsg{Admin: admin, Target: target, PubKey: pubkey}
+}
+
+// NewCloseAssetAccountMsg creates a new CloseAssetAccountMsg,
+// sweepTo may be nil if the account has no balance.
+func NewCloseAssetAccountMsg(admin, target, sweepTo sdk.Address) CloseAssetAccountMsg {
+	return CloseAssetAccountMsg{Admin: admin, Target: target, SweepTo: sweepTo}
+}
+
+// NewRevokeRoleMsg creates a new RevokeRoleMsg.
+func NewRevokeRoleMsg(admin, target sdk.Address, role string) RevokeRoleMsg {
+	return RevokeRoleMsg{BaseRoleMsg{Admin: admin, Target: target, Role: role}}
+}
+
+// NewRegisterEntityMsg creates a new RegisterEntityMsg.
+func NewRegisterEntityMsg(admin sdk.Address, id, name, typ, lei, country string) RegisterEntityMsg {
+	return RegisterEntityMsg{Admin: admin, EntityID: id, EntityName: name, EntityType: typ, LEI: lei, Country: country}
+}
+
+// NewUpdateEntityStatusMsg creates a new UpdateEntityStatusMsg.
+func NewUpdateEntityStatusMsg(admin sdk.Address, id, status string) UpdateEntityStatusMsg {
+	return UpdateEntityStatusMsg{Admin: admin, EntityID: id, Status: status}
+}
+
+/* Auxiliary functions, could be undocumented */
+
+// validateReference ensures that references are short and made of letters,
+// digits and "-_.:" only, so that they can be used in query paths.
+func validateReference(reference, memo string) sdk.Error {
+	if len(reference) > MaxReferenceLength {
+		return ErrInvalidReference(fmt.Sprintf("reference is longer than %d characters", MaxReferenceLength))
+	}
+	for _, r := range reference {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r)) {
+			return ErrInvalidReference(fmt.Sprintf("invalid character %q in reference", r))
+		}
+	}
+	if len(memo) > MaxMemoLength {
+		return ErrInvalidReference(fmt.Sprintf("memo is longer than %d bytes", MaxMemoLength))
+	}
+	if !utf8.ValidString(memo) {
+		return ErrInvalidReference("memo is not valid UTF-8")
+	}
+	return nil
+}
 
 func validateAddress(addr sdk.Address) sdk.Error {
 	if addr == nil {
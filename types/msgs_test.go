@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -10,8 +11,8 @@ import (
 )
 
 func TestDepositMsg_ValidateBasic(t *testing.T) {
-	coin := sdk.Coin{Amount: 100, Denom: "ATM"}
-	coinNegative := sdk.Coin{Amount: -100, Denom: "ATM"}
+	coin := sdk.Coin{Amount: 100, Denom: "USD"}
+	coinNegative := sdk.Coin{Amount: -100, Denom: "USD"}
 	short := crypto.Address("foo")
 	long := crypto.Address("hefkuhwqekufghwqekufgwqekufgkwuqgfkugfkuwgek")
 	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
@@ -23,6 +24,8 @@ func TestDepositMsg_ValidateBasic(t *testing.T) {
 		Sender    crypto.Address
 		Recipient crypto.Address
 		Amount    sdk.Coin
+		Reference string
+		Memo      string
 	}
 	tests := []struct {
 		name      string
@@ -37,7 +40,7 @@ func TestDepositMsg_ValidateBasic(t *testing.T) {
 		{
 			"no denom",
 			fields{Amount: sdk.Coin{Amount: 100}},
-			CodeInvalidAmount,
+			CodeInvalidCurrency,
 		},
 		{
 			"no amount",
@@ -84,6 +87,41 @@ func TestDepositMsg_ValidateBasic(t *testing.T) {
 			fields{Amount: coinNegative, Operator: addr, Sender: addr2, Recipient: addr3},
 			CodeInvalidAmount,
 		},
+		{
+			"unknown denom",
+			fields{Amount: sdk.Coin{Amount: 100, Denom: "USDD"}, Operator: addr, Sender: addr2, Recipient: addr3},
+			CodeInvalidCurrency,
+		},
+		{
+			"reference and memo",
+			fields{Amount: coin, Operator: addr, Sender: addr2, Recipient: addr3, Reference: "INV-2018.04:17_a", Memo: "April's margin call ✓"},
+			sdk.CodeOK,
+		},
+		{
+			"reference with a slash",
+			fields{Amount: coin, Operator: addr, Sender: addr2, Recipient: addr3, Reference: "INV/17"},
+			CodeInvalidReference,
+		},
+		{
+			"reference with a space",
+			fields{Amount: coin, Operator: addr, Sender: addr2, Recipient: addr3, Reference: "INV 17"},
+			CodeInvalidReference,
+		},
+		{
+			"reference too long",
+			fields{Amount: coin, Operator: addr, Sender: addr2, Recipient: addr3, Reference: strings.Repeat("a", MaxReferenceLength+1)},
+			CodeInvalidReference,
+		},
+		{
+			"memo too long",
+			fields{Amount: coin, Operator: addr, Sender: addr2, Recipient: addr3, Memo: strings.Repeat("a", MaxMemoLength+1)},
+			CodeInvalidReference,
+		},
+		{
+			"memo not UTF-8",
+			fields{Amount: coin, Operator: addr, Sender: addr2, Recipient: addr3, Memo: "\xff"},
+			CodeInvalidReference,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -92,6 +130,8 @@ func TestDepositMsg_ValidateBasic(t *testing.T) {
 				Sender:    tt.fields.Sender,
 				Recipient: tt.fields.Recipient,
 				Amount:    tt.fields.Amount,
+				Reference: tt.fields.Reference,
+				Memo:      tt.fields.Memo,
 			}
 			got := d.ValidateBasic()
 			if got == nil {
@@ -103,8 +143,8 @@ func TestDepositMsg_ValidateBasic(t *testing.T) {
 	}
 }
 func TestSettleMsg_ValidateBasic(t *testing.T) {
-	coin := sdk.Coin{Amount: 100, Denom: "ATM"}
-	coinNegative := sdk.Coin{Amount: -100, Denom: "ATM"}
+	coin := sdk.Coin{Amount: 100, Denom: "USD"}
+	coinNegative := sdk.Coin{Amount: -100, Denom: "USD"}
 	short := crypto.Address("foo")
 	long := crypto.Address("hefkuhwqekufghwqekufgwqekufgkwuqgfkugfkuwgek")
 	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
@@ -130,7 +170,7 @@ func TestSettleMsg_ValidateBasic(t *testing.T) {
 		{
 			"no denom",
 			fields{Amount: sdk.Coin{Amount: 100}},
-			CodeInvalidAmount,
+			CodeInvalidCurrency,
 		},
 		{
 			"no amount",
@@ -172,6 +212,11 @@ func TestSettleMsg_ValidateBasic(t *testing.T) {
 			fields{Amount: coinNegative, Operator: addr3, Sender: addr, Recipient: addr2},
 			sdk.CodeOK,
 		},
+		{
+			"unknown denom",
+			fields{Amount: sdk.Coin{Amount: -100, Denom: "usd"}, Operator: addr3, Sender: addr, Recipient: addr2},
+			CodeInvalidCurrency,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -191,8 +236,8 @@ func TestSettleMsg_ValidateBasic(t *testing.T) {
 	}
 }
 func TestWithdrawMsg_ValidateBasic(t *testing.T) {
-	coin := sdk.Coin{Amount: 100, Denom: "ATM"}
-	coinNegative := sdk.Coin{Amount: -100, Denom: "ATM"}
+	coin := sdk.Coin{Amount: 100, Denom: "USD"}
+	coinNegative := sdk.Coin{Amount: -100, Denom: "USD"}
 	short := crypto.Address("foo")
 	long := crypto.Address("hefkuhwqekufghwqekufgwqekufgkwuqgfkugfkuwgek")
 	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
@@ -211,7 +256,7 @@ func TestWithdrawMsg_ValidateBasic(t *testing.T) {
 		errorCode sdk.CodeType
 	}{
 		{"empty msg", fields{}, CodeInvalidAmount},
-		{"no denom", fields{Amount: sdk.Coin{Amount: 100}}, CodeInvalidAmount},
+		{"no denom", fields{Amount: sdk.Coin{Amount: 100}}, CodeInvalidCurrency},
 		{"no amount", fields{Amount: sdk.Coin{Denom: "Foo"}}, CodeInvalidAmount},
 		{"missing address", fields{Amount: coin}, CodeInvalidAddress},
 		{"short address", fields{Amount: coin, Sender: short, Recipient: short}, CodeInvalidAddress},
@@ -221,6 +266,7 @@ func TestWithdrawMsg_ValidateBasic(t *testing.T) {
 		{"missing proper address", fields{Amount: coin, Sender: addr, Recipient: addr2}, CodeInvalidAddress},
 		{"negative amount", fields{Amount: coinNegative, Sender: addr, Recipient: addr2}, CodeInvalidAmount},
 		{"proper address", fields{Amount: coin, Sender: addr, Recipient: addr2, Operator: addr3}, sdk.CodeOK},
+		{"unknown denom", fields{Amount: sdk.Coin{Amount: 100, Denom: "ATM"}, Sender: addr, Recipient: addr2, Operator: addr3}, CodeInvalidCurrency},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -240,6 +286,79 @@ func TestWithdrawMsg_ValidateBasic(t *testing.T) {
 	}
 }
 
+func TestBatchSettleMsg_ValidateBasic(t *testing.T) {
+	coin := sdk.Coin{Amount: 100, Denom: "USD"}
+	coinNegative := sdk.Coin{Amount: -100, Denom: "EUR"}
+	long := crypto.Address("hefkuhwqekufghwqekufgwqekufgkwuqgfkugfkuwgek")
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr4 := crypto.GenPrivKeyEd25519().PubKey().Address()
+
+	type fields struct {
+		Operator crypto.Address
+		Sender   crypto.Address
+		Legs     []SettleLeg
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		errorCode sdk.CodeType
+	}{
+		{"empty msg", fields{}, CodeInvalidAddress},
+		{"no legs", fields{Operator: addr, Sender: addr2}, CodeInvalidAmount},
+		{"zero amount", fields{Operator: addr, Sender: addr2, Legs: []SettleLeg{{addr3, sdk.Coin{Denom: "USD"}}}}, CodeInvalidAmount},
+		{"no denom", fields{Operator: addr, Sender: addr2, Legs: []SettleLeg{{addr3, sdk.Coin{Amount: 100}}}}, CodeInvalidCurrency},
+		{"unknown denom", fields{Operator: addr, Sender: addr2, Legs: []SettleLeg{
+			{addr3, coin}, {addr4, sdk.Coin{Amount: 100, Denom: "USDD"}}}}, CodeInvalidCurrency},
+		{"long recipient", fields{Operator: addr, Sender: addr2, Legs: []SettleLeg{{long, coin}}}, CodeInvalidAddress},
+		{"sender and recipient got same address", fields{Operator: addr, Sender: addr2, Legs: []SettleLeg{
+			{addr3, coin}, {addr2, coin}}}, CodeInvalidAddress},
+		{"proper legs", fields{Operator: addr, Sender: addr2, Legs: []SettleLeg{
+			{addr3, coin}, {addr4, coinNegative}, {addr3, coinNegative}}}, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewBatchSettleMsg(tt.fields.Operator, tt.fields.Sender, tt.fields.Legs)
+			got := msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.errorCode.IsOK())
+			} else {
+				assert.Equal(t, tt.errorCode, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestSubmitObligationMsg_ValidateBasic(t *testing.T) {
+	coin := sdk.Coin{Amount: 100, Denom: "USD"}
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  SubmitObligationMsg
+		want sdk.CodeType
+	}{
+		{"empty msg", SubmitObligationMsg{}, CodeInvalidAmount},
+		{"negative amount", NewSubmitObligationMsg(addr, addr2, addr3, sdk.Coin{Amount: -100, Denom: "USD"}), CodeInvalidAmount},
+		{"unknown denom", NewSubmitObligationMsg(addr, addr2, addr3, sdk.Coin{Amount: 100, Denom: "ATM"}), CodeInvalidCurrency},
+		{"missing operator", NewSubmitObligationMsg(nil, addr2, addr3, coin), CodeInvalidAddress},
+		{"same address", NewSubmitObligationMsg(addr, addr2, addr2, coin), CodeInvalidAddress},
+		{"ok", NewSubmitObligationMsg(addr, addr2, addr3, coin), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
 func TestCreateAssetAccountMsg_ValidateBasic(t *testing.T) {
 	creatorAddress := crypto.GenPrivKeyEd25519().PubKey().Address()
 	newPubKey := crypto.GenPrivKeyEd25519().PubKey()
@@ -317,34 +436,31 @@ func TestBaseCreateUserMsg_ValidateBasic(t *testing.T) {
 func TestCreateAdminMsg_ValidateBasic(t *testing.T) {
 	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
 	pub := crypto.GenPrivKeyEd25519().PubKey()
-	validEntity := BaseLegalEntity{
-		EntityName: "CH",
-		EntityType: EntityClearingHouse,
-	}
+	validEntityID := "icm-1"
 	validCreateUser := BaseCreateUserMsg{
 		Creator: addr,
 		PubKey:  pub,
 	}
 	type fields struct {
-		cm BaseCreateUserMsg
-		le BaseLegalEntity
+		cm       BaseCreateUserMsg
+		entityID string
 	}
 	tests := []struct {
 		name   string
 		fields fields
 		want   sdk.CodeType
 	}{
-		{"nil pubkey", fields{cm: BaseCreateUserMsg{nil, crypto.PubKey{}}, le: validEntity}, CodeInvalidPubKey},
-		{"invalid entity type", fields{cm: validCreateUser, le: BaseLegalEntity{EntityName: "CH", EntityType: "invalid"}}, CodeInvalidEntity},
-		{"empty entity name", fields{cm: validCreateUser, le: BaseLegalEntity{EntityName: "    ", EntityType: EntityClearingHouse}}, CodeInvalidEntity},
-		{"self create", fields{cm: BaseCreateUserMsg{Creator: pub.Address(), PubKey: pub}, le: validEntity}, CodeSelfCreate},
-		{"ok", fields{cm: validCreateUser, le: validEntity}, sdk.CodeOK},
+		{"nil pubkey", fields{cm: BaseCreateUserMsg{nil, crypto.PubKey{}}, entityID: validEntityID}, CodeInvalidPubKey},
+		{"empty entity ID", fields{cm: validCreateUser, entityID: ""}, CodeInvalidEntity},
+		{"invalid entity ID", fields{cm: validCreateUser, entityID: "icm/1"}, CodeInvalidEntity},
+		{"self create", fields{cm: BaseCreateUserMsg{Creator: pub.Address(), PubKey: pub}, entityID: validEntityID}, CodeSelfCreate},
+		{"ok", fields{cm: validCreateUser, entityID: validEntityID}, sdk.CodeOK},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			msg := CreateAdminMsg{
 				BaseCreateUserMsg: tt.fields.cm,
-				BaseLegalEntity:   tt.fields.le,
+				EntityID:          tt.fields.entityID,
 			}
 			got := msg.ValidateBasic()
 			if got != nil {
@@ -451,6 +567,25 @@ func TestMessageTypes(t *testing.T) {
 	createAsset := CreateAssetAccountMsg{}
 	freezeOp := FreezeOperatorMsg{}
 	freezeAd := FreezeAdminMsg{}
+	unfreezeOp := UnfreezeOperatorMsg{}
+	unfreezeAd := UnfreezeAdminMsg{}
+	freezeAsset := FreezeAssetAccountMsg{}
+	batchSettle := BatchSettleMsg{}
+	submitObligation := SubmitObligationMsg{}
+	netSettlement := NetSettlementMsg{}
+	unfreezeAsset := UnfreezeAssetAccountMsg{}
+	setCreditLimit := SetCreditLimitMsg{}
+	proposeTransfer := ProposeTransferMsg{}
+	approveTransfer := ApproveTransferMsg{}
+	rejectTransfer := RejectTransferMsg{}
+	setOperatorLimits := SetOperatorLimitsMsg{}
+	grantRole := GrantRoleMsg{}
+	revokeRole := RevokeRoleMsg{}
+	rotateKey := RotateKeyMsg{}
+	closeAsset := CloseAssetAccountMsg{}
+	registerEntity := RegisterEntityMsg{}
+	cancelNettingCycle := CancelNettingCycleMsg{}
+	updateEntityStatus := UpdateEntityStatusMsg{}
 	assert.Equal(t, deposit.Type(), DepositType)
 	assert.Equal(t, settle.Type(), SettlementType)
 	assert.Equal(t, withdraw.Type(), WithdrawType)
@@ -459,30 +594,351 @@ func TestMessageTypes(t *testing.T) {
 	assert.Equal(t, createAsset.Type(), CreateAssetAccountType)
 	assert.Equal(t, freezeOp.Type(), FreezeOperatorType)
 	assert.Equal(t, freezeAd.Type(), FreezeAdminType)
+	assert.Equal(t, unfreezeOp.Type(), UnfreezeOperatorType)
+	assert.Equal(t, unfreezeAd.Type(), UnfreezeAdminType)
+	assert.Equal(t, freezeAsset.Type(), FreezeAssetAccountType)
+	assert.Equal(t, batchSettle.Type(), BatchSettlementType)
+	assert.Equal(t, submitObligation.Type(), SubmitObligationType)
+	assert.Equal(t, netSettlement.Type(), NetSettlementType)
+	assert.Equal(t, unfreezeAsset.Type(), UnfreezeAssetAccountType)
+	assert.Equal(t, setCreditLimit.Type(), SetCreditLimitType)
+	assert.Equal(t, proposeTransfer.Type(), ProposeTransferType)
+	assert.Equal(t, approveTransfer.Type(), ApproveTransferType)
+	assert.Equal(t, rejectTransfer.Type(), RejectTransferType)
+	assert.Equal(t, setOperatorLimits.Type(), SetOperatorLimitsType)
+	assert.Equal(t, grantRole.Type(), GrantRoleType)
+	assert.Equal(t, revokeRole.Type(), RevokeRoleType)
+	assert.Equal(t, rotateKey.Type(), RotateKeyType)
+	assert.Equal(t, closeAsset.Type(), CloseAssetAccountType)
+	assert.Equal(t, registerEntity.Type(), RegisterEntityType)
+	assert.Equal(t, cancelNettingCycle.Type(), CancelNettingCycleType)
+	assert.Equal(t, updateEntityStatus.Type(), UpdateEntityStatusType)
+}
+
+func TestSetCreditLimitMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  SetCreditLimitMsg
+		want sdk.CodeType
+	}{
+		{"negative limit", NewSetCreditLimitMsg(addr, addr2, sdk.Coin{"USD", -100}), CodeInvalidAmount},
+		{"unknown denom", NewSetCreditLimitMsg(addr, addr2, sdk.Coin{"ATM", 100}), CodeInvalidCurrency},
+		{"missing target", NewSetCreditLimitMsg(addr, nil, sdk.Coin{"USD", 100}), CodeInvalidAddress},
+		{"remove limit", NewSetCreditLimitMsg(addr, addr2, sdk.Coin{"USD", 0}), sdk.CodeOK},
+		{"ok", NewSetCreditLimitMsg(addr, addr2, sdk.Coin{"USD", 100}), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestSetOperatorLimitsMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  SetOperatorLimitsMsg
+		want sdk.CodeType
+	}{
+		{"negative limit", NewSetOperatorLimitsMsg(addr, addr2, sdk.Coin{"USD", 100}, sdk.Coin{"USD", -100}), CodeInvalidAmount},
+		{"different denoms", NewSetOperatorLimitsMsg(addr, addr2, sdk.Coin{"USD", 100}, sdk.Coin{"EUR", 100}), CodeInvalidAmount},
+		{"unknown denom", NewSetOperatorLimitsMsg(addr, addr2, sdk.Coin{"ATM", 100}, sdk.Coin{"ATM", 100}), CodeInvalidCurrency},
+		{"missing target", NewSetOperatorLimitsMsg(addr, nil, sdk.Coin{"USD", 100}, sdk.Coin{"USD", 1000}), CodeInvalidAddress},
+		{"remove limits", NewSetOperatorLimitsMsg(addr, addr2, sdk.Coin{"USD", 0}, sdk.Coin{"USD", 0}), sdk.CodeOK},
+		{"ok", NewSetOperatorLimitsMsg(addr, addr2, sdk.Coin{"USD", 100}, sdk.Coin{"USD", 1000}), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestBaseRoleMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  BaseRoleMsg
+		want sdk.CodeType
+	}{
+		{"unknown role", NewGrantRoleMsg(addr, addr2, "superuser").BaseRoleMsg, CodeInvalidRole},
+		{"own roles", NewRevokeRoleMsg(addr, addr, RoleUserAdmin).BaseRoleMsg, CodeInvalidRole},
+		{"missing target", NewGrantRoleMsg(addr, nil, RoleAuditor).BaseRoleMsg, CodeInvalidAddress},
+		{"ok", NewGrantRoleMsg(addr, addr2, RoleAuditor).BaseRoleMsg, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestRotateKeyMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	pub := crypto.GenPrivKeyEd25519().PubKey()
+	tests := []struct {
+		name string
+		msg  RotateKeyMsg
+		want sdk.CodeType
+	}{
+		{"missing key", NewRotateKeyMsg(addr, addr2, crypto.PubKey{}), CodeInvalidPubKey},
+		{"own key", NewRotateKeyMsg(addr, addr, pub), CodeInvalidPubKey},
+		{"missing target", NewRotateKeyMsg(addr, nil, pub), CodeInvalidAddress},
+		{"ok", NewRotateKeyMsg(addr, addr2, pub), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestCloseAssetAccountMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  CloseAssetAccountMsg
+		want sdk.CodeType
+	}{
+		{"missing target", NewCloseAssetAccountMsg(addr, nil, nil), CodeInvalidAddress},
+		{"sweep to itself", NewCloseAssetAccountMsg(addr, addr2, addr2), CodeInvalidAddress},
+		{"invalid sweep account", NewCloseAssetAccountMsg(addr, addr2, []byte("short")), CodeInvalidAddress},
+		{"no sweep account", NewCloseAssetAccountMsg(addr, addr2, nil), sdk.CodeOK},
+		{"ok", NewCloseAssetAccountMsg(addr, addr2, addr3), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestRegisterEntityMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	lei := "5493001KJTIIGC8Y1R12"
+	tests := []struct {
+		name string
+		msg  RegisterEntityMsg
+		want sdk.CodeType
+	}{
+		{"missing admin", NewRegisterEntityMsg(nil, "icm-1", "ICM", EntityIndividualClearingMember, "", ""), CodeInvalidAddress},
+		{"missing ID", NewRegisterEntityMsg(addr, "", "ICM", EntityIndividualClearingMember, "", ""), CodeInvalidEntity},
+		{"ID too long", NewRegisterEntityMsg(addr, strings.Repeat("a", MaxEntityIDLength+1), "ICM", EntityIndividualClearingMember, "", ""), CodeInvalidEntity},
+		{"invalid ID", NewRegisterEntityMsg(addr, "icm 1", "ICM", EntityIndividualClearingMember, "", ""), CodeInvalidEntity},
+		{"empty name", NewRegisterEntityMsg(addr, "icm-1", "  ", EntityIndividualClearingMember, "", ""), CodeInvalidEntity},
+		{"invalid type", NewRegisterEntityMsg(addr, "icm-1", "ICM", "bank", "", ""), CodeInvalidEntity},
+		{"LEI too short", NewRegisterEntityMsg(addr, "icm-1", "ICM", EntityIndividualClearingMember, lei[1:], ""), CodeInvalidEntity},
+		{"lower case LEI", NewRegisterEntityMsg(addr, "icm-1", "ICM", EntityIndividualClearingMember, strings.ToLower(lei), ""), CodeInvalidEntity},
+		{"wrong LEI check digits", NewRegisterEntityMsg(addr, "icm-1", "ICM", EntityIndividualClearingMember, lei[:18]+"13", ""), CodeInvalidEntity},
+		{"invalid country", NewRegisterEntityMsg(addr, "icm-1", "ICM", EntityIndividualClearingMember, "", "GBR"), CodeInvalidEntity},
+		{"lower case country", NewRegisterEntityMsg(addr, "icm-1", "ICM", EntityIndividualClearingMember, "", "gb"), CodeInvalidEntity},
+		{"no metadata", NewRegisterEntityMsg(addr, "icm-1", "ICM", EntityIndividualClearingMember, "", ""), sdk.CodeOK},
+		{"ok", NewRegisterEntityMsg(addr, "ICM_1.a", "ICM", EntityIndividualClearingMember, lei, "GB"), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestUpdateEntityStatusMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  UpdateEntityStatusMsg
+		want sdk.CodeType
+	}{
+		{"missing admin", NewUpdateEntityStatusMsg(nil, "icm-1", EntityStatusSuspended), CodeInvalidAddress},
+		{"missing ID", NewUpdateEntityStatusMsg(addr, "", EntityStatusSuspended), CodeInvalidEntity},
+		{"invalid status", NewUpdateEntityStatusMsg(addr, "icm-1", "deleted"), CodeInvalidEntity},
+		{"ok", NewUpdateEntityStatusMsg(addr, "icm-1", EntityStatusClosed), sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestProposeTransferMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  ProposeTransferMsg
+		want sdk.CodeType
+	}{
+		{"unknown transfer type", NewProposeTransferMsg(addr, BatchSettlementType, addr2, addr3, sdk.Coin{"USD", 100}), CodeWrongMessageFormat},
+		{"negative deposit", NewProposeTransferMsg(addr, DepositType, addr2, addr3, sdk.Coin{"USD", -100}), CodeInvalidAmount},
+		{"same sender and recipient", NewProposeTransferMsg(addr, WithdrawType, addr2, addr2, sdk.Coin{"USD", 100}), CodeInvalidAddress},
+		{"negative settlement", NewProposeTransferMsg(addr, SettlementType, addr2, addr3, sdk.Coin{"USD", -100}), sdk.CodeOK},
+		{"ok", NewProposeTransferMsg(addr, DepositType, addr2, addr3, sdk.Coin{"USD", 100}), sdk.CodeOK},
+		{"invalid reference", ProposeTransferMsg{addr, SettlementType, addr2, addr3, sdk.Coin{"USD", 100}, "a b", ""}, CodeInvalidReference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
+}
+
+func TestProposeTransferMsg_TransferMsg(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	pm := ProposeTransferMsg{addr, WithdrawType, addr2, addr3, sdk.Coin{"USD", 100}, "ref-1", "memo"}
+	got, err := pm.TransferMsg()
+	assert.Nil(t, err)
+	assert.Equal(t, WithdrawMsg{addr, addr2, addr3, sdk.Coin{"USD", 100}, "ref-1", "memo"}, got)
+}
+
+func TestDepositMsg_GetSignBytes(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	addr3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	msg := NewDepositMsg(addr, addr2, addr3, sdk.Coin{"USD", 100})
+	withRef := msg
+	withRef.Reference = "ref-1"
+	withMemo := msg
+	withMemo.Memo = "memo"
+	assert.NotEqual(t, msg.GetSignBytes(), withRef.GetSignBytes())
+	assert.NotEqual(t, msg.GetSignBytes(), withMemo.GetSignBytes())
+}
+
+func TestFreezeAccountMsgs_GetSignBytes(t *testing.T) {
+	admin := crypto.GenPrivKeyEd25519().PubKey().Address()
+	target := crypto.GenPrivKeyEd25519().PubKey().Address()
+	signBytes := [][]byte{
+		NewFreezeOperatorMsg(admin, target).GetSignBytes(),
+		NewFreezeAdminMsg(admin, target).GetSignBytes(),
+		NewUnfreezeOperatorMsg(admin, target).GetSignBytes(),
+		NewUnfreezeAdminMsg(admin, target).GetSignBytes(),
+		NewFreezeAssetAccountMsg(admin, target).GetSignBytes(),
+		NewUnfreezeAssetAccountMsg(admin, target).GetSignBytes(),
+	}
+	for i := range signBytes {
+		for j := i + 1; j < len(signBytes); j++ {
+			assert.NotEqual(t, signBytes[i], signBytes[j], "%d and %d", i, j)
+		}
+	}
+}
+
+func TestRoleMsgs_GetSignBytes(t *testing.T) {
+	priv := crypto.GenPrivKeyEd25519()
+	target := crypto.GenPrivKeyEd25519().PubKey().Address()
+	grant := NewGrantRoleMsg(priv.PubKey().Address(), target, RoleUserAdmin).GetSignBytes()
+	revoke := NewRevokeRoleMsg(priv.PubKey().Address(), target, RoleUserAdmin).GetSignBytes()
+	assert.NotEqual(t, grant, revoke)
+	// a revocation's signature cannot be replayed as a grant
+	sig := priv.Sign(revoke)
+	assert.True(t, priv.PubKey().VerifyBytes(revoke, sig))
+	assert.False(t, priv.PubKey().VerifyBytes(grant, sig))
+}
+
+func TestCheckTransferMsgs_GetSignBytes(t *testing.T) {
+	priv := crypto.GenPrivKeyEd25519()
+	approve := NewApproveTransferMsg(priv.PubKey().Address(), 1).GetSignBytes()
+	reject := NewRejectTransferMsg(priv.PubKey().Address(), 1).GetSignBytes()
+	assert.NotEqual(t, approve, reject)
+	// a rejection's signature cannot be relayed as an approval
+	sig := priv.Sign(reject)
+	assert.True(t, priv.PubKey().VerifyBytes(reject, sig))
+	assert.False(t, priv.PubKey().VerifyBytes(approve, sig))
+}
+
+func TestBaseCheckTransferMsg_ValidateBasic(t *testing.T) {
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name string
+		msg  BaseCheckTransferMsg
+		want sdk.CodeType
+	}{
+		{"invalid id", NewApproveTransferMsg(addr, 0).BaseCheckTransferMsg, CodeInvalidTransfer},
+		{"missing checker", NewRejectTransferMsg(nil, 1).BaseCheckTransferMsg, CodeInvalidAddress},
+		{"ok", NewApproveTransferMsg(addr, 1).BaseCheckTransferMsg, sdk.CodeOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.msg.ValidateBasic()
+			if got == nil {
+				assert.True(t, tt.want.IsOK())
+			} else {
+				assert.Equal(t, tt.want, got.ABCICode(), got.ABCILog())
+			}
+		})
+	}
 }
 
 func Test_NewCreateAdminMsg(t *testing.T) {
 	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
 	pub := crypto.GenPrivKeyEd25519().PubKey()
 	type args struct {
-		creator    sdk.Address
-		pubkey     crypto.PubKey
-		entityName string
-		entityType string
+		creator  sdk.Address
+		pubkey   crypto.PubKey
+		entityID string
 	}
 	tests := []struct {
 		name    string
 		args    args
 		wantMsg CreateAdminMsg
 	}{
-		{"nil", args{nil, crypto.PubKey{}, "", ""}, CreateAdminMsg{}},
-		{"CreateAdminMsg", args{addr, pub, "entityName", "typ"}, CreateAdminMsg{
-			BaseCreateUserMsg{PubKey: pub, Creator: addr},
-			BaseLegalEntity{EntityName: "entityName", EntityType: "typ"}}},
+		{"nil", args{nil, crypto.PubKey{}, ""}, CreateAdminMsg{}},
+		{"CreateAdminMsg", args{addr, pub, "entityID"}, CreateAdminMsg{
+			BaseCreateUserMsg{PubKey: pub, Creator: addr}, "entityID"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := NewCreateAdminMsg(tt.args.creator, tt.args.pubkey, tt.args.entityName, tt.args.entityType)
+			got := NewCreateAdminMsg(tt.args.creator, tt.args.pubkey, tt.args.entityID)
 			assert.Equal(t, got, tt.wantMsg)
 		})
 	}
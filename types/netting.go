@@ -0,0 +1,175 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// Obligation defines an amount that a member owes
+// to another member within a netting cycle.
+type Obligation struct {
+	Debtor   sdk.Address
+	Creditor sdk.Address
+	Amount   sdk.Coin
+}
+
+// NetPosition defines a member's net position at the end of
+// a netting cycle. Positive amounts are owed to the member,
+// negative amounts are owed by the member.
+type NetPosition struct {
+	Member sdk.Address
+	Amount sdk.Coins
+}
+
+// NettingResult defines the outcome of a netting cycle.
+type NettingResult struct {
+	Cycle     int64
+	Height    int64
+	Sender    sdk.Address
+	Positions []NetPosition
+}
+
+// NettingMapper stores obligations and netting results.
+type NettingMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+// NewNettingMapper creates a netting mapper given a storekey.
+func NewNettingMapper(key sdk.StoreKey) NettingMapper {
+	return NettingMapper{
+		key: key,
+		cdc: wire.NewCodec(),
+	}
+}
+
+// GetCurrentCycle returns the cycle obligations are currently added to.
+func (nm NettingMapper) GetCurrentCycle(ctx sdk.Context) int64 {
+	bz := ctx.KVStore(nm.key).Get(NettingCycleKey())
+	if bz == nil {
+		return 1
+	}
+	var cycle int64
+	nm.mustUnmarshal(bz, &cycle)
+	return cycle
+}
+
+// AddObligation appends an obligation to the current cycle.
+func (nm NettingMapper) AddObligation(ctx sdk.Context, ob Obligation) {
+	store := ctx.KVStore(nm.key)
+	cycle := nm.GetCurrentCycle(ctx)
+	index := nm.getObligationCount(ctx, cycle)
+	store.Set(ObligationKey(cycle, index), nm.mustMarshal(ob))
+	store.Set(ObligationCountKey(cycle), nm.mustMarshal(index+1))
+}
+
+// GetObligations returns the obligations submitted in a cycle.
+func (nm NettingMapper) GetObligations(ctx sdk.Context, cycle int64) []Obligation {
+	store := ctx.KVStore(nm.key)
+	count := nm.getObligationCount(ctx, cycle)
+	obligations := make([]Obligation, count)
+	for i := int64(0); i < count; i++ {
+		nm.mustUnmarshal(store.Get(ObligationKey(cycle, i)), &obligations[i])
+	}
+	return obligations
+}
+
+// CloseCycle stores the cycle's result and opens the next cycle.
+func (nm NettingMapper) CloseCycle(ctx sdk.Context, res NettingResult) {
+	store := ctx.KVStore(nm.key)
+	store.Set(NettingResultKey(res.Cycle), nm.mustMarshal(res))
+	store.Set(NettingCycleKey(), nm.mustMarshal(res.Cycle+1))
+}
+
+// CancelCycle discards the cycle's obligations and opens the next cycle.
+// Cancelled cycles have no result.
+func (nm NettingMapper) CancelCycle(ctx sdk.Context, cycle int64) {
+	store := ctx.KVStore(nm.key)
+	count := nm.getObligationCount(ctx, cycle)
+	for i := int64(0); i < count; i++ {
+		store.Delete(ObligationKey(cycle, i))
+	}
+	store.Delete(ObligationCountKey(cycle))
+	store.Set(NettingCycleKey(), nm.mustMarshal(cycle+1))
+}
+
+// GetNettingResult returns the result of a closed cycle.
+func (nm NettingMapper) GetNettingResult(ctx sdk.Context, cycle int64) (res NettingResult, found bool) {
+	bz := ctx.KVStore(nm.key).Get(NettingResultKey(cycle))
+	if bz == nil {
+		return res, false
+	}
+	nm.mustUnmarshal(bz, &res)
+	return res, true
+}
+
+func (nm NettingMapper) getObligationCount(ctx sdk.Context, cycle int64) int64 {
+	bz := ctx.KVStore(nm.key).Get(ObligationCountKey(cycle))
+	if bz == nil {
+		return 0
+	}
+	var count int64
+	nm.mustUnmarshal(bz, &count)
+	return count
+}
+
+func (nm NettingMapper) mustMarshal(v interface{}) []byte {
+	bz, err := nm.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (nm NettingMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := nm.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+// NettingCycleKey stores the current netting cycle under "netting/cycle".
+func NettingCycleKey() []byte {
+	return []byte("netting/cycle")
+}
+
+// ObligationKey stores an obligation under "netting/obligations/cycle/index".
+func ObligationKey(cycle, index int64) []byte {
+	return []byte(fmt.Sprintf("netting/obligations/%d/%d", cycle, index))
+}
+
+// ObligationCountKey stores the number of obligations of a cycle
+// under "netting/obligations/cycle".
+func ObligationCountKey(cycle int64) []byte {
+	return []byte(fmt.Sprintf("netting/obligations/%d", cycle))
+}
+
+// NettingResultKey stores a cycle's result under "netting/results/cycle".
+func NettingResultKey(cycle int64) []byte {
+	return []byte(fmt.Sprintf("netting/results/%d", cycle))
+}
+
+// ComputeNetPositions nets obligations into per-member positions.
+// Positions are returned in order of first appearance and,
+// per currency, they sum up to zero.
+func ComputeNetPositions(obligations []Obligation) []NetPosition {
+	var positions []NetPosition
+	index := make(map[string]int)
+	position := func(addr sdk.Address) *NetPosition {
+		i, ok := index[string(addr)]
+		if !ok {
+			i = len(positions)
+			index[string(addr)] = i
+			positions = append(positions, NetPosition{Member: addr, Amount: sdk.Coins{}})
+		}
+		return &positions[i]
+	}
+	for _, ob := range obligations {
+		debtor := position(ob.Debtor)
+		debtor.Amount = debtor.Amount.Minus(sdk.Coins{ob.Amount})
+		creditor := position(ob.Creditor)
+		creditor.Amount = creditor.Amount.Plus(sdk.Coins{ob.Amount})
+	}
+	return positions
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestComputeNetPositions(t *testing.T) {
+	m1 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	m2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	m3 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tests := []struct {
+		name        string
+		obligations []Obligation
+		want        []NetPosition
+	}{
+		{"nil", nil, nil},
+		{"single", []Obligation{{m1, m2, sdk.Coin{"USD", 100}}}, []NetPosition{
+			{m1, sdk.Coins{{"USD", -100}}},
+			{m2, sdk.Coins{{"USD", 100}}},
+		}},
+		{"offsetting", []Obligation{{m1, m2, sdk.Coin{"USD", 100}}, {m2, m1, sdk.Coin{"USD", 100}}}, []NetPosition{
+			{m1, sdk.Coins{}},
+			{m2, sdk.Coins{}},
+		}},
+		{"multilateral", []Obligation{
+			{m1, m2, sdk.Coin{"USD", 100}},
+			{m2, m3, sdk.Coin{"USD", 70}},
+			{m3, m1, sdk.Coin{"EUR", 30}},
+		}, []NetPosition{
+			{m1, sdk.Coins{{"EUR", 30}, {"USD", -100}}},
+			{m2, sdk.Coins{{"USD", 30}}},
+			{m3, sdk.Coins{{"EUR", -30}, {"USD", 70}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeNetPositions(tt.obligations)
+			assert.Equal(t, tt.want, got)
+			total := sdk.Coins{}
+			for _, pos := range got {
+				total = total.Plus(pos.Amount)
+			}
+			assert.True(t, total.IsZero())
+		})
+	}
+}
+
+func TestNettingMapper(t *testing.T) {
+	key, ctx := fakeStore()
+	netting := NewNettingMapper(key)
+	m1 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	m2 := crypto.GenPrivKeyEd25519().PubKey().Address()
+	ob := Obligation{m1, m2, sdk.Coin{"USD", 100}}
+
+	assert.Equal(t, int64(1), netting.GetCurrentCycle(ctx))
+	netting.AddObligation(ctx, ob)
+	netting.AddObligation(ctx, ob)
+	assert.Equal(t, []Obligation{ob, ob}, netting.GetObligations(ctx, 1))
+	_, found := netting.GetNettingResult(ctx, 1)
+	assert.False(t, found)
+
+	netting.CloseCycle(ctx, NettingResult{Cycle: 1, Height: 100})
+	res, found := netting.GetNettingResult(ctx, 1)
+	assert.True(t, found)
+	assert.Equal(t, int64(100), res.Height)
+	assert.Equal(t, int64(2), netting.GetCurrentCycle(ctx))
+	assert.Equal(t, 0, len(netting.GetObligations(ctx, 2)))
+
+	// cancelled cycles lose their obligations and have no result
+	netting.AddObligation(ctx, ob)
+	netting.CancelCycle(ctx, 2)
+	assert.Equal(t, 0, len(netting.GetObligations(ctx, 2)))
+	_, found = netting.GetNettingResult(ctx, 2)
+	assert.False(t, found)
+	assert.Equal(t, int64(3), netting.GetCurrentCycle(ctx))
+	assert.Equal(t, []Obligation{ob, ob}, netting.GetObligations(ctx, 1))
+}
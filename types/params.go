@@ -0,0 +1,79 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// ParamsMapper stores the parameters that
+// configure the chain, set at genesis.
+type ParamsMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+// NewParamsMapper creates a params mapper given a storekey.
+func NewParamsMapper(key sdk.StoreKey) ParamsMapper {
+	return ParamsMapper{
+		key: key,
+		cdc: wire.NewCodec(),
+	}
+}
+
+// GetDualControlParams returns the dual control parameters,
+// or the defaults if they were never set.
+func (pm ParamsMapper) GetDualControlParams(ctx sdk.Context) DualControlParams {
+	bz := ctx.KVStore(pm.key).Get(DualControlParamsKey())
+	if bz == nil {
+		return DefaultDualControlParams()
+	}
+	var params DualControlParams
+	pm.mustUnmarshal(bz, &params)
+	return params
+}
+
+// SetDualControlParams stores the dual control parameters.
+func (pm ParamsMapper) SetDualControlParams(ctx sdk.Context, params DualControlParams) {
+	ctx.KVStore(pm.key).Set(DualControlParamsKey(), pm.mustMarshal(params))
+}
+
+// GetInvariantParams returns the invariants' parameters,
+// or the defaults if they were never set.
+func (pm ParamsMapper) GetInvariantParams(ctx sdk.Context) InvariantParams {
+	bz := ctx.KVStore(pm.key).Get(InvariantParamsKey())
+	if bz == nil {
+		return DefaultInvariantParams()
+	}
+	var params InvariantParams
+	pm.mustUnmarshal(bz, &params)
+	return params
+}
+
+// SetInvariantParams stores the invariants' parameters.
+func (pm ParamsMapper) SetInvariantParams(ctx sdk.Context, params InvariantParams) {
+	ctx.KVStore(pm.key).Set(InvariantParamsKey(), pm.mustMarshal(params))
+}
+
+func (pm ParamsMapper) mustMarshal(v interface{}) []byte {
+	bz, err := pm.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (pm ParamsMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := pm.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+// DualControlParamsKey stores the dual control parameters under "dualcontrol/params".
+func DualControlParamsKey() []byte {
+	return []byte("dualcontrol/params")
+}
+
+// InvariantParamsKey stores the invariants' parameters under "invariants/params".
+func InvariantParamsKey() []byte {
+	return []byte("invariants/params")
+}
@@ -0,0 +1,41 @@
+package types
+
+// ABCI query paths served by the application; they all return JSON.
+const (
+	// QueryPathPrefix prefixes all clearchain query paths.
+	QueryPathPrefix = "/clearchain/"
+	// AccountQueryPath returns an account, e.g. /clearchain/account/<addr>
+	AccountQueryPath = QueryPathPrefix + "account/"
+	// BalanceQueryPath returns an account's balance in a currency,
+	// e.g. /clearchain/balance/<addr>/<denom>
+	BalanceQueryPath = QueryPathPrefix + "balance/"
+	// EntityQueryPath lists a legal entity's accounts, e.g. /clearchain/entity/<name>
+	EntityQueryPath = QueryPathPrefix + "entity/"
+	// EntitiesQueryPath lists the registered legal entities.
+	EntitiesQueryPath = QueryPathPrefix + "entities"
+	// RegisteredEntityQueryPath returns a registered legal entity,
+	// e.g. /clearchain/entities/<id>
+	RegisteredEntityQueryPath = EntitiesQueryPath + "/"
+	// CurrenciesQueryPath lists the supported currencies.
+	CurrenciesQueryPath = QueryPathPrefix + "currencies"
+	// TransferQueryPath returns a proposed transfer, e.g. /clearchain/transfers/<id>
+	TransferQueryPath = QueryPathPrefix + "transfers/"
+	// PendingTransfersQueryPath lists the transfers waiting for approval.
+	PendingTransfersQueryPath = TransferQueryPath + "pending"
+	// ReferenceQueryPath returns the transfer a sender executed with a reference,
+	// e.g. /clearchain/references/<sender>/<reference>
+	ReferenceQueryPath = QueryPathPrefix + "references/"
+	// HistoryQueryPath returns a page of an asset account's postings, newest first,
+	// e.g. /clearchain/history/<addr> or /clearchain/history/<addr>/<page>/<limit>
+	HistoryQueryPath = QueryPathPrefix + "history/"
+	// JournalQueryPath returns a journal entry, e.g. /clearchain/journal/<id>
+	JournalQueryPath = QueryPathPrefix + "journal/"
+	// JournalBalanceQueryPath compares an account's coins with the sum of
+	// its journal lines, e.g. /clearchain/journal/balance/<addr>
+	JournalBalanceQueryPath = JournalQueryPath + "balance/"
+	// NettingQueryPath returns the result of a settled netting cycle,
+	// e.g. /clearchain/netting/<cycle>
+	NettingQueryPath = QueryPathPrefix + "netting/"
+	// InvariantsQueryPath checks the ledger invariants and lists their violations.
+	InvariantsQueryPath = QueryPathPrefix + "invariants"
+)
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/wire"
+)
+
+// TransferRecord indexes an executed deposit, settlement
+// or withdraw by its sender and external reference.
+type TransferRecord struct {
+	TransferType string
+	Operator     sdk.Address
+	Sender       sdk.Address
+	Recipient    sdk.Address
+	Amount       sdk.Coin
+	Reference    string
+	Memo         string
+	Height       int64
+}
+
+// NewTransferRecord builds the record of a deposit, settlement or withdraw,
+// it returns false for any other message.
+func NewTransferRecord(msg sdk.Msg) (TransferRecord, bool) {
+	switch m := msg.(type) {
+	case DepositMsg:
+		return TransferRecord{DepositType, m.Operator, m.Sender, m.Recipient, m.Amount, m.Reference, m.Memo, 0}, true
+	case SettleMsg:
+		return TransferRecord{SettlementType, m.Operator, m.Sender, m.Recipient, m.Amount, m.Reference, m.Memo, 0}, true
+	case WithdrawMsg:
+		return TransferRecord{WithdrawType, m.Operator, m.Sender, m.Recipient, m.Amount, m.Reference, m.Memo, 0}, true
+	}
+	return TransferRecord{}, false
+}
+
+// ReferenceMapper indexes the transfers that carry a reference.
+type ReferenceMapper struct {
+	key sdk.StoreKey
+	cdc *wire.Codec
+}
+
+// NewReferenceMapper creates a reference mapper given a storekey.
+func NewReferenceMapper(key sdk.StoreKey) ReferenceMapper {
+	return ReferenceMapper{
+		key: key,
+		cdc: wire.NewCodec(),
+	}
+}
+
+// HasReference tells whether the sender already used the reference.
+func (rm ReferenceMapper) HasReference(ctx sdk.Context, sender sdk.Address, reference string) bool {
+	return ctx.KVStore(rm.key).Has(ReferenceKey(sender, reference))
+}
+
+// GetTransfer returns the sender's transfer with the given reference.
+func (rm ReferenceMapper) GetTransfer(ctx sdk.Context, sender sdk.Address, reference string) (rec TransferRecord, found bool) {
+	bz := ctx.KVStore(rm.key).Get(ReferenceKey(sender, reference))
+	if bz == nil {
+		return rec, false
+	}
+	rm.mustUnmarshal(bz, &rec)
+	return rec, true
+}
+
+// SetTransfer indexes the transfer by its sender and reference.
+func (rm ReferenceMapper) SetTransfer(ctx sdk.Context, rec TransferRecord) {
+	ctx.KVStore(rm.key).Set(ReferenceKey(rec.Sender, rec.Reference), rm.mustMarshal(rec))
+}
+
+func (rm ReferenceMapper) mustMarshal(v interface{}) []byte {
+	bz, err := rm.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (rm ReferenceMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := rm.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+// ReferenceKey stores a transfer under "references/sender/reference",
+// the sender being hex encoded.
+func ReferenceKey(sender sdk.Address, reference string) []byte {
+	return []byte(fmt.Sprintf("references/%s/%s", hex.EncodeToString(sender), reference))
+}
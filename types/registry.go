@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// SetEntity registers the legal entity under its ID and name.
+// Entities are never renamed, accounts refer to them by name.
+func (em EntityMapper) SetEntity(ctx sdk.Context, e Entity) {
+	store := ctx.KVStore(em.key)
+	store.Set(RegisteredEntityKey(e.ID), em.mustMarshal(e))
+	store.Set(RegisteredEntityNameKey(e.Name), []byte(e.ID))
+}
+
+// GetEntity returns the legal entity registered under the ID.
+func (em EntityMapper) GetEntity(ctx sdk.Context, id string) (e Entity, found bool) {
+	bz := ctx.KVStore(em.key).Get(RegisteredEntityKey(id))
+	if bz == nil {
+		return e, false
+	}
+	em.mustUnmarshal(bz, &e)
+	return e, true
+}
+
+// GetEntityByName returns the legal entity registered with the name.
+func (em EntityMapper) GetEntityByName(ctx sdk.Context, name string) (e Entity, found bool) {
+	id := ctx.KVStore(em.key).Get(RegisteredEntityNameKey(name))
+	if id == nil {
+		return e, false
+	}
+	return em.GetEntity(ctx, string(id))
+}
+
+// IterateEntities calls process on the registered legal entities,
+// ordered by ID, until it returns true.
+func (em EntityMapper) IterateEntities(ctx sdk.Context, process func(Entity) (stop bool)) {
+	prefix := []byte("registry/ids/")
+	iter := ctx.KVStore(em.key).Iterator(prefix, prefixEndBytes(prefix))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var e Entity
+		em.mustUnmarshal(iter.Value(), &e)
+		if process(e) {
+			return
+		}
+	}
+}
+
+func (em EntityMapper) mustMarshal(v interface{}) []byte {
+	bz, err := em.cdc.MarshalBinary(v)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+func (em EntityMapper) mustUnmarshal(bz []byte, ptr interface{}) {
+	if err := em.cdc.UnmarshalBinary(bz, ptr); err != nil {
+		panic(err)
+	}
+}
+
+// RegisteredEntityKey stores a legal entity under "registry/ids/id".
+func RegisteredEntityKey(id string) []byte {
+	return []byte(fmt.Sprintf("registry/ids/%s", id))
+}
+
+// RegisteredEntityNameKey stores a legal entity's ID under "registry/names/name".
+func RegisteredEntityNameKey(name string) []byte {
+	return []byte(fmt.Sprintf("registry/names/%s", name))
+}
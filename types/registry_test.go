@@ -0,0 +1,56 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntityMapper_Registry(t *testing.T) {
+	key, ctx := fakeStore()
+	entities := NewEntityMapper(key)
+	accts := NewIndexedAccountMapper(key, entities)
+	fakeAdminWithEntityName(accts, ctx, "ICM", EntityIndividualClearingMember)
+	icm := NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "5493001KJTIIGC8Y1R12", "GB")
+	cust := NewEntity("cust-1", "CUST", EntityCustodian, "", "")
+	entities.SetEntity(ctx, icm)
+	entities.SetEntity(ctx, cust)
+
+	got, found := entities.GetEntity(ctx, "icm-1")
+	assert.True(t, found)
+	assert.Equal(t, icm, got)
+	got, found = entities.GetEntityByName(ctx, "CUST")
+	assert.True(t, found)
+	assert.Equal(t, cust, got)
+	_, found = entities.GetEntity(ctx, "ICM")
+	assert.False(t, found)
+	_, found = entities.GetEntityByName(ctx, "icm-1")
+	assert.False(t, found)
+
+	var all []Entity
+	entities.IterateEntities(ctx, func(e Entity) bool {
+		all = append(all, e)
+		return false
+	})
+	assert.Equal(t, []Entity{cust, icm}, all)
+	// registered entities and indexed accounts do not overlap
+	var accounts []*AppAccount
+	accts.IterateAccounts(ctx, func(acc *AppAccount) bool {
+		accounts = append(accounts, acc)
+		return false
+	})
+	assert.Equal(t, 1, len(accounts))
+}
+
+func TestEntity_Validate(t *testing.T) {
+	suspended := NewEntity("icm-1", "ICM", EntityIndividualClearingMember, "", "")
+	suspended.Status = EntityStatusSuspended
+	unknown := suspended
+	unknown.Status = ""
+	assert.Nil(t, suspended.Validate())
+	assert.False(t, suspended.IsActive())
+	assert.NotNil(t, unknown.Validate())
+	assert.True(t, validateLEI("5493001KJTIIGC8Y1R12"))
+	assert.False(t, validateLEI("5493001KJTIIGC8Y1RA2"))
+	assert.False(t, validateLEI("5493001KJTIIGC8Y1R21"))
+}
@@ -0,0 +1,116 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Roles that can be assigned to users.
+const (
+	RoleDepositOperator    = "deposit-operator"
+	RoleSettlementOperator = "settlement-operator"
+	RoleWithdrawalOperator = "withdrawal-operator"
+	RoleUserAdmin          = "user-admin"
+	RoleAuditor            = "auditor"
+)
+
+// Permissions the handlers check signers against.
+const (
+	// PermDeposit allows clearing house users to sign deposits.
+	PermDeposit = "deposit"
+	// PermSettle allows clearing house users to sign settlements,
+	// submit obligations, trigger net settlements and cancel netting cycles.
+	PermSettle = "settle"
+	// PermWithdraw allows clearing house users to sign withdrawals.
+	PermWithdraw = "withdraw"
+	// PermManageUsers allows users to create, freeze and configure
+	// their entity's users and asset accounts; clearing house users
+	// may also manage other entities' admins and credit limits.
+	PermManageUsers = "manage-users"
+)
+
+// rolePermissions is the permission table: it maps each role to the
+// permissions it grants. Auditors hold no permission, queries are public.
+var rolePermissions = map[string][]string{
+	RoleDepositOperator:    {PermDeposit},
+	RoleSettlementOperator: {PermSettle},
+	RoleWithdrawalOperator: {PermWithdraw},
+	RoleUserAdmin:          {PermManageUsers},
+	RoleAuditor:            {},
+}
+
+// DefaultOperatorRoles returns the roles operators are created with.
+func DefaultOperatorRoles() []string {
+	return []string{RoleDepositOperator, RoleSettlementOperator, RoleWithdrawalOperator}
+}
+
+// DefaultAdminRoles returns the roles admins are created with.
+func DefaultAdminRoles() []string {
+	return []string{RoleUserAdmin}
+}
+
+// ValidateRole ensures that the role is defined in the permission table.
+func ValidateRole(role string) error {
+	if _, ok := rolePermissions[role]; !ok {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	return nil
+}
+
+// HasRole returns true if the account was assigned the role; false otherwise.
+func (a AppAccount) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission returns true if any of the account's roles
+// grants the permission; false otherwise.
+func (a AppAccount) HasPermission(perm string) bool {
+	for _, role := range a.Roles {
+		for _, p := range rolePermissions[role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CanTransfer returns true if any of the account's roles grants
+// a transfer permission; false otherwise. Such users are subject
+// to operator limits.
+func (a AppAccount) CanTransfer() bool {
+	for _, perm := range transferPermissions {
+		if a.HasPermission(perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantRole assigns the role to the account, roles are kept sorted.
+// It returns false if the account already had the role.
+func (a *AppAccount) GrantRole(role string) bool {
+	if a.HasRole(role) {
+		return false
+	}
+	a.Roles = append(a.Roles, role)
+	sort.Strings(a.Roles)
+	return true
+}
+
+// RevokeRole removes the role from the account.
+// It returns false if the account did not have the role.
+func (a *AppAccount) RevokeRole(role string) bool {
+	for i, r := range a.Roles {
+		if r == role {
+			a.Roles = append(a.Roles[:i], a.Roles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppAccount_Roles(t *testing.T) {
+	operator, _ := makeUser("CH", EntityClearingHouse)
+	admin, _ := makeAdminUser("CH", EntityClearingHouse)
+	assert.True(t, operator.HasPermission(PermDeposit))
+	assert.True(t, operator.HasPermission(PermSettle))
+	assert.True(t, operator.HasPermission(PermWithdraw))
+	assert.False(t, operator.HasPermission(PermManageUsers))
+	assert.True(t, admin.HasPermission(PermManageUsers))
+	assert.False(t, admin.HasPermission(PermDeposit))
+	assert.True(t, operator.CanTransfer())
+	assert.False(t, admin.CanTransfer())
+
+	assert.True(t, operator.RevokeRole(RoleDepositOperator))
+	assert.False(t, operator.RevokeRole(RoleDepositOperator))
+	assert.False(t, operator.HasPermission(PermDeposit))
+	assert.True(t, operator.HasPermission(PermSettle))
+
+	assert.True(t, operator.GrantRole(RoleUserAdmin))
+	assert.False(t, operator.GrantRole(RoleUserAdmin))
+	assert.True(t, operator.HasPermission(PermManageUsers))
+	assert.Equal(t, []string{RoleSettlementOperator, RoleUserAdmin, RoleWithdrawalOperator}, operator.Roles)
+
+	// auditors hold no permission
+	auditor, _ := makeUser("CH", EntityClearingHouse)
+	auditor.Roles = []string{RoleAuditor}
+	for _, perm := range []string{PermDeposit, PermSettle, PermWithdraw, PermManageUsers} {
+		assert.False(t, auditor.HasPermission(perm), perm)
+	}
+}
+
+func TestValidateRole(t *testing.T) {
+	assert.Nil(t, ValidateRole(RoleAuditor))
+	assert.NotNil(t, ValidateRole("superuser"))
+	assert.NotNil(t, ValidateRole(""))
+}
@@ -0,0 +1,21 @@
+package types
+
+// Names of the stores the application mounts. Each one is persisted
+// in its own database: IAVL stores sharing a database overwrite each
+// other's trees. Chains started before the accounts, entities, journal
+// and params stores were split keep all their data in the main store.
+const (
+	// MainStoreName holds the netting cycles, proposed transfers and references.
+	MainStoreName = "main"
+	// AccountsStoreName holds the accounts.
+	AccountsStoreName = "accounts"
+	// EntitiesStoreName holds the index of accounts by legal entity.
+	EntitiesStoreName = "entities"
+	// JournalStoreName holds the journal and the asset accounts' history.
+	JournalStoreName = "journal"
+	// ParamsStoreName holds the chain's parameters.
+	ParamsStoreName = "params"
+)
+
+// StoreNames lists the stores the application mounts.
+var StoreNames = []string{MainStoreName, AccountsStoreName, EntitiesStoreName, JournalStoreName, ParamsStoreName}
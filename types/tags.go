@@ -0,0 +1,97 @@
+package types
+
+import (
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+// Keys of the tags that handlers set on successful results.
+// Tendermint indexes transactions by tag, e.g. sender='<ADDR>'
+// or recipient.entity='ICM1'. Addresses are upper-case hex.
+const (
+	// TagAction holds the message's type, approved transfers
+	// are tagged with both the approval's and the transfer's.
+	TagAction = "action"
+	// TagOperator holds the clearing house user that moved money.
+	TagOperator = "operator"
+	// TagAdmin holds the user that created or changed an account.
+	TagAdmin = "admin"
+	// TagChecker holds the user that approved or rejected a transfer.
+	TagChecker = "checker"
+	// TagSender and TagRecipient hold the asset accounts money moved between.
+	TagSender    = "sender"
+	TagRecipient = "recipient"
+	// TagTarget holds the account that was created or changed.
+	TagTarget = "target"
+	// TagDebtor and TagCreditor hold an obligation's members.
+	TagDebtor   = "debtor"
+	TagCreditor = "creditor"
+	// TagDenom and TagAmount hold the amount moved or configured.
+	TagDenom  = "denom"
+	TagAmount = "amount"
+	// TagRole holds the role granted or revoked.
+	TagRole = "role"
+	// TagReference holds the transfer's external reference.
+	TagReference = "reference"
+	// TagTransferID and TagTransferType hold a proposed transfer's ID and type.
+	TagTransferID   = "transfer.id"
+	TagTransferType = "transfer.type"
+	// TagEntityID, TagEntityType and TagEntityStatus hold
+	// a registered legal entity's ID, type and status.
+	TagEntityID     = "entity.id"
+	TagEntityType   = "entity.type"
+	TagEntityStatus = "entity.status"
+	// TagCycle holds the netting cycle an obligation
+	// was recorded in, or that was settled or cancelled.
+	TagCycle = "cycle"
+)
+
+// EntityTag returns the key of the tag holding the legal
+// entity name of the account tagged with key, e.g. sender.entity.
+func EntityTag(key string) string {
+	return key + ".entity"
+}
+
+// Tags are the tags of a handler's result.
+type Tags []cmn.KVPair
+
+// NewTags returns tags that start with the message's action.
+func NewTags(action string) Tags {
+	return Tags{}.Append(TagAction, action)
+}
+
+// Append returns the tags with a new tag.
+func (t Tags) Append(key, value string) Tags {
+	return append(t, cmn.KVPair{Key: []byte(key), Value: []byte(value)})
+}
+
+// AppendAddress returns the tags with a new address tag.
+func (t Tags) AppendAddress(key string, addr sdk.Address) Tags {
+	return t.Append(key, addr.String())
+}
+
+// AppendAccount returns the tags with the account's
+// address and its legal entity's name.
+func (t Tags) AppendAccount(key string, acc *AppAccount) Tags {
+	return t.AppendAddress(key, acc.Address).Append(EntityTag(key), acc.LegalEntityName())
+}
+
+// AppendCoin returns the tags with the coin's denom and amount.
+func (t Tags) AppendCoin(coin sdk.Coin) Tags {
+	return t.Append(TagDenom, coin.Denom).Append(TagAmount, strconv.FormatInt(coin.Amount, 10))
+}
+
+// AppendReference returns the tags with the reference, if any.
+func (t Tags) AppendReference(reference string) Tags {
+	if len(reference) == 0 {
+		return t
+	}
+	return t.Append(TagReference, reference)
+}
+
+// Result returns a successful result carrying the tags.
+func (t Tags) Result() sdk.Result {
+	return sdk.Result{Tags: t}
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	crypto "github.com/tendermint/go-crypto"
+	cmn "github.com/tendermint/tmlibs/common"
+)
+
+func TestTags(t *testing.T) {
+	acc, _ := makeAssetAccount(nil, "ICM1", EntityIndividualClearingMember)
+	addr := crypto.GenPrivKeyEd25519().PubKey().Address()
+	tags := NewTags(DepositType).
+		AppendAddress(TagOperator, addr).
+		AppendAccount(TagRecipient, acc).
+		AppendCoin(sdk.Coin{"USD", -700}).
+		AppendReference("").
+		AppendReference("ref-1")
+	assert.Equal(t, Tags{
+		{Key: []byte("action"), Value: []byte("deposit")},
+		{Key: []byte("operator"), Value: []byte(addr.String())},
+		{Key: []byte("recipient"), Value: []byte(acc.Address.String())},
+		{Key: []byte("recipient.entity"), Value: []byte("ICM1")},
+		{Key: []byte("denom"), Value: []byte("USD")},
+		{Key: []byte("amount"), Value: []byte("-700")},
+		{Key: []byte("reference"), Value: []byte("ref-1")},
+	}, tags)
+	assert.Equal(t, []cmn.KVPair(tags), tags.Result().Tags)
+	assert.True(t, tags.Result().IsOK())
+}
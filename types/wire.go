@@ -7,14 +7,33 @@ import (
 )
 
 const (
-	typeDepositMsg            = 0x1
-	typeSettleMsg             = 0x2
-	typeWithdrawMsg           = 0x3
-	typeCreateAdminMsg        = 0x4
-	typeCreateOperatorMsg     = 0x5
-	typeCreateAssetAccountMsg = 0x6
-	typeFreezeAdminMsg        = 0x7
-	typeFreezeOperatorMsg     = 0x8
+	typeDepositMsg              = 0x1
+	typeSettleMsg               = 0x2
+	typeWithdrawMsg             = 0x3
+	typeCreateAdminMsg          = 0x4
+	typeCreateOperatorMsg       = 0x5
+	typeCreateAssetAccountMsg   = 0x6
+	typeFreezeAdminMsg          = 0x7
+	typeFreezeOperatorMsg       = 0x8
+	typeUnfreezeAdminMsg        = 0x9
+	typeUnfreezeOperatorMsg     = 0xa
+	typeFreezeAssetAccountMsg   = 0xb
+	typeUnfreezeAssetAccountMsg = 0xc
+	typeBatchSettleMsg          = 0xd
+	typeSubmitObligationMsg     = 0xe
+	typeNetSettlementMsg        = 0xf
+	typeSetCreditLimitMsg       = 0x10
+	typeProposeTransferMsg      = 0x11
+	typeApproveTransferMsg      = 0x12
+	typeRejectTransferMsg       = 0x13
+	typeSetOperatorLimitsMsg    = 0x14
+	typeGrantRoleMsg            = 0x15
+	typeRevokeRoleMsg           = 0x16
+	typeRotateKeyMsg            = 0x17
+	typeCloseAssetAccountMsg    = 0x18
+	typeRegisterEntityMsg       = 0x19
+	typeCancelNettingCycleMsg   = 0x1a
+	typeUpdateEntityStatusMsg   = 0x1b
 
 	typeAppAccount = 0x1
 )
@@ -32,6 +51,25 @@ func MakeCodec() *wire.Codec {
 		oldwire.ConcreteType{CreateAssetAccountMsg{}, typeCreateAssetAccountMsg},
 		oldwire.ConcreteType{FreezeAdminMsg{}, typeFreezeAdminMsg},
 		oldwire.ConcreteType{FreezeOperatorMsg{}, typeFreezeOperatorMsg},
+		oldwire.ConcreteType{UnfreezeAdminMsg{}, typeUnfreezeAdminMsg},
+		oldwire.ConcreteType{UnfreezeOperatorMsg{}, typeUnfreezeOperatorMsg},
+		oldwire.ConcreteType{FreezeAssetAccountMsg{}, typeFreezeAssetAccountMsg},
+		oldwire.ConcreteType{UnfreezeAssetAccountMsg{}, typeUnfreezeAssetAccountMsg},
+		oldwire.ConcreteType{BatchSettleMsg{}, typeBatchSettleMsg},
+		oldwire.ConcreteType{SubmitObligationMsg{}, typeSubmitObligationMsg},
+		oldwire.ConcreteType{NetSettlementMsg{}, typeNetSettlementMsg},
+		oldwire.ConcreteType{SetCreditLimitMsg{}, typeSetCreditLimitMsg},
+		oldwire.ConcreteType{ProposeTransferMsg{}, typeProposeTransferMsg},
+		oldwire.ConcreteType{ApproveTransferMsg{}, typeApproveTransferMsg},
+		oldwire.ConcreteType{RejectTransferMsg{}, typeRejectTransferMsg},
+		oldwire.ConcreteType{SetOperatorLimitsMsg{}, typeSetOperatorLimitsMsg},
+		oldwire.ConcreteType{GrantRoleMsg{}, typeGrantRoleMsg},
+		oldwire.ConcreteType{RevokeRoleMsg{}, typeRevokeRoleMsg},
+		oldwire.ConcreteType{RotateKeyMsg{}, typeRotateKeyMsg},
+		oldwire.ConcreteType{CloseAssetAccountMsg{}, typeCloseAssetAccountMsg},
+		oldwire.ConcreteType{RegisterEntityMsg{}, typeRegisterEntityMsg},
+		oldwire.ConcreteType{CancelNettingCycleMsg{}, typeCancelNettingCycleMsg},
+		oldwire.ConcreteType{UpdateEntityStatusMsg{}, typeUpdateEntityStatusMsg},
 	)
 	var _ = oldwire.RegisterInterface(
 		struct{ sdk.Account }{},